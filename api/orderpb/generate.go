@@ -0,0 +1,5 @@
+// Package orderpb holds the messages and the services of the gRPC API, generated from
+// order_service.proto.
+package orderpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative order_service.proto
@@ -1,13 +1,27 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"simple-order-service/internal/config"
 	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/grpcservice"
 	"simple-order-service/internal/interfaces/repository"
+	"simple-order-service/internal/interfaces/webhook"
 	"simple-order-service/internal/interfaces/webservice"
 	"simple-order-service/internal/usecases"
 	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+	"simple-order-service/pkg/metrics"
+	"simple-order-service/pkg/token"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/urfave/cli"
 )
@@ -21,15 +35,72 @@ func main() {
 		{
 			Name:        "start:webserver",
 			Description: "Start Webserver",
+			Flags:       configFlags(),
 			Action: func(c *cli.Context) {
-				StartWebServer()
+				StartWebServer(loadConfig(c))
+			},
+		},
+		{
+			Name:        "migrate:db",
+			Description: "Apply pending storage migrations to DB",
+			Flags:       configFlags(),
+			Action: func(c *cli.Context) {
+				MigrateDB(loadConfig(c))
+			},
+		},
+		{
+			Name:        "rebuild:orders",
+			Description: "Rebuild the orders in DB by replaying their event log",
+			Flags:       configFlags(),
+			Action: func(c *cli.Context) {
+				RebuildOrders(loadConfig(c))
+			},
+		},
+		{
+			Name:        "auth:keys:create",
+			Description: "Create an API key, which is only shown once",
+			Flags: append(configFlags(),
+				cli.StringFlag{Name: "subject", Usage: "who the key is for; the id of the customer for customer keys"},
+				cli.StringFlag{Name: "role", Usage: "role of the key: customer, operator or admin"},
+			),
+			Action: func(c *cli.Context) {
+				CreateAPIKey(loadConfig(c), c.String("subject"), c.String("role"))
+			},
+		},
+		{
+			Name:        "auth:keys:list",
+			Description: "List the API keys",
+			Flags:       configFlags(),
+			Action: func(c *cli.Context) {
+				ListAPIKeys(loadConfig(c))
+			},
+		},
+		{
+			Name:        "auth:keys:revoke",
+			Description: "Revoke an API key",
+			Flags:       append(configFlags(), cli.StringFlag{Name: "id", Usage: "id of the key to revoke"}),
+			Action: func(c *cli.Context) {
+				RevokeAPIKey(loadConfig(c), c.String("id"))
+			},
+		},
+		{
+			Name:        "customers:create",
+			Description: "Create a customer, which API keys with the customer role can then be created for",
+			Flags: append(configFlags(),
+				cli.StringFlag{Name: "name", Usage: "name of the customer"},
+				cli.StringFlag{Name: "email", Usage: "email of the customer, which must not be used by another customer"},
+				cli.StringFlag{Name: "contact", Usage: "how else the customer can be reached"},
+			),
+			Action: func(c *cli.Context) {
+				CreateCustomer(loadConfig(c), c.String("name"), c.String("email"), c.String("contact"))
 			},
 		},
 		{
 			Name:        "seed:db:products",
 			Description: "Seed products to DB",
+			Flags:       configFlags(),
 			Action: func(c *cli.Context) {
-				SeedProductsInDB()
+				SeedProductsInDB(loadConfig(c))
 			},
 		},
 	}
@@ -39,32 +110,350 @@ func main() {
 	}
 }
 
-func StartWebServer() {
-	db, err := database.NewInstance("shop.db")
+// configFlags lets every setting of the configuration be overridden on the command line.
+func configFlags() []cli.Flag {
+	flags := []cli.Flag{
+		cli.StringFlag{
+			Name:   "config",
+			EnvVar: config.EnvPrefix + "CONFIG",
+			Usage:  "path to a JSON or YAML configuration file",
+		},
+	}
+	for _, setting := range config.Settings() {
+		flags = append(flags, cli.StringFlag{
+			Name:  setting.Flag,
+			Value: setting.Default(),
+			Usage: setting.Usage + " [$" + setting.Env() + "]",
+		})
+	}
+	return flags
+}
+
+func loadConfig(c *cli.Context) config.Config {
+	cfg, err := config.Load(c.String("config"), os.LookupEnv, func(name string) (string, bool) {
+		return c.String(name), c.IsSet(name)
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
+	setupLogging(cfg)
+	return cfg
+}
+
+// setupLogging writes JSON lines to stderr at the configured level. The lines of the standard log
+// package, which are only written by fatal errors and by the HTTP server, are logged as errors.
+func setupLogging(cfg config.Config) {
+	level, err := logging.ParseLevel(cfg.Server.LogLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logging.SetDefault(logging.New(os.Stderr, level))
+	log.SetFlags(0)
+	log.SetOutput(logging.Default().Writer(logging.LevelError))
+}
+
+func openDB(cfg config.Config) *database.DB {
+	db, err := database.Open(cfg.Storage.Path, time.Duration(cfg.Storage.Timeout))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return db
+}
+
+func StartWebServer(cfg config.Config) {
+	pricingEngine, err := loadPricingEngine(cfg.Orders.PricingRules, cfg.Limits.Domain())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := openDB(cfg)
+	txDuration := metrics.Default.NewHistogramVec("bbolt_transaction_duration_seconds",
+		"Duration of the bbolt transactions, by mode (read or write).", metrics.DefaultBuckets, "mode")
+	db.ObserveTransactions(func(mode string, duration time.Duration) {
+		txDuration.Observe(duration.Seconds(), mode)
+	})
+	health := webservice.NewHealth()
+	health.AddCheck("database", func() error {
+		return repository.CheckStorage(db)
+	})
+
 	var ordersRepo domain.OrderRepository = repository.NewOrdersRepo(db)
 	var productsRepo domain.ProductRepository = repository.NewProductsRepo(db)
+	var couponsRepo domain.CouponRepository = repository.NewCouponsRepo(db)
+	var idempotencyKeysRepo domain.IdempotencyRepository = repository.NewIdempotencyKeysRepo(db)
+	var webhooksRepo domain.WebhookRepository = repository.NewWebhooksRepo(db)
+	var outboxRepo domain.OutboxRepository = repository.NewOutboxRepo(db)
+	var feedRepo domain.FeedRepository = repository.NewFeedRepo(db)
+	var apiKeysRepo domain.APIKeyRepository = repository.NewAPIKeysRepo(db)
+	var customersRepo domain.CustomerRepository = repository.NewCustomersRepo(db)
+	var unitOfWork domain.UnitOfWork = repository.NewUnitOfWork(db)
 
-	var orderInteractor webservice.OrderInteractor = usecases.NewOrderInteractor(ordersRepo, productsRepo)
-	var productInteractor webservice.ProductInteractor = usecases.NewProductInteractor(productsRepo)
+	var orderInteractor webservice.OrderInteractor = usecases.NewOrderInteractor(ordersRepo, productsRepo, unitOfWork, pricingEngine, time.Duration(cfg.Orders.ReservationTTL), cfg.Limits.Domain(), usecases.NewMetrics(metrics.Default))
+	var productInteractor webservice.ProductInteractor = usecases.NewProductInteractor(productsRepo, unitOfWork, cfg.Limits.Domain())
+	var couponInteractor webservice.CouponInteractor = usecases.NewCouponInteractor(couponsRepo, unitOfWork)
+	var customerInteractor webservice.CustomerInteractor = usecases.NewCustomerInteractor(customersRepo, unitOfWork)
+	var webhookInteractor webservice.WebhookInteractor = usecases.NewWebhookInteractor(webhooksRepo, outboxRepo, unitOfWork, cfg.Webhooks.AllowedHosts)
+	var authenticator webservice.Authenticator = usecases.NewAuthInteractor(apiKeysRepo, unitOfWork, tokenSigner(cfg), time.Duration(cfg.Auth.TokenTTL))
 
-	router := webservice.SetupRoutes(orderInteractor, productInteractor)
+	usecases.RegisterBusinessGauges(metrics.Default, ordersRepo, productsRepo, 30*time.Second)
 
-	if err = webservice.StartServer(router); err != nil {
+	reservationSweeper := usecases.NewReservationSweeper(unitOfWork, time.Duration(cfg.Orders.ReservationSweepInterval))
+	feedTrimmer := usecases.NewFeedTrimmer(feedRepo, time.Duration(cfg.Feed.Retention), time.Duration(cfg.Feed.TrimInterval))
+	idempotency := webservice.NewIdempotencyMiddleware(idempotencyKeysRepo, time.Duration(cfg.Server.IdempotencyKeyTTL), time.Hour)
+	webhookDispatcher := usecases.NewWebhookDispatcher(unitOfWork, webhook.NewHTTPSender(10*time.Second, cfg.Webhooks.AllowedHosts), time.Duration(cfg.Webhooks.DispatchInterval), cfg.Webhooks.MaxAttempts, time.Second, time.Duration(cfg.Webhooks.MaxBackoff), cfg.Webhooks.Concurrency)
+
+	rateLimits := webservice.RateLimits{
+		CatalogueReads: webservice.NewRateLimitMiddleware("catalogue_reads", cfg.RateLimits.CatalogueReads.RequestsPerMinute, cfg.RateLimits.CatalogueReads.Burst, cfg.RateLimits.MaxClients),
+		OrderWrites:    webservice.NewRateLimitMiddleware("order_writes", cfg.RateLimits.OrderWrites.RequestsPerMinute, cfg.RateLimits.OrderWrites.Burst, cfg.RateLimits.MaxClients),
+	}
+	// The server answers the health probes while the storage is migrated, and rejects the requests
+	// made to the API until the migration is done. The event streams end when the server is stopped.
+	serverCtx, stopServer := context.WithCancel(context.Background())
+	defer stopServer()
+	router := webservice.SetupRoutes(orderInteractor, productInteractor, couponInteractor, customerInteractor, webhookInteractor, feedRepo, authenticator, health, idempotency, rateLimits, cfg.Limits.Domain(), int64(cfg.Server.MaxBodyBytes), serverCtx.Done())
+
+	serverErrs := make(chan error, 1)
+	go func() {
+		serverErrs <- webservice.StartServer(serverCtx, router, cfg.Server.ListenAddr, time.Duration(cfg.Server.ReadTimeout), time.Duration(cfg.Server.WriteTimeout), time.Duration(cfg.Server.ShutdownTimeout))
+	}()
+
+	if err := repository.Migrate(db); err != nil {
+		stopServer()
+		<-serverErrs
+		db.Close()
 		log.Fatal(err)
 	}
+
+	workers := newWorkers()
+	workers.Start("reservation_sweeper", reservationSweeper.Run)
+	workers.Start("idempotency_purge", idempotency.Run)
+	workers.Start("feed_trimmer", feedTrimmer.Run)
+	workers.Start("webhook_dispatcher", webhookDispatcher.Run)
+	health.SetWorkers(workers)
+	health.SetPhase(webservice.PhaseReady)
+
+	// The gRPC server has no probes of its own, so it is only started once the storage is migrated.
+	// Without a gRPC server, grpcErrs stays nil and is never ready.
+	var grpcErrs chan error
+	if cfg.Server.GRPCListenAddr != "" {
+		grpcErrs = make(chan error, 1)
+		grpcServer := grpcservice.NewServer(orderInteractor, productInteractor, feedRepo, authenticator, grpcservice.NewIdempotencyInterceptor(idempotencyKeysRepo, time.Duration(cfg.Server.IdempotencyKeyTTL)), grpcservice.RateLimits{
+			CatalogueReads: rateLimits.CatalogueReads.Limiter(),
+			OrderWrites:    rateLimits.OrderWrites.Limiter(),
+		}, cfg.Limits.Domain())
+		go func() {
+			grpcErrs <- grpcServer.Start(serverCtx, cfg.Server.GRPCListenAddr, time.Duration(cfg.Server.ShutdownTimeout))
+		}()
+	}
+
+	// On SIGINT or SIGTERM the server reports that it is draining for the drain delay, so that load
+	// balancers stop sending it traffic, then stops accepting connections and drains the requests in
+	// flight. When either server fails, the other one is stopped too. The workers are stopped once no
+	// request can write anymore, and the database is closed last.
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err = <-serverErrs:
+		serverErrs = nil
+	case err = <-grpcErrs:
+		grpcErrs = nil
+	case <-signals.Done():
+		health.SetPhase(webservice.PhaseDraining)
+		time.Sleep(time.Duration(cfg.Server.DrainDelay))
+	}
+	stopServer()
+	for _, errs := range []chan error{serverErrs, grpcErrs} {
+		if errs == nil {
+			continue
+		}
+		if stopErr := <-errs; err == nil {
+			err = stopErr
+		}
+	}
+	workers.Stop()
+	if closeErr := db.Close(); closeErr != nil {
+		logging.Default().Error("could not close the database", "error", closeErr)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	logging.Default().Info("web server stopped")
+}
+
+// workers runs the background jobs of the web server until they are stopped, and reports which of
+// them are running.
+type workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]bool
 }
 
-func SeedProductsInDB() {
-	db, err := database.NewInstance("shop.db")
+func newWorkers() *workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workers{ctx: ctx, cancel: cancel, running: make(map[string]bool)}
+}
+
+func (w *workers) Start(name string, run func(ctx context.Context)) {
+	w.setRunning(name, true)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer w.setRunning(name, false)
+		run(w.ctx)
+	}()
+}
+
+func (w *workers) setRunning(name string, running bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.running[name] = running
+}
+
+func (w *workers) Status() map[string]bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := make(map[string]bool, len(w.running))
+	for name, running := range w.running {
+		status[name] = running
+	}
+	return status
+}
+
+// Stop cancels the workers and waits for the jobs they are running to finish.
+func (w *workers) Stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// tokenSigner signs bearer tokens with the configured secret, or with a random one when none is
+// configured.
+func tokenSigner(cfg config.Config) token.Signer {
+	if cfg.Auth.TokenSecret != "" {
+		return token.NewSigner([]byte(cfg.Auth.TokenSecret))
+	}
+	logging.Default().Warn("no token secret is configured; bearer tokens will only be valid until the server restarts")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatal(err)
+	}
+	return token.NewSigner(secret)
+}
+
+// loadPricingEngine builds the pricing engine from the rules in the given file, falling back to the
+// premium bundle discount of the limits when no file is given.
+func loadPricingEngine(path string, limits domain.Limits) (domain.PricingEngine, error) {
+	if path == "" {
+		return domain.DefaultPricingEngine(limits), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.PricingEngine{}, err
+	}
+	rules, err := domain.ParsePricingRules(data)
+	if err != nil {
+		return domain.PricingEngine{}, err
+	}
+	for _, rule := range rules {
+		logging.Default().Info("applying pricing rule", "rule", rule.Name())
+	}
+	return domain.NewPricingEngine(rules...), nil
+}
+
+func MigrateDB(cfg config.Config) {
+	db := openDB(cfg)
+	defer db.Close()
+
+	if err := repository.Migrate(db); err != nil {
+		log.Fatal(err)
+	}
+	logging.Default().Info("migrations applied")
+}
+
+func RebuildOrders(cfg config.Config) {
+	db := openDB(cfg)
+	defer db.Close()
+	if err := repository.Migrate(db); err != nil {
+		log.Fatal(err)
+	}
+
+	rebuilt, err := repository.RebuildOrders(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logging.Default().Info("rebuilt orders from the event log", "orders", rebuilt)
+}
+
+func CreateCustomer(cfg config.Config, name, email, contact string) {
+	db := openDB(cfg)
+	defer db.Close()
+
+	var customersRepo domain.CustomerRepository = repository.NewCustomersRepo(db)
+	var unitOfWork domain.UnitOfWork = repository.NewUnitOfWork(db)
+	customer, err := usecases.NewCustomerInteractor(customersRepo, unitOfWork).Create(context.Background(), usecases.Customer{Name: name, Email: email, Contact: contact})
+	if err != nil {
+		log.Fatal(err)
+	}
+	printJSON(customer)
+}
+
+// newAuthInteractor builds the interactor of the key commands, which sign with the configured secret
+// only: a random one would not match the secret of the server.
+func newAuthInteractor(cfg config.Config, db *database.DB) *usecases.AuthInteractor {
+	if cfg.Auth.TokenSecret == "" {
+		log.Fatal("auth.token_secret: must be set")
+	}
+	var apiKeysRepo domain.APIKeyRepository = repository.NewAPIKeysRepo(db)
+	var unitOfWork domain.UnitOfWork = repository.NewUnitOfWork(db)
+	return usecases.NewAuthInteractor(apiKeysRepo, unitOfWork, token.NewSigner([]byte(cfg.Auth.TokenSecret)), time.Duration(cfg.Auth.TokenTTL))
+}
+
+func CreateAPIKey(cfg config.Config, subject, role string) {
+	db := openDB(cfg)
+	defer db.Close()
+
+	key, err := newAuthInteractor(cfg, db).CreateAPIKey(context.Background(), subject, role)
 	if err != nil {
 		log.Fatal(err)
 	}
+	printJSON(key)
+}
+
+func ListAPIKeys(cfg config.Config) {
+	db := openDB(cfg)
+	defer db.Close()
+
+	printJSON(newAuthInteractor(cfg, db).APIKeys(context.Background()))
+}
+
+func RevokeAPIKey(cfg config.Config, id string) {
+	db := openDB(cfg)
+	defer db.Close()
+
+	if err := newAuthInteractor(cfg, db).RevokeAPIKey(context.Background(), id); err != nil {
+		log.Fatal(err)
+	}
+	logging.Default().Info("api key revoked", "id", id)
+}
+
+// printJSON writes the result of a command to stdout, apart from the logs.
+func printJSON(value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+}
+
+func SeedProductsInDB(cfg config.Config) {
+	db := openDB(cfg)
+	defer db.Close()
 
 	var productsRepo domain.ProductRepository = repository.NewProductsRepo(db)
-	var productInteractor webservice.ProductInteractor = usecases.NewProductInteractor(productsRepo)
+	var unitOfWork domain.UnitOfWork = repository.NewUnitOfWork(db)
+	var productInteractor webservice.ProductInteractor = usecases.NewProductInteractor(productsRepo, unitOfWork, cfg.Limits.Domain())
 
 	product1 := domain.NewProduct("1", "sneakers", 12.0, 11, domain.Premium)
 	product2 := domain.NewProduct("2", "shirt", 10.0, 3, domain.Premium)
@@ -81,5 +470,5 @@ func SeedProductsInDB() {
 	db.Put([]byte("products"), []byte(product3.ID()), data3)
 	db.Put([]byte("products"), []byte(product4.ID()), data4)
 
-	log.Println(productInteractor.GetAll())
+	logging.Default().Info("seeded products", "products", productInteractor.GetAll(context.Background()))
 }
@@ -2,11 +2,21 @@ module simple-order-service
 
 go 1.20
 
+require (
+	github.com/gorilla/mux v1.8.0
+	github.com/urfave/cli v1.22.12
+	go.etcd.io/bbolt v1.3.7
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230525234030-28d5490b6b19
+	google.golang.org/grpc v1.57.1
+	google.golang.org/protobuf v1.31.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
 require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.2 // indirect
-	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
-	github.com/urfave/cli v1.22.12 // indirect
-	go.etcd.io/bbolt v1.3.7 // indirect
-	golang.org/x/sys v0.4.0 // indirect
+	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
 )
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/logging"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedConfigFile = func(path string) error {
+		return fmt.Errorf("config file %s must be a .json, .yaml or .yml file", path)
+	}
+	ErrInvalidSetting = func(name, reason string) error {
+		return fmt.Errorf("%s: %s", name, reason)
+	}
+)
+
+// Config holds the settings of the service. Settings are read from a JSON or YAML file, then
+// overridden by environment variables, then by command line flags.
+type Config struct {
+	Server     ServerConfig     `json:"server" yaml:"server"`
+	Storage    StorageConfig    `json:"storage" yaml:"storage"`
+	Orders     OrdersConfig     `json:"orders" yaml:"orders"`
+	Webhooks   WebhooksConfig   `json:"webhooks" yaml:"webhooks"`
+	Feed       FeedConfig       `json:"feed" yaml:"feed"`
+	Auth       AuthConfig       `json:"auth" yaml:"auth"`
+	Limits     LimitsConfig     `json:"limits" yaml:"limits"`
+	RateLimits RateLimitsConfig `json:"rate_limits" yaml:"rate_limits"`
+}
+
+type ServerConfig struct {
+	ListenAddr        string   `json:"listen_addr" yaml:"listen_addr"`
+	GRPCListenAddr    string   `json:"grpc_listen_addr" yaml:"grpc_listen_addr"`
+	ReadTimeout       Duration `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout      Duration `json:"write_timeout" yaml:"write_timeout"`
+	DrainDelay        Duration `json:"drain_delay" yaml:"drain_delay"`
+	ShutdownTimeout   Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	IdempotencyKeyTTL Duration `json:"idempotency_key_ttl" yaml:"idempotency_key_ttl"`
+	MaxBodyBytes      int      `json:"max_body_bytes" yaml:"max_body_bytes"`
+	LogLevel          string   `json:"log_level" yaml:"log_level"`
+}
+
+type StorageConfig struct {
+	Path    string   `json:"path" yaml:"path"`
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+}
+
+type OrdersConfig struct {
+	PricingRules             string   `json:"pricing_rules" yaml:"pricing_rules"`
+	ReservationTTL           Duration `json:"reservation_ttl" yaml:"reservation_ttl"`
+	ReservationSweepInterval Duration `json:"reservation_sweep_interval" yaml:"reservation_sweep_interval"`
+}
+
+// WebhooksConfig holds the settings of the delivery of events to webhooks. Webhooks may not target
+// loopback, link-local or private addresses, so that they cannot be used to reach the network of the
+// service, except for the hosts in AllowedHosts.
+type WebhooksConfig struct {
+	DispatchInterval Duration `json:"dispatch_interval" yaml:"dispatch_interval"`
+	MaxAttempts      int      `json:"max_attempts" yaml:"max_attempts"`
+	MaxBackoff       Duration `json:"max_backoff" yaml:"max_backoff"`
+	Concurrency      int      `json:"concurrency" yaml:"concurrency"`
+	AllowedHosts     []string `json:"allowed_hosts" yaml:"allowed_hosts"`
+}
+
+// FeedConfig holds the settings of the feed of changes streamed to clients. Events older than
+// Retention are trimmed every TrimInterval, and cannot be resumed from afterwards.
+type FeedConfig struct {
+	Retention    Duration `json:"retention" yaml:"retention"`
+	TrimInterval Duration `json:"trim_interval" yaml:"trim_interval"`
+}
+
+// AuthConfig holds the settings of the bearer tokens. Tokens are signed with TokenSecret, which has
+// to be shared by every instance of the service; a random secret is used when none is given, so that
+// tokens are only valid until the service restarts.
+type AuthConfig struct {
+	TokenSecret string   `json:"token_secret" yaml:"token_secret"`
+	TokenTTL    Duration `json:"token_ttl" yaml:"token_ttl"`
+}
+
+// LimitsConfig holds the business limits enforced by the domain.
+type LimitsConfig struct {
+	MaxQuantityPerProduct    int     `json:"max_quantity_per_product" yaml:"max_quantity_per_product"`
+	PremiumBundleMinProducts int     `json:"premium_bundle_min_products" yaml:"premium_bundle_min_products"`
+	PremiumBundleDiscount    float64 `json:"premium_bundle_discount" yaml:"premium_bundle_discount"`
+	DefaultPageLimit         int     `json:"default_page_limit" yaml:"default_page_limit"`
+	MaxPageLimit             int     `json:"max_page_limit" yaml:"max_page_limit"`
+}
+
+// RateLimitsConfig holds the rate limits of the groups of routes of the API, which every client is held
+// to separately. The buckets of at most MaxClients clients are kept in memory.
+type RateLimitsConfig struct {
+	MaxClients     int             `json:"max_clients" yaml:"max_clients"`
+	CatalogueReads RateLimitConfig `json:"catalogue_reads" yaml:"catalogue_reads"`
+	OrderWrites    RateLimitConfig `json:"order_writes" yaml:"order_writes"`
+}
+
+// RateLimitConfig lets a client make RequestsPerMinute requests a minute on average, and up to Burst
+// requests at once. Routes whose RequestsPerMinute is 0 are not limited.
+type RateLimitConfig struct {
+	RequestsPerMinute int `json:"requests_per_minute" yaml:"requests_per_minute"`
+	Burst             int `json:"burst" yaml:"burst"`
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			ListenAddr:        ":8080",
+			GRPCListenAddr:    ":9090",
+			ReadTimeout:       Duration(10 * time.Second),
+			WriteTimeout:      Duration(30 * time.Second),
+			DrainDelay:        Duration(5 * time.Second),
+			ShutdownTimeout:   Duration(15 * time.Second),
+			IdempotencyKeyTTL: Duration(24 * time.Hour),
+			MaxBodyBytes:      1 << 20,
+			LogLevel:          "info",
+		},
+		Storage: StorageConfig{
+			Path:    "shop.db",
+			Timeout: Duration(time.Second),
+		},
+		Orders: OrdersConfig{
+			ReservationTTL:           Duration(15 * time.Minute),
+			ReservationSweepInterval: Duration(time.Minute),
+		},
+		Webhooks: WebhooksConfig{
+			DispatchInterval: Duration(time.Second),
+			MaxAttempts:      10,
+			MaxBackoff:       Duration(time.Hour),
+			Concurrency:      8,
+		},
+		Feed: FeedConfig{
+			Retention:    Duration(7 * 24 * time.Hour),
+			TrimInterval: Duration(time.Hour),
+		},
+		Auth: AuthConfig{
+			TokenTTL: Duration(time.Hour),
+		},
+		Limits: LimitsConfig{
+			MaxQuantityPerProduct:    domain.DefaultMaxQuantityPerProduct,
+			PremiumBundleMinProducts: domain.DefaultPremiumBundleMinProducts,
+			PremiumBundleDiscount:    domain.DefaultPremiumBundleDiscount,
+			DefaultPageLimit:         domain.DefaultPageLimit,
+			MaxPageLimit:             domain.DefaultMaxPageLimit,
+		},
+		RateLimits: RateLimitsConfig{
+			MaxClients:     10000,
+			CatalogueReads: RateLimitConfig{RequestsPerMinute: 600, Burst: 60},
+			OrderWrites:    RateLimitConfig{RequestsPerMinute: 60, Burst: 10},
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the file at path if one is given, and the values
+// returned by env and flags, in increasing order of precedence. env and flags return false for the
+// settings which are not set.
+func Load(path string, env, flags func(name string) (string, bool)) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := cfg.override(env, Setting.Env); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.override(flags, func(setting Setting) string { return setting.Flag }); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) override(lookup func(name string) (string, bool), name func(setting Setting) string) error {
+	if lookup == nil {
+		return nil
+	}
+	for _, setting := range Settings() {
+		value, ok := lookup(name(setting))
+		if !ok {
+			continue
+		}
+		if err := setting.set(cfg, value); err != nil {
+			return ErrInvalidSetting(name(setting), err.Error())
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(cfg)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(cfg)
+	default:
+		return ErrUnsupportedConfigFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate returns every invalid setting of the configuration.
+func (cfg Config) Validate() error {
+	errs := make([]error, 0)
+	check := func(ok bool, name, reason string) {
+		if !ok {
+			errs = append(errs, ErrInvalidSetting(name, reason))
+		}
+	}
+	check(cfg.Server.ListenAddr != "", "server.listen_addr", "must not be empty")
+	check(cfg.Server.GRPCListenAddr != cfg.Server.ListenAddr, "server.grpc_listen_addr", "must not be the address of the web server")
+	check(cfg.Server.ReadTimeout >= 0, "server.read_timeout", "must not be negative")
+	check(cfg.Server.WriteTimeout >= 0, "server.write_timeout", "must not be negative")
+	check(cfg.Server.DrainDelay >= 0, "server.drain_delay", "must not be negative")
+	check(cfg.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be greater than zero")
+	check(cfg.Server.IdempotencyKeyTTL > 0, "server.idempotency_key_ttl", "must be greater than zero")
+	check(cfg.Server.MaxBodyBytes > 0, "server.max_body_bytes", "must be at least 1")
+	check(isLogLevel(cfg.Server.LogLevel), "server.log_level", "must be one of debug, info, warn or error")
+	check(cfg.Storage.Path != "", "storage.path", "must not be empty")
+	check(cfg.Storage.Timeout > 0, "storage.timeout", "must be greater than zero")
+	check(cfg.Orders.ReservationTTL > 0, "orders.reservation_ttl", "must be greater than zero")
+	check(cfg.Orders.ReservationSweepInterval > 0, "orders.reservation_sweep_interval", "must be greater than zero")
+	check(cfg.Webhooks.DispatchInterval > 0, "webhooks.dispatch_interval", "must be greater than zero")
+	check(cfg.Webhooks.MaxAttempts > 0, "webhooks.max_attempts", "must be at least 1")
+	check(cfg.Webhooks.MaxBackoff > 0, "webhooks.max_backoff", "must be greater than zero")
+	check(cfg.Webhooks.Concurrency > 0, "webhooks.concurrency", "must be at least 1")
+	check(cfg.Feed.Retention > 0, "feed.retention", "must be greater than zero")
+	check(cfg.Feed.TrimInterval > 0, "feed.trim_interval", "must be greater than zero")
+	check(cfg.Auth.TokenSecret == "" || len(cfg.Auth.TokenSecret) >= 32, "auth.token_secret", "must be at least 32 characters long")
+	check(cfg.Auth.TokenTTL > 0, "auth.token_ttl", "must be greater than zero")
+	check(cfg.Limits.MaxQuantityPerProduct > 0, "limits.max_quantity_per_product", "must be at least 1")
+	check(cfg.Limits.PremiumBundleMinProducts > 0, "limits.premium_bundle_min_products", "must be at least 1")
+	check(cfg.Limits.PremiumBundleDiscount > 0 && cfg.Limits.PremiumBundleDiscount <= 1, "limits.premium_bundle_discount", "must be greater than 0 and at most 1")
+	check(cfg.Limits.MaxPageLimit > 0, "limits.max_page_limit", "must be at least 1")
+	check(cfg.Limits.DefaultPageLimit > 0 && cfg.Limits.DefaultPageLimit <= cfg.Limits.MaxPageLimit, "limits.default_page_limit", "must be at least 1 and at most limits.max_page_limit")
+	check(cfg.RateLimits.MaxClients > 0, "rate_limits.max_clients", "must be at least 1")
+	check(cfg.RateLimits.CatalogueReads.RequestsPerMinute >= 0, "rate_limits.catalogue_reads.requests_per_minute", "must not be negative")
+	check(cfg.RateLimits.CatalogueReads.Burst > 0, "rate_limits.catalogue_reads.burst", "must be at least 1")
+	check(cfg.RateLimits.OrderWrites.RequestsPerMinute >= 0, "rate_limits.order_writes.requests_per_minute", "must not be negative")
+	check(cfg.RateLimits.OrderWrites.Burst > 0, "rate_limits.order_writes.burst", "must be at least 1")
+	return errors.Join(errs...)
+}
+
+func isLogLevel(name string) bool {
+	_, err := logging.ParseLevel(name)
+	return err == nil
+}
+
+// Domain returns the limits the interactors are built with.
+func (limits LimitsConfig) Domain() domain.Limits {
+	return domain.Limits{
+		MaxQuantityPerProduct:    limits.MaxQuantityPerProduct,
+		PremiumBundleMinProducts: limits.PremiumBundleMinProducts,
+		PremiumBundleDiscount:    limits.PremiumBundleDiscount,
+		DefaultPageLimit:         limits.DefaultPageLimit,
+		MaxPageLimit:             limits.MaxPageLimit,
+	}
+}
+
+// Duration is a time.Duration written as a string such as "15m" in configuration files.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return d.parse(value)
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var value string
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+	return d.parse(value)
+}
+
+func (d *Duration) parse(value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"simple-order-service/internal/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func lookup(values map[string]string) func(name string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := config.Load("", nil, nil)
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if cfg.Server.ListenAddr != ":8080" || cfg.Storage.Path != "shop.db" || cfg.Limits.MaxQuantityPerProduct != 10 {
+		t.Errorf("Got: %+v, Want: the defaults", cfg)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  listen_addr: ":9000"
+  read_timeout: 5s
+storage:
+  path: file.db
+limits:
+  max_quantity_per_product: 20
+`)
+	env := lookup(map[string]string{"SOS_DB_PATH": "env.db", "SOS_MAX_QUANTITY_PER_PRODUCT": "30"})
+	flags := lookup(map[string]string{"max-quantity-per-product": "40"})
+
+	cfg, err := config.Load(path, env, flags)
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	if cfg.Server.ListenAddr != ":9000" || time.Duration(cfg.Server.ReadTimeout) != 5*time.Second {
+		t.Errorf("Got: %v, %v, Want: the values of the file", cfg.Server.ListenAddr, cfg.Server.ReadTimeout)
+	}
+	if cfg.Storage.Path != "env.db" {
+		t.Errorf("Got: %v, Want: %v", cfg.Storage.Path, "env.db")
+	}
+	if cfg.Limits.MaxQuantityPerProduct != 40 {
+		t.Errorf("Got: %v, Want: %v", cfg.Limits.MaxQuantityPerProduct, 40)
+	}
+	if time.Duration(cfg.Server.WriteTimeout) != 30*time.Second {
+		t.Errorf("Got: %v, Want: %v", cfg.Server.WriteTimeout, 30*time.Second)
+	}
+}
+
+func TestLoad_JSONFile(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"storage": {"path": "file.db", "timeout": "3s"}}`)
+
+	cfg, err := config.Load(path, nil, nil)
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if cfg.Storage.Path != "file.db" || time.Duration(cfg.Storage.Timeout) != 3*time.Second {
+		t.Errorf("Got: %+v, Want: file.db with a timeout of 3s", cfg.Storage)
+	}
+}
+
+func TestLoad_RejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		flags map[string]string
+		want  string
+	}{
+		{"unknown key", `{"server": {"port": 8080}}`, nil, "unknown field"},
+		{"unparseable flag", `{}`, map[string]string{"db-timeout": "soon"}, "db-timeout"},
+		{"invalid value", `{}`, map[string]string{"premium-bundle-discount": "1.5"}, "limits.premium_bundle_discount"},
+		{"short token secret", `{"auth": {"token_secret": "secret"}}`, nil, "auth.token_secret"},
+		{"unknown log level", `{"server": {"log_level": "verbose"}}`, nil, "server.log_level"},
+		{"shared address", `{}`, map[string]string{"grpc-listen-addr": ":8080"}, "server.grpc_listen_addr"},
+		{"no burst", `{"rate_limits": {"order_writes": {"requests_per_minute": 60, "burst": 0}}}`, nil, "rate_limits.order_writes.burst"},
+		{"inconsistent limits", `{"limits": {"default_page_limit": 50, "max_page_limit": 20}}`, nil, "limits.default_page_limit"},
+	}
+	for _, test := range tests {
+		path := writeConfigFile(t, "config.json", test.file)
+		_, err := config.Load(path, nil, lookup(test.flags))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: Got: %v, Want: an error about %s", test.name, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Setting is a setting of the configuration which can be overridden by a command line flag and by
+// an environment variable.
+type Setting struct {
+	Flag  string
+	Usage string
+	get   func(cfg *Config) string
+	set   func(cfg *Config, value string) error
+}
+
+// EnvPrefix prefixes the environment variables of the settings, which are named after their flag,
+// e.g. SOS_LISTEN_ADDR for --listen-addr.
+const EnvPrefix = "SOS_"
+
+func (setting Setting) Env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(setting.Flag, "-", "_"))
+}
+
+// Default returns the default value of the setting, as it would be written on the command line.
+func (setting Setting) Default() string {
+	cfg := Default()
+	return setting.get(&cfg)
+}
+
+func Settings() []Setting {
+	return []Setting{
+		stringSetting("listen-addr", "address on which the web server listens", func(cfg *Config) *string { return &cfg.Server.ListenAddr }),
+		stringSetting("grpc-listen-addr", "address on which the gRPC server listens, empty for no gRPC server", func(cfg *Config) *string { return &cfg.Server.GRPCListenAddr }),
+		durationSetting("read-timeout", "maximum duration for reading a request, 0 for none", func(cfg *Config) *Duration { return &cfg.Server.ReadTimeout }),
+		durationSetting("write-timeout", "maximum duration for writing a response, 0 for none", func(cfg *Config) *Duration { return &cfg.Server.WriteTimeout }),
+		durationSetting("drain-delay", "how long the server reports that it is draining before it stops accepting connections", func(cfg *Config) *Duration { return &cfg.Server.DrainDelay }),
+		durationSetting("shutdown-timeout", "how long the requests in flight are given to complete when the server shuts down", func(cfg *Config) *Duration { return &cfg.Server.ShutdownTimeout }),
+		durationSetting("idempotency-key-ttl", "how long the response to a request made with an idempotency key is replayed to its retries", func(cfg *Config) *Duration { return &cfg.Server.IdempotencyKeyTTL }),
+		intSetting("max-body-bytes", "size in bytes of the largest request body accepted", func(cfg *Config) *int { return &cfg.Server.MaxBodyBytes }),
+		stringSetting("log-level", "lowest level of the lines logged: debug, info, warn or error", func(cfg *Config) *string { return &cfg.Server.LogLevel }),
+		stringSetting("db-path", "path to the bbolt database file", func(cfg *Config) *string { return &cfg.Storage.Path }),
+		durationSetting("db-timeout", "how long to wait for the lock on the database file", func(cfg *Config) *Duration { return &cfg.Storage.Timeout }),
+		stringSetting("pricing-rules", "path to a JSON file listing the pricing rules to apply to orders, in order", func(cfg *Config) *string { return &cfg.Orders.PricingRules }),
+		durationSetting("reservation-ttl", "how long the products added to an open order stay reserved for it", func(cfg *Config) *Duration { return &cfg.Orders.ReservationTTL }),
+		durationSetting("reservation-sweep-interval", "how often expired reservations are released", func(cfg *Config) *Duration { return &cfg.Orders.ReservationSweepInterval }),
+		durationSetting("webhook-dispatch-interval", "how often the events of orders are delivered to webhooks", func(cfg *Config) *Duration { return &cfg.Webhooks.DispatchInterval }),
+		intSetting("webhook-max-attempts", "how many times the delivery of an event to a webhook is attempted before it is dead-lettered", func(cfg *Config) *int { return &cfg.Webhooks.MaxAttempts }),
+		durationSetting("webhook-max-backoff", "the longest delay between two attempts to deliver an event to a webhook", func(cfg *Config) *Duration { return &cfg.Webhooks.MaxBackoff }),
+		intSetting("webhook-concurrency", "how many webhooks events are delivered to at once", func(cfg *Config) *int { return &cfg.Webhooks.Concurrency }),
+		listSetting("webhook-allowed-hosts", "comma separated hosts to which webhooks may deliver events even though they are loopback, link-local or private", func(cfg *Config) *[]string { return &cfg.Webhooks.AllowedHosts }),
+		durationSetting("feed-retention", "how long the events of the feed can be resumed from", func(cfg *Config) *Duration { return &cfg.Feed.Retention }),
+		durationSetting("feed-trim-interval", "how often the events older than the retention are removed from the feed", func(cfg *Config) *Duration { return &cfg.Feed.TrimInterval }),
+		stringSetting("token-secret", "secret with which bearer tokens are signed, of at least 32 characters", func(cfg *Config) *string { return &cfg.Auth.TokenSecret }),
+		durationSetting("token-ttl", "how long a bearer token is valid for", func(cfg *Config) *Duration { return &cfg.Auth.TokenTTL }),
+		intSetting("max-quantity-per-product", "maximum quantity of a product in an order", func(cfg *Config) *int { return &cfg.Limits.MaxQuantityPerProduct }),
+		intSetting("premium-bundle-min-products", "number of unique premium products which earn the premium bundle discount", func(cfg *Config) *int { return &cfg.Limits.PremiumBundleMinProducts }),
+		floatSetting("premium-bundle-discount", "discount rate of the premium bundle", func(cfg *Config) *float64 { return &cfg.Limits.PremiumBundleDiscount }),
+		intSetting("default-page-limit", "number of results in a page when no limit is given", func(cfg *Config) *int { return &cfg.Limits.DefaultPageLimit }),
+		intSetting("max-page-limit", "maximum number of results in a page", func(cfg *Config) *int { return &cfg.Limits.MaxPageLimit }),
+		intSetting("rate-limit-max-clients", "number of clients whose rate limits are kept in memory", func(cfg *Config) *int { return &cfg.RateLimits.MaxClients }),
+		intSetting("catalogue-reads-per-minute", "requests a client may make to read the catalogue a minute, 0 for no limit", func(cfg *Config) *int { return &cfg.RateLimits.CatalogueReads.RequestsPerMinute }),
+		intSetting("catalogue-reads-burst", "requests a client may make at once to read the catalogue", func(cfg *Config) *int { return &cfg.RateLimits.CatalogueReads.Burst }),
+		intSetting("order-writes-per-minute", "requests a client may make to change orders a minute, 0 for no limit", func(cfg *Config) *int { return &cfg.RateLimits.OrderWrites.RequestsPerMinute }),
+		intSetting("order-writes-burst", "requests a client may make at once to change orders", func(cfg *Config) *int { return &cfg.RateLimits.OrderWrites.Burst }),
+	}
+}
+
+func stringSetting(flag, usage string, field func(cfg *Config) *string) Setting {
+	return Setting{
+		Flag:  flag,
+		Usage: usage,
+		get:   func(cfg *Config) string { return *field(cfg) },
+		set: func(cfg *Config, value string) error {
+			*field(cfg) = value
+			return nil
+		},
+	}
+}
+
+func durationSetting(flag, usage string, field func(cfg *Config) *Duration) Setting {
+	return Setting{
+		Flag:  flag,
+		Usage: usage,
+		get:   func(cfg *Config) string { return field(cfg).String() },
+		set: func(cfg *Config, value string) error {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			*field(cfg) = Duration(parsed)
+			return nil
+		},
+	}
+}
+
+func intSetting(flag, usage string, field func(cfg *Config) *int) Setting {
+	return Setting{
+		Flag:  flag,
+		Usage: usage,
+		get:   func(cfg *Config) string { return strconv.Itoa(*field(cfg)) },
+		set: func(cfg *Config, value string) error {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			*field(cfg) = parsed
+			return nil
+		},
+	}
+}
+
+func floatSetting(flag, usage string, field func(cfg *Config) *float64) Setting {
+	return Setting{
+		Flag:  flag,
+		Usage: usage,
+		get:   func(cfg *Config) string { return strconv.FormatFloat(*field(cfg), 'f', -1, 64) },
+		set: func(cfg *Config, value string) error {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			*field(cfg) = parsed
+			return nil
+		},
+	}
+}
+
+func listSetting(flag, usage string, field func(cfg *Config) *[]string) Setting {
+	return Setting{
+		Flag:  flag,
+		Usage: usage,
+		get:   func(cfg *Config) string { return strings.Join(*field(cfg), ",") },
+		set: func(cfg *Config, value string) error {
+			values := make([]string, 0)
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					values = append(values, item)
+				}
+			}
+			*field(cfg) = values
+			return nil
+		},
+	}
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+//go:generate moq -out api_key_repository_mock.go . APIKeyRepository
+
+type APIKeyRepository interface {
+	Store(ctx context.Context, key APIKey) error
+	FindById(ctx context.Context, id string) APIKey
+	GetAll(ctx context.Context) []APIKey
+	Delete(ctx context.Context, id string) error
+}
+
+var (
+	ErrInvalidRole    = errors.New("invalid role. the different roles are: 'customer', 'operator' and 'admin'")
+	ErrMissingSubject = errors.New("subject must not be empty")
+)
+
+type AuthError struct {
+	Err error
+}
+
+func (e AuthError) Error() string {
+	return e.Err.Error()
+}
+
+// Role decides what a principal is allowed to do. Customers place their own orders, operators
+// fulfil orders, and admins manage the catalogue and everything else.
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+func (role Role) IsValid() bool {
+	switch role {
+	case RoleCustomer, RoleOperator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Principal is who a request is made by. The subject of a customer is the id of the customer.
+type Principal struct {
+	Subject string
+	Role    Role
+}
+
+// CanView reports whether the principal may read the order. Customers may only read the orders they
+// own.
+func (principal Principal) CanView(order Order) bool {
+	return principal.Role != RoleCustomer || order.customerID == principal.Subject
+}
+
+// CanChange reports whether the principal may change the order. Customers may only change the orders
+// they own while they are open.
+func (principal Principal) CanChange(order Order) bool {
+	return principal.CanView(order) && (principal.Role != RoleCustomer || order.status == OrderOpen)
+}
+
+// CanChangeStatus reports whether the principal may move the order to the status. Customers may only
+// place or cancel the orders they may change.
+func (principal Principal) CanChangeStatus(order Order, status OrderStatus) bool {
+	if !principal.CanChange(order) {
+		return false
+	}
+	return principal.Role != RoleCustomer || status == OrderPlaced || status == OrderCancelled
+}
+
+// CanChangeDispatchDate reports whether the principal may change the dispatch date of the order,
+// which is left to the staff.
+func (principal Principal) CanChangeDispatchDate(order Order) bool {
+	return principal.Role != RoleCustomer && principal.CanChange(order)
+}
+
+// apiKeyPrefix starts every API key, so that keys leaked in logs or in source code are easy to spot.
+const apiKeyPrefix = "sos_"
+
+// APIKey lets a principal authenticate. Only a hash of the secret of the key is kept; the secret is
+// shown once, when the key is created.
+type APIKey struct {
+	id         string
+	subject    string
+	role       Role
+	secretHash string
+	createdAt  time.Time
+}
+
+func NewAPIKey(id, subject string, role Role, secret string, createdAt time.Time) APIKey {
+	return APIKey{
+		id:         id,
+		subject:    subject,
+		role:       role,
+		secretHash: hashSecret(secret),
+		createdAt:  createdAt,
+	}
+}
+
+// FormatAPIKey builds the key handed to the principal from the id and the secret of the key.
+func FormatAPIKey(id, secret string) string {
+	return apiKeyPrefix + id + "_" + secret
+}
+
+// ParseAPIKey splits a key built by FormatAPIKey into its id and its secret.
+func ParseAPIKey(key string) (id, secret string, ok bool) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return "", "", false
+	}
+	id, secret, ok = strings.Cut(strings.TrimPrefix(key, apiKeyPrefix), "_")
+	return id, secret, ok && id != "" && secret != ""
+}
+
+// Secrets are long random strings, so a single round of SHA-256 is enough to keep them from being
+// recovered from the database.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (key *APIKey) ID() string {
+	return key.id
+}
+
+func (key *APIKey) Subject() string {
+	return key.subject
+}
+
+func (key *APIKey) Role() Role {
+	return key.role
+}
+
+func (key *APIKey) CreatedAt() time.Time {
+	return key.createdAt
+}
+
+func (key *APIKey) Principal() Principal {
+	return Principal{Subject: key.subject, Role: key.role}
+}
+
+// Matches compares the secret with the one of the key in constant time.
+func (key *APIKey) Matches(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.secretHash)) == 1
+}
+
+func (key *APIKey) Validate() error {
+	if strings.TrimSpace(key.subject) == "" {
+		return &AuthError{Err: ErrMissingSubject}
+	}
+	if !key.role.IsValid() {
+		return &AuthError{Err: ErrInvalidRole}
+	}
+	return nil
+}
+
+func (key *APIKey) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		ID         string    `json:"id"`
+		Subject    string    `json:"subject"`
+		Role       Role      `json:"role"`
+		SecretHash string    `json:"secret_hash"`
+		CreatedAt  time.Time `json:"created_at"`
+	}{
+		ID:         key.id,
+		Subject:    key.subject,
+		Role:       key.role,
+		SecretHash: key.secretHash,
+		CreatedAt:  key.createdAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (key *APIKey) UnmarshalJSON(data []byte) error {
+	type apiKey struct {
+		ID         string    `json:"id"`
+		Subject    string    `json:"subject"`
+		Role       Role      `json:"role"`
+		SecretHash string    `json:"secret_hash"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+	k := &apiKey{}
+	if err := json.Unmarshal(data, k); err != nil {
+		return err
+	}
+	key.id = k.ID
+	key.subject = k.Subject
+	key.role = k.Role
+	key.secretHash = k.SecretHash
+	key.createdAt = k.CreatedAt
+	return nil
+}
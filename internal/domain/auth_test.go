@@ -0,0 +1,93 @@
+package domain_test
+
+import (
+	"simple-order-service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestPrincipal_CustomersOnlyChangeTheirOwnOpenOrders(t *testing.T) {
+	own := domain.NewCustomerOrder("1", "c-1")
+	placed := domain.NewCustomerOrder("2", "c-1")
+	placed.SetOrderStatus(domain.OrderPlaced, "c-1")
+	other := domain.NewCustomerOrder("3", "c-2")
+
+	customer := domain.Principal{Subject: "c-1", Role: domain.RoleCustomer}
+	operator := domain.Principal{Subject: "ops", Role: domain.RoleOperator}
+
+	tests := []struct {
+		name       string
+		principal  domain.Principal
+		order      domain.Order
+		wantView   bool
+		wantChange bool
+	}{
+		{"own open order", customer, own, true, true},
+		{"own placed order", customer, placed, true, false},
+		{"order of another customer", customer, other, false, false},
+		{"operator", operator, other, true, true},
+	}
+	for _, test := range tests {
+		if got := test.principal.CanView(test.order); got != test.wantView {
+			t.Errorf("%s: CanView: Got: %v, Want: %v", test.name, got, test.wantView)
+		}
+		if got := test.principal.CanChange(test.order); got != test.wantChange {
+			t.Errorf("%s: CanChange: Got: %v, Want: %v", test.name, got, test.wantChange)
+		}
+	}
+}
+
+func TestPrincipal_CustomersOnlyPlaceOrCancelTheirOwnOpenOrders(t *testing.T) {
+	own := domain.NewCustomerOrder("1", "c-1")
+	other := domain.NewCustomerOrder("2", "c-2")
+
+	customer := domain.Principal{Subject: "c-1", Role: domain.RoleCustomer}
+	operator := domain.Principal{Subject: "ops", Role: domain.RoleOperator}
+
+	tests := []struct {
+		name      string
+		principal domain.Principal
+		order     domain.Order
+		status    domain.OrderStatus
+		want      bool
+	}{
+		{"place own order", customer, own, domain.OrderPlaced, true},
+		{"cancel own order", customer, own, domain.OrderCancelled, true},
+		{"complete own order", customer, own, domain.OrderCompleted, false},
+		{"place order of another customer", customer, other, domain.OrderPlaced, false},
+		{"operator", operator, other, domain.OrderCompleted, true},
+	}
+	for _, test := range tests {
+		if got := test.principal.CanChangeStatus(test.order, test.status); got != test.want {
+			t.Errorf("%s: Got: %v, Want: %v", test.name, got, test.want)
+		}
+	}
+	if customer.CanChangeDispatchDate(own) {
+		t.Error("customers must not change the dispatch date")
+	}
+	if !operator.CanChangeDispatchDate(own) {
+		t.Error("operators must change the dispatch date")
+	}
+}
+
+func TestAPIKey_MatchesOnlyItsSecret(t *testing.T) {
+	key := domain.NewAPIKey("k1", "c-1", domain.RoleCustomer, "s3cret", time.Now())
+
+	id, secret, ok := domain.ParseAPIKey(domain.FormatAPIKey("k1", "s3cret"))
+	if !ok || id != "k1" || !key.Matches(secret) {
+		t.Errorf("Got: %v, %v, %v, Want: the key to match its own secret", id, secret, ok)
+	}
+	if key.Matches("s3cret2") {
+		t.Error("key must not match another secret")
+	}
+	if _, _, ok := domain.ParseAPIKey("k1_s3cret"); ok {
+		t.Error("keys without the prefix must be rejected")
+	}
+
+	data, _ := key.MarshalJSON()
+	stored := domain.APIKey{}
+	stored.UnmarshalJSON(data)
+	if !stored.Matches("s3cret") {
+		t.Error("stored key must still match its secret")
+	}
+}
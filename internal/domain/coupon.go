@@ -0,0 +1,244 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//go:generate moq -out coupon_repository_mock.go . CouponRepository
+
+type CouponRepository interface {
+	Store(ctx context.Context, coupon Coupon) error
+	FindByCode(ctx context.Context, code string) Coupon
+	GetAll(ctx context.Context) []Coupon
+}
+
+type DiscountType string
+
+const (
+	PercentDiscount DiscountType = "percent"
+	FixedDiscount   DiscountType = "fixed"
+)
+
+var (
+	ErrMissingCouponCode     = errors.New("coupon code must not be empty")
+	ErrInvalidDiscountType   = errors.New("invalid discount type. the different discount type values are: 'percent' and 'fixed'")
+	ErrInvalidCouponValue    = errors.New("coupon value must be greater than zero, and at most 1 for percent discounts")
+	ErrInvalidValidityWindow = errors.New("coupon must be valid until a time after it becomes valid")
+	ErrInvalidMaxRedemptions = errors.New("maximum redemptions of a coupon must not be negative")
+
+	ErrCouponNotYetValid = func(code string) error {
+		return fmt.Errorf("coupon: %s is not valid yet", code)
+	}
+	ErrCouponExpired = func(code string) error {
+		return fmt.Errorf("coupon: %s has expired", code)
+	}
+	ErrCouponUsageLimitReached = func(code string) error {
+		return fmt.Errorf("coupon: %s has reached its usage limit", code)
+	}
+	ErrCouponAlreadyApplied = func(code string) error {
+		return fmt.Errorf("coupon: %s has already been applied to the order", code)
+	}
+	ErrCouponNotApplied = func(code string) error {
+		return fmt.Errorf("coupon: %s has not been applied to the order", code)
+	}
+	ErrCouponNotStackable = func(code string) error {
+		return fmt.Errorf("coupon: %s cannot be combined with other coupons", code)
+	}
+)
+
+type CouponError struct {
+	Err error
+}
+
+func (e CouponError) Error() string {
+	return e.Err.Error()
+}
+
+// Coupon is a promotional code which takes a discount off the value of the orders it is applied to.
+// A coupon can only be applied between validFrom and validUntil, and at most maxRedemptions times
+// when maxRedemptions is not zero. A coupon which is not stackable cannot be applied to an order
+// along with any other coupon.
+type Coupon struct {
+	code           string
+	discountType   DiscountType
+	value          float64
+	validFrom      time.Time
+	validUntil     time.Time
+	maxRedemptions int
+	redemptions    int
+	stackable      bool
+}
+
+func NewCoupon(code string, discountType DiscountType, value float64, validFrom, validUntil time.Time, maxRedemptions int, stackable bool) Coupon {
+	return Coupon{
+		code:           NormalizeCouponCode(code),
+		discountType:   discountType,
+		value:          value,
+		validFrom:      validFrom,
+		validUntil:     validUntil,
+		maxRedemptions: maxRedemptions,
+		stackable:      stackable,
+	}
+}
+
+// NormalizeCouponCode makes coupon codes case insensitive.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (coupon *Coupon) Code() string {
+	return coupon.code
+}
+
+func (coupon *Coupon) DiscountType() DiscountType {
+	return coupon.discountType
+}
+
+func (coupon *Coupon) Value() float64 {
+	return coupon.value
+}
+
+func (coupon *Coupon) ValidFrom() time.Time {
+	return coupon.validFrom
+}
+
+func (coupon *Coupon) ValidUntil() time.Time {
+	return coupon.validUntil
+}
+
+func (coupon *Coupon) MaxRedemptions() int {
+	return coupon.maxRedemptions
+}
+
+func (coupon *Coupon) Redemptions() int {
+	return coupon.redemptions
+}
+
+func (coupon *Coupon) IsStackable() bool {
+	return coupon.stackable
+}
+
+func (coupon *Coupon) Validate() error {
+	if coupon.code == "" {
+		return &CouponError{Err: ErrMissingCouponCode}
+	}
+	if coupon.discountType != PercentDiscount && coupon.discountType != FixedDiscount {
+		return &CouponError{Err: ErrInvalidDiscountType}
+	}
+	if coupon.value <= 0 || (coupon.discountType == PercentDiscount && coupon.value > 1) {
+		return &CouponError{Err: ErrInvalidCouponValue}
+	}
+	if !coupon.validUntil.After(coupon.validFrom) {
+		return &CouponError{Err: ErrInvalidValidityWindow}
+	}
+	if coupon.maxRedemptions < 0 {
+		return &CouponError{Err: ErrInvalidMaxRedemptions}
+	}
+	return nil
+}
+
+// CheckApplicable returns an error if the coupon cannot be applied to an order at the given time.
+func (coupon *Coupon) CheckApplicable(at time.Time) error {
+	if at.Before(coupon.validFrom) {
+		return &CouponError{Err: ErrCouponNotYetValid(coupon.code)}
+	}
+	if !at.Before(coupon.validUntil) {
+		return &CouponError{Err: ErrCouponExpired(coupon.code)}
+	}
+	if coupon.maxRedemptions > 0 && coupon.redemptions >= coupon.maxRedemptions {
+		return &CouponError{Err: ErrCouponUsageLimitReached(coupon.code)}
+	}
+	return nil
+}
+
+func (coupon *Coupon) Redeem() error {
+	if coupon.maxRedemptions > 0 && coupon.redemptions >= coupon.maxRedemptions {
+		return &CouponError{Err: ErrCouponUsageLimitReached(coupon.code)}
+	}
+	coupon.redemptions += 1
+	return nil
+}
+
+// ReleaseRedemption gives back a redemption, e.g. when the coupon is removed from a placed order.
+func (coupon *Coupon) ReleaseRedemption() {
+	if coupon.redemptions > 0 {
+		coupon.redemptions -= 1
+	}
+}
+
+func (coupon *Coupon) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		Code           string       `json:"code"`
+		DiscountType   DiscountType `json:"discount_type"`
+		Value          float64      `json:"value"`
+		ValidFrom      time.Time    `json:"valid_from"`
+		ValidUntil     time.Time    `json:"valid_until"`
+		MaxRedemptions int          `json:"max_redemptions"`
+		Redemptions    int          `json:"redemptions"`
+		Stackable      bool         `json:"stackable"`
+	}{
+		Code:           coupon.code,
+		DiscountType:   coupon.discountType,
+		Value:          coupon.value,
+		ValidFrom:      coupon.validFrom,
+		ValidUntil:     coupon.validUntil,
+		MaxRedemptions: coupon.maxRedemptions,
+		Redemptions:    coupon.redemptions,
+		Stackable:      coupon.stackable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (coupon *Coupon) UnmarshalJSON(data []byte) error {
+	type cpn struct {
+		Code           string       `json:"code"`
+		DiscountType   DiscountType `json:"discount_type"`
+		Value          float64      `json:"value"`
+		ValidFrom      time.Time    `json:"valid_from"`
+		ValidUntil     time.Time    `json:"valid_until"`
+		MaxRedemptions int          `json:"max_redemptions"`
+		Redemptions    int          `json:"redemptions"`
+		Stackable      bool         `json:"stackable"`
+	}
+	c := &cpn{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return err
+	}
+	coupon.code = c.Code
+	coupon.discountType = c.DiscountType
+	coupon.value = c.Value
+	coupon.validFrom = c.ValidFrom
+	coupon.validUntil = c.ValidUntil
+	coupon.maxRedemptions = c.MaxRedemptions
+	coupon.redemptions = c.Redemptions
+	coupon.stackable = c.Stackable
+	return nil
+}
+
+// AppliedCoupon is the snapshot of a coupon kept in an order, so that the value of the order does
+// not change if the coupon is changed later.
+type AppliedCoupon struct {
+	Code         string       `json:"code"`
+	DiscountType DiscountType `json:"discount_type"`
+	Value        float64      `json:"value"`
+	Stackable    bool         `json:"stackable"`
+}
+
+func (applied AppliedCoupon) Name() string {
+	return "coupon " + applied.Code
+}
+
+func (applied AppliedCoupon) Discount(_ []OrderLine, total float64) float64 {
+	if applied.DiscountType == PercentDiscount {
+		return total * applied.Value
+	}
+	return applied.Value
+}
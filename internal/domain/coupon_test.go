@@ -0,0 +1,113 @@
+package domain_test
+
+import (
+	"errors"
+	"simple-order-service/internal/domain"
+	"testing"
+	"time"
+)
+
+func newTestCoupon(code string, discountType domain.DiscountType, value float64, maxRedemptions int, stackable bool) domain.Coupon {
+	now := time.Now()
+	return domain.NewCoupon(code, discountType, value, now.Add(-time.Hour), now.Add(time.Hour), maxRedemptions, stackable)
+}
+
+func TestApplyCouponToOrder_DiscountsOrderValue(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.AddQuantity(domain.NewProduct("1", "tie", 10.0, 10, domain.Budget), 5, domain.DefaultLimits())
+
+	order.ApplyCoupon(newTestCoupon("save10", domain.PercentDiscount, 0.1, 0, true), time.Now())
+	order.ApplyCoupon(newTestCoupon("minus5", domain.FixedDiscount, 5.0, 0, true), time.Now())
+
+	got := order.Value(defaultEngine)
+	want := 40.0
+	if got != want {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+	if order.AppliedCoupons()[0].Code != "SAVE10" {
+		t.Errorf("Got: %v, Want: %v", order.AppliedCoupons()[0].Code, "SAVE10")
+	}
+}
+
+func TestApplyCouponToOrder_Expired(t *testing.T) {
+	order := domain.NewOrder("123")
+	coupon := newTestCoupon("save10", domain.PercentDiscount, 0.1, 0, true)
+
+	got := order.ApplyCoupon(coupon, time.Now().Add(2*time.Hour))
+	want := domain.CouponError{Err: domain.ErrCouponExpired("SAVE10")}
+	if got == nil || got.Error() != want.Error() {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestApplyCouponToOrder_UsageLimitReached(t *testing.T) {
+	order := domain.NewOrder("123")
+	coupon := newTestCoupon("once", domain.FixedDiscount, 5.0, 1, true)
+	coupon.Redeem()
+
+	got := order.ApplyCoupon(coupon, time.Now())
+	want := domain.CouponError{Err: domain.ErrCouponUsageLimitReached("ONCE")}
+	if got == nil || got.Error() != want.Error() {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestApplyCouponToOrder_NotStackable(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.ApplyCoupon(newTestCoupon("stack", domain.FixedDiscount, 5.0, 0, true), time.Now())
+
+	got := order.ApplyCoupon(newTestCoupon("solo", domain.FixedDiscount, 5.0, 0, false), time.Now())
+	want := domain.OrderError{Err: domain.ErrCouponNotStackable("SOLO")}
+	if got == nil || got.Error() != want.Error() {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestApplyCouponToOrder_AlreadyApplied(t *testing.T) {
+	order := domain.NewOrder("123")
+	coupon := newTestCoupon("stack", domain.FixedDiscount, 5.0, 0, true)
+	order.ApplyCoupon(coupon, time.Now())
+
+	got := order.ApplyCoupon(coupon, time.Now())
+	var orderErr *domain.OrderError
+	if !errors.As(got, &orderErr) {
+		t.Errorf("Got: %v, Want: %v", got, domain.ErrCouponAlreadyApplied("STACK"))
+	}
+}
+
+func TestRemoveCouponFromOrder(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.ApplyCoupon(newTestCoupon("stack", domain.FixedDiscount, 5.0, 0, true), time.Now())
+
+	if err := order.RemoveCoupon("Stack"); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if len(order.AppliedCoupons()) != 0 {
+		t.Errorf("Got: %v coupons, Want: %v", len(order.AppliedCoupons()), 0)
+	}
+	if err := order.RemoveCoupon("stack"); err == nil {
+		t.Error("removing a coupon which is not applied must fail")
+	}
+}
+
+func TestValidateCoupon_Errors(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		coupon domain.Coupon
+		want   error
+	}{
+		{domain.NewCoupon(" ", domain.FixedDiscount, 5.0, now, now.Add(time.Hour), 0, false), domain.ErrMissingCouponCode},
+		{domain.NewCoupon("a", domain.DiscountType("bogo"), 5.0, now, now.Add(time.Hour), 0, false), domain.ErrInvalidDiscountType},
+		{domain.NewCoupon("a", domain.PercentDiscount, 1.5, now, now.Add(time.Hour), 0, false), domain.ErrInvalidCouponValue},
+		{domain.NewCoupon("a", domain.FixedDiscount, 5.0, now, now, 0, false), domain.ErrInvalidValidityWindow},
+		{domain.NewCoupon("a", domain.FixedDiscount, 5.0, now, now.Add(time.Hour), -1, false), domain.ErrInvalidMaxRedemptions},
+	}
+
+	for _, test := range tests {
+		got := test.coupon.Validate()
+		var couponErr *domain.CouponError
+		if !errors.As(got, &couponErr) || !errors.Is(couponErr.Err, test.want) {
+			t.Errorf("Got: %v, Want: %v", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+//go:generate moq -out customer_repository_mock.go . CustomerRepository
+
+type CustomerRepository interface {
+	Store(ctx context.Context, customer Customer) error
+	FindById(ctx context.Context, id string) Customer
+	FindByEmail(ctx context.Context, email string) Customer
+	GetAll(ctx context.Context) []Customer
+}
+
+var (
+	ErrMissingCustomerName  = errors.New("customer name must not be empty")
+	ErrInvalidCustomerEmail = errors.New("customer email must be a valid email address")
+)
+
+type CustomerError struct {
+	Err error
+}
+
+func (e CustomerError) Error() string {
+	return e.Err.Error()
+}
+
+// Customer owns the orders it places. The API keys of a customer are issued for the id of the
+// customer.
+type Customer struct {
+	id        string
+	name      string
+	email     string
+	contact   string
+	createdAt time.Time
+}
+
+// NewCustomer creates a customer. The email is stored in lower case, since it identifies the customer.
+func NewCustomer(id, name, email, contact string, createdAt time.Time) Customer {
+	return Customer{
+		id:        id,
+		name:      strings.TrimSpace(name),
+		email:     NormalizeEmail(email),
+		contact:   strings.TrimSpace(contact),
+		createdAt: createdAt,
+	}
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (customer *Customer) ID() string {
+	return customer.id
+}
+
+func (customer *Customer) Name() string {
+	return customer.name
+}
+
+func (customer *Customer) Email() string {
+	return customer.email
+}
+
+// Contact holds how else the customer can be reached, such as a phone number or a postal address.
+func (customer *Customer) Contact() string {
+	return customer.contact
+}
+
+func (customer *Customer) CreatedAt() time.Time {
+	return customer.createdAt
+}
+
+func (customer *Customer) Validate() error {
+	if customer.name == "" {
+		return &CustomerError{Err: ErrMissingCustomerName}
+	}
+	address, err := mail.ParseAddress(customer.email)
+	if err != nil || address.Address != customer.email {
+		return &CustomerError{Err: ErrInvalidCustomerEmail}
+	}
+	return nil
+}
+
+func (customer *Customer) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		ID        string    `json:"id"`
+		Name      string    `json:"name"`
+		Email     string    `json:"email"`
+		Contact   string    `json:"contact,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+	}{
+		ID:        customer.id,
+		Name:      customer.name,
+		Email:     customer.email,
+		Contact:   customer.contact,
+		CreatedAt: customer.createdAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (customer *Customer) UnmarshalJSON(data []byte) error {
+	type cust struct {
+		ID        string    `json:"id"`
+		Name      string    `json:"name"`
+		Email     string    `json:"email"`
+		Contact   string    `json:"contact"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	c := &cust{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return err
+	}
+	customer.id = c.ID
+	customer.name = c.Name
+	customer.email = c.Email
+	customer.contact = c.Contact
+	customer.createdAt = c.CreatedAt
+	return nil
+}
@@ -0,0 +1,36 @@
+package domain_test
+
+import (
+	"simple-order-service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestCustomer_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		customer domain.Customer
+		want     error
+	}{
+		{"valid", domain.NewCustomer("c-1", "Jane Doe", " Jane@Example.com ", "", time.Now()), nil},
+		{"no name", domain.NewCustomer("c-1", " ", "jane@example.com", "", time.Now()), domain.ErrMissingCustomerName},
+		{"no email", domain.NewCustomer("c-1", "Jane Doe", "", "", time.Now()), domain.ErrInvalidCustomerEmail},
+		{"display name", domain.NewCustomer("c-1", "Jane Doe", "Jane <jane@example.com>", "", time.Now()), domain.ErrInvalidCustomerEmail},
+	}
+	for _, test := range tests {
+		got := test.customer.Validate()
+		if (got == nil) != (test.want == nil) || (got != nil && got.Error() != test.want.Error()) {
+			t.Errorf("%s: Got: %v, Want: %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestOrderQuery_MatchesOrdersOfCustomer(t *testing.T) {
+	query := domain.OrderQuery{CustomerID: "c-1"}
+	if !query.Matches(domain.NewCustomerOrder("1", "c-1")) {
+		t.Error("query must match the orders of the customer")
+	}
+	if query.Matches(domain.NewCustomerOrder("2", "c-2")) || query.Matches(domain.NewOrder("3")) {
+		t.Error("query must not match the orders of other customers")
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate moq -out feed_repository_mock.go . FeedRepository
+
+// FeedRepository holds the changes pushed to clients following the activity of the shop, in the
+// order in which they were committed. DeleteBefore trims the events which happened before the given
+// time, except for the last one, so that the sequence of the feed carries on from it.
+type FeedRepository interface {
+	Since(ctx context.Context, sequence uint64, limit int) []FeedEvent
+	LastSequence(ctx context.Context) uint64
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+	Watch() (<-chan struct{}, func())
+}
+
+type FeedEventType string
+
+const (
+	FeedOrderStatusChanged   FeedEventType = "order_status_changed"
+	FeedOrderDispatchDateSet FeedEventType = "order_dispatch_date_set"
+	FeedProductStockChanged  FeedEventType = "product_stock_changed"
+)
+
+// FeedEvent is a change to an order or to the stock of a product. Events are numbered by an
+// increasing sequence, so that a client which has seen an event can resume the feed after it.
+type FeedEvent struct {
+	Sequence uint64        `json:"sequence"`
+	Type     FeedEventType `json:"type"`
+	At       time.Time     `json:"at"`
+
+	OrderID        string      `json:"order_id,omitempty"`
+	Status         OrderStatus `json:"status,omitempty"`
+	PreviousStatus OrderStatus `json:"previous_status,omitempty"`
+	DispatchDate   string      `json:"dispatch_date,omitempty"`
+
+	ProductID string      `json:"product_id,omitempty"`
+	Stock     *StockLevel `json:"stock,omitempty"`
+}
+
+type StockLevel struct {
+	Available int `json:"available"`
+	Reserved  int `json:"reserved"`
+}
+
+// FeedEventFromOrderEvent returns the feed event for the order event, and false for the events
+// which are not published to the feed.
+func FeedEventFromOrderEvent(event OrderEvent) (FeedEvent, bool) {
+	switch event.Type {
+	case OrderStatusChanged:
+		return FeedEvent{
+			Type:           FeedOrderStatusChanged,
+			At:             event.At,
+			OrderID:        event.OrderID,
+			Status:         event.Transition.To,
+			PreviousStatus: event.Transition.From,
+		}, true
+	case OrderDispatchDateSet:
+		return FeedEvent{
+			Type:         FeedOrderDispatchDateSet,
+			At:           event.At,
+			OrderID:      event.OrderID,
+			DispatchDate: event.DispatchDate,
+		}, true
+	}
+	return FeedEvent{}, false
+}
+
+func NewStockChangedEvent(product Product, at time.Time) FeedEvent {
+	return FeedEvent{
+		Type:      FeedProductStockChanged,
+		At:        at,
+		ProductID: product.id,
+		Stock:     &StockLevel{Available: product.Available(), Reserved: product.reserved},
+	}
+}
+
+// StockChanged reports whether the stock of the product differs from the stock of the given
+// previous state of the product.
+func (product *Product) StockChanged(previous Product) bool {
+	return product.Available() != previous.Available() || product.reserved != previous.reserved
+}
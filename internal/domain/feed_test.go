@@ -0,0 +1,46 @@
+package domain_test
+
+import (
+	"simple-order-service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestFeedEventFromOrderEvent(t *testing.T) {
+	order := domain.NewOrder("1")
+	order.Add(domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium))
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+
+	published := make([]domain.FeedEvent, 0)
+	for _, event := range order.PendingEvents() {
+		if feedEvent, ok := domain.FeedEventFromOrderEvent(event); ok {
+			published = append(published, feedEvent)
+		}
+	}
+
+	if len(published) != 1 {
+		t.Fatalf("Got: %v, Want: only the status change", published)
+	}
+	got := published[0]
+	if got.Type != domain.FeedOrderStatusChanged || got.OrderID != "1" || got.PreviousStatus != domain.OrderOpen || got.Status != domain.OrderPlaced {
+		t.Errorf("Got: %+v, Want: order 1 changed from %v to %v", got, domain.OrderOpen, domain.OrderPlaced)
+	}
+}
+
+func TestNewStockChangedEvent(t *testing.T) {
+	previous := domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)
+	product := previous
+	if product.StockChanged(previous) {
+		t.Errorf("Got: %v, Want: %v", true, false)
+	}
+
+	product.Reserve(2)
+	if !product.StockChanged(previous) {
+		t.Errorf("Got: %v, Want: %v", false, true)
+	}
+
+	event := domain.NewStockChangedEvent(product, time.Now())
+	if event.ProductID != "123" || event.Stock == nil || event.Stock.Available != 3 || event.Stock.Reserved != 2 {
+		t.Errorf("Got: %+v, Want: 3 available and 2 reserved units of product 123", event)
+	}
+}
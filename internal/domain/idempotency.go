@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate moq -out idempotency_repository_mock.go . IdempotencyRepository
+
+type IdempotencyRepository interface {
+	Store(ctx context.Context, record IdempotencyRecord) error
+	FindByKey(ctx context.Context, key string) IdempotencyRecord
+	DeleteExpired(ctx context.Context, at time.Time) (int, error)
+}
+
+// IdempotencyRecord is the response given to the first request made with an idempotency key. Retries
+// of the request with the same key are given the same response instead of being applied again, as
+// long as they carry the same fingerprint and the record has not expired.
+type IdempotencyRecord struct {
+	Key         string              `json:"key"`
+	Fingerprint string              `json:"fingerprint"`
+	StatusCode  int                 `json:"status_code"`
+	Header      map[string][]string `json:"header"`
+	Body        []byte              `json:"body"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+}
+
+func (record IdempotencyRecord) IsExpired(at time.Time) bool {
+	return !at.Before(record.ExpiresAt)
+}
@@ -0,0 +1,30 @@
+package domain
+
+// The limits of the service default to these values.
+const (
+	DefaultMaxQuantityPerProduct    = 10
+	DefaultPremiumBundleMinProducts = 3
+	DefaultPremiumBundleDiscount    = 0.1
+	DefaultPageLimit                = 20
+	DefaultMaxPageLimit             = 100
+)
+
+// Limits are the business limits of orders and the sizes of the pages of results, which are read from
+// the configuration of the service.
+type Limits struct {
+	MaxQuantityPerProduct    int
+	PremiumBundleMinProducts int
+	PremiumBundleDiscount    float64
+	DefaultPageLimit         int
+	MaxPageLimit             int
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		MaxQuantityPerProduct:    DefaultMaxQuantityPerProduct,
+		PremiumBundleMinProducts: DefaultPremiumBundleMinProducts,
+		PremiumBundleDiscount:    DefaultPremiumBundleDiscount,
+		DefaultPageLimit:         DefaultPageLimit,
+		MaxPageLimit:             DefaultMaxPageLimit,
+	}
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,27 +11,34 @@ import (
 //go:generate moq -out order_repository_mock.go . OrderRepository
 
 type OrderRepository interface {
-	Store(order Order) error
-	FindById(id string) Order
-	GetAll() []Order // Ideally this API should return paginated results, but for the sake of simplicity, we will be returning all results, assuming results are not many
+	Store(ctx context.Context, order Order) error
+	FindById(ctx context.Context, id string) Order
+	GetAll(ctx context.Context) []Order
+	Find(ctx context.Context, query OrderQuery) ([]Order, string, error)
+	Events(ctx context.Context, orderID string) ([]OrderEvent, error)
 }
 
-const (
-	MinUniquePremProductsForDiscount       int     = 3
-	MaxUniqueProductsPerOrder              int     = 10
-	DiscountValueIfThreeUniquePremProducts float64 = 0.1
-)
-
 var (
 	ErrInvalidDispatchDateWithOrderNotDispatched = errors.New("cannot set the dispatch date as order is not yet dispatched")
 	ErrInvalidDispatchDateFormat                 = errors.New("invalid dispatch date format. please provide the correct date")
 	ErrInvalidDispatchDate                       = errors.New("dispatch date must be after the current date")
+	ErrInvalidOrderStatus                        = errors.New("invalid order status. the different order status values are: 'open', 'placed', 'dispatched', 'cancelled' and 'completed'")
+
+	ErrInvalidStatusTransition = func(from, to OrderStatus) error {
+		return fmt.Errorf("order status cannot be changed from %s to %s", from, to)
+	}
 
 	ErrUnavailableProduct = func(name string) error {
 		return fmt.Errorf("product: %s cannot be added to the order as it is not available", name)
 	}
-	ErrMaxAllowedQuantity = func(name string) error {
-		return fmt.Errorf("product: %s cannot be added to the order as it exceeds the maximum allowed quantity per order, i.e., %d", name, MaxUniqueProductsPerOrder)
+	ErrMaxAllowedQuantity = func(name string, max int) error {
+		return fmt.Errorf("product: %s cannot be added to the order as it exceeds the maximum allowed quantity per order, i.e., %d", name, max)
+	}
+	ErrInvalidQuantity = func(name string) error {
+		return fmt.Errorf("product: %s cannot be added to the order as the quantity must be at least 1", name)
+	}
+	ErrInsufficientStock = func(name string, available int) error {
+		return fmt.Errorf("product: %s cannot be added to the order as only %d units are available", name, available)
 	}
 )
 
@@ -43,74 +51,162 @@ func (e OrderError) Error() string {
 }
 
 type Order struct {
-	id             string
-	products       []Product
-	productToCount map[string]int
-	dispatchDate   string
-	status         OrderStatus
+	id            string
+	customerID    string
+	lines         []OrderLine
+	dispatchDate  string
+	status        OrderStatus
+	history       []StatusTransition
+	coupons       []AppliedCoupon
+	stockReleased bool
+	version       int
+	eventSequence int
+	pendingEvents []OrderEvent
 }
 
+// NewOrder creates an open order. Every change made to an order is recorded as an OrderEvent.
 func NewOrder(id string) Order {
-	return Order{
-		id:             id,
-		products:       make([]Product, 0),
-		productToCount: make(map[string]int),
-	}
+	return NewCustomerOrder(id, "")
+}
+
+// NewCustomerOrder creates an open order owned by the customer.
+func NewCustomerOrder(id, customerID string) Order {
+	order := Order{id: id}
+	order.record(OrderEvent{Type: OrderCreated, CustomerID: customerID})
+	return order
 }
 
 func (order *Order) ID() string {
 	return order.id
 }
 
-func (order *Order) Value() float64 {
-	sum := 0.0
-	orderedProducts := order.products
-	uniquePremiumProductsCounts := 0
-	seenUniqueProducts := make(map[string]bool)
+// CustomerID is the id of the customer who owns the order, which is empty for orders created by staff.
+func (order *Order) CustomerID() string {
+	return order.customerID
+}
+
+// Value is the total of the order after the discounts of the pricing engine and of its coupons.
+func (order *Order) Value(engine PricingEngine) float64 {
+	return order.Price(engine).Total
+}
+
+// Price evaluates the rules of the pricing engine followed by the coupons applied to the order.
+func (order *Order) Price(engine PricingEngine) PriceBreakdown {
+	rules := make([]PricingRule, 0, len(engine.rules)+len(order.coupons))
+	rules = append(rules, engine.rules...)
+	for _, coupon := range order.coupons {
+		rules = append(rules, coupon)
+	}
+	return NewPricingEngine(rules...).Price(order.lines)
+}
 
-	for _, product := range orderedProducts {
-		sum += product.price
-		if product.category == Premium && !seenUniqueProducts[product.id] {
-			seenUniqueProducts[product.id] = true
-			uniquePremiumProductsCounts += 1
+// ApplyCoupon adds the coupon to the order if it can be applied at the given time and does not
+// break the stacking policy of the coupons already applied.
+func (order *Order) ApplyCoupon(coupon Coupon, at time.Time) error {
+	if err := coupon.CheckApplicable(at); err != nil {
+		return err
+	}
+	for _, applied := range order.coupons {
+		if applied.Code == coupon.code {
+			return &OrderError{Err: ErrCouponAlreadyApplied(coupon.code)}
+		}
+		if !applied.Stackable {
+			return &OrderError{Err: ErrCouponNotStackable(applied.Code)}
 		}
 	}
-	if uniquePremiumProductsCounts == 3 {
-		sum *= (1 - DiscountValueIfThreeUniquePremProducts)
+	if !coupon.stackable && len(order.coupons) > 0 {
+		return &OrderError{Err: ErrCouponNotStackable(coupon.code)}
+	}
+	order.record(OrderEvent{Type: OrderCouponApplied, Coupon: &AppliedCoupon{
+		Code:         coupon.code,
+		DiscountType: coupon.discountType,
+		Value:        coupon.value,
+		Stackable:    coupon.stackable,
+	}})
+	return nil
+}
+
+func (order *Order) RemoveCoupon(code string) error {
+	code = NormalizeCouponCode(code)
+	for _, applied := range order.coupons {
+		if applied.Code == code {
+			order.record(OrderEvent{Type: OrderCouponRemoved, Coupon: &AppliedCoupon{Code: code}})
+			return nil
+		}
 	}
+	return &OrderError{Err: ErrCouponNotApplied(code)}
+}
 
-	return sum
+func (order *Order) AppliedCoupons() []AppliedCoupon {
+	return order.coupons
 }
 
-// TODO: While adding if an existing product, increase the quantity of the product in the order
-// rather than adding it to the order
+// ReleaseStock returns the lines whose quantities must be given back to the catalogue, and marks the
+// stock of the order as released. The stock of an order is released at most once, so nil is
+// returned when it has already been released.
+func (order *Order) ReleaseStock() []OrderLine {
+	if order.stockReleased {
+		return nil
+	}
+	order.record(OrderEvent{Type: OrderStockReleased})
+	return order.lines
+}
+
+func (order *Order) IsStockReleased() bool {
+	return order.stockReleased
+}
+
+// Add adds one unit of the product to the order, within the default limits.
 func (order *Order) Add(product Product) error {
+	return order.AddQuantity(product, 1, DefaultLimits())
+}
+
+// AddQuantity adds the given number of units of the product to the order. If the product is
+// already in the order, the quantity of its line is increased.
+func (order *Order) AddQuantity(product Product, quantity int, limits Limits) error {
+	if quantity < 1 {
+		return &OrderError{Err: ErrInvalidQuantity(product.name)}
+	}
 	if !product.IsAvailable() {
 		return &OrderError{Err: ErrUnavailableProduct(product.name)}
 	}
 
-	order.productToCount[product.id] += 1
-
-	if order.productToCount[product.id] > MaxUniqueProductsPerOrder {
-		order.productToCount[product.id] -= 1
-		return &OrderError{Err: ErrMaxAllowedQuantity(product.name)}
+	idx := order.lineIndex(product.id)
+	orderedQuantity := quantity
+	if idx >= 0 {
+		orderedQuantity += order.lines[idx].quantity
+	}
+	if orderedQuantity > limits.MaxQuantityPerProduct {
+		return &OrderError{Err: ErrMaxAllowedQuantity(product.name, limits.MaxQuantityPerProduct)}
+	}
+	if quantity > product.Available() {
+		return &OrderError{Err: ErrInsufficientStock(product.name, product.Available())}
 	}
 
-	order.products = append(order.products, product)
-
+	line := NewOrderLine(product, quantity)
+	order.record(OrderEvent{Type: OrderProductAdded, Line: &line})
 	return nil
 }
 
-func (order *Order) ProductQuantity() int {
-	return len(order.products)
+func (order *Order) lineIndex(productID string) int {
+	for idx, line := range order.lines {
+		if line.productID == productID {
+			return idx
+		}
+	}
+	return -1
 }
 
-func (order *Order) Products() []Product {
-	return order.products
+func (order *Order) ProductQuantity() int {
+	quantity := 0
+	for _, line := range order.lines {
+		quantity += line.quantity
+	}
+	return quantity
 }
 
-func (order *Order) ProductToCount() map[string]int {
-	return order.productToCount
+func (order *Order) Lines() []OrderLine {
+	return order.lines
 }
 
 func (order *Order) SetDispatchDate(dateString string) error {
@@ -124,7 +220,7 @@ func (order *Order) SetDispatchDate(dateString string) error {
 	if !date.After(time.Now()) {
 		return &OrderError{Err: ErrInvalidDispatchDate}
 	}
-	order.dispatchDate = dateString
+	order.record(OrderEvent{Type: OrderDispatchDateSet, DispatchDate: dateString})
 	return nil
 }
 
@@ -132,27 +228,67 @@ func (order *Order) GetDispatchDate() string {
 	return order.dispatchDate
 }
 
-func (order *Order) SetOrderStatus(status OrderStatus) {
-	order.status = status
+// SetOrderStatus moves the order to the given status, provided the transition is allowed from
+// the current status, and records the transition along with the actor who requested it.
+func (order *Order) SetOrderStatus(status OrderStatus, actor string) error {
+	if !status.IsValid() {
+		return &OrderError{Err: ErrInvalidOrderStatus}
+	}
+	if !order.status.CanTransitionTo(status) {
+		return &OrderError{Err: ErrInvalidStatusTransition(order.status, status)}
+	}
+	at := time.Now().UTC()
+	order.record(OrderEvent{Type: OrderStatusChanged, At: at, Transition: &StatusTransition{
+		From:  order.status,
+		To:    status,
+		At:    at,
+		Actor: actor,
+	}})
+	return nil
 }
 
 func (order *Order) GetOrderStatus() OrderStatus {
 	return order.status
 }
 
+func (order *Order) History() []StatusTransition {
+	return order.history
+}
+
+// Version is the number of times the order has been stored. It is 0 for an order which has never
+// been stored.
+func (order *Order) Version() int {
+	return order.version
+}
+
+// IncrementVersion records that repositories have stored the order.
+func (order *Order) IncrementVersion() {
+	order.version += 1
+}
+
 func (order *Order) MarshalJSON() ([]byte, error) {
 	data, err := json.Marshal(struct {
-		Id             string         `json:"id"`
-		Products       []Product      `json:"products"`
-		ProductToCount map[string]int `json:"product_to_count"`
-		DispatchDate   string         `json:"dispatch_date"`
-		Status         OrderStatus    `json:"status"`
+		Id            string             `json:"id"`
+		CustomerID    string             `json:"customer_id,omitempty"`
+		Lines         []OrderLine        `json:"lines"`
+		DispatchDate  string             `json:"dispatch_date"`
+		Status        OrderStatus        `json:"status"`
+		History       []StatusTransition `json:"history"`
+		Coupons       []AppliedCoupon    `json:"coupons,omitempty"`
+		StockReleased bool               `json:"stock_released,omitempty"`
+		Version       int                `json:"version"`
+		EventSequence int                `json:"event_sequence,omitempty"`
 	}{
-		Id:             order.id,
-		Products:       order.products,
-		ProductToCount: order.productToCount,
-		DispatchDate:   order.dispatchDate,
-		Status:         order.status,
+		Id:            order.id,
+		CustomerID:    order.customerID,
+		Lines:         order.lines,
+		DispatchDate:  order.dispatchDate,
+		Status:        order.status,
+		History:       order.history,
+		Coupons:       order.coupons,
+		StockReleased: order.stockReleased,
+		Version:       order.version,
+		EventSequence: order.eventSequence,
 	})
 	if err != nil {
 		return nil, err
@@ -162,20 +298,50 @@ func (order *Order) MarshalJSON() ([]byte, error) {
 
 func (order *Order) UnmarshalJSON(data []byte) error {
 	type ord struct {
-		Id             string         `json:"id"`
-		Products       []Product      `json:"products"`
-		ProductToCount map[string]int `json:"product_to_count"`
-		DispatchDate   string         `json:"dispatch_date"`
-		Status         OrderStatus    `json:"status"`
+		Id            string             `json:"id"`
+		CustomerID    string             `json:"customer_id"`
+		Lines         []OrderLine        `json:"lines"`
+		Products      []Product          `json:"products"`
+		DispatchDate  string             `json:"dispatch_date"`
+		Status        OrderStatus        `json:"status"`
+		History       []StatusTransition `json:"history"`
+		Coupons       []AppliedCoupon    `json:"coupons"`
+		StockReleased bool               `json:"stock_released"`
+		Version       int                `json:"version"`
+		EventSequence int                `json:"event_sequence"`
 	}
 	o := &ord{}
 	if err := json.Unmarshal(data, o); err != nil {
 		return err
 	}
 	order.id = o.Id
+	order.customerID = o.CustomerID
 	order.dispatchDate = o.DispatchDate
-	order.productToCount = o.ProductToCount
-	order.products = o.Products
+	order.lines = o.Lines
 	order.status = o.Status
+	order.history = o.History
+	order.coupons = o.Coupons
+	order.stockReleased = o.StockReleased
+	order.version = o.Version
+	order.eventSequence = o.EventSequence
+	if order.lines == nil {
+		order.lines = linesFromLegacyProducts(o.Products)
+	}
 	return nil
 }
+
+// linesFromLegacyProducts folds orders stored before order lines were introduced, which held a copy
+// of the product for every ordered unit, into one line per product.
+func linesFromLegacyProducts(products []Product) []OrderLine {
+	lines := make([]OrderLine, 0)
+	lineIndex := make(map[string]int)
+	for _, product := range products {
+		if idx, ok := lineIndex[product.id]; ok {
+			lines[idx].quantity += 1
+			continue
+		}
+		lineIndex[product.id] = len(lines)
+		lines = append(lines, NewOrderLine(product, 1))
+	}
+	return lines
+}
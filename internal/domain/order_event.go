@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type OrderEventType string
+
+const (
+	OrderCreated         OrderEventType = "order_created"
+	OrderProductAdded    OrderEventType = "product_added"
+	OrderStatusChanged   OrderEventType = "status_changed"
+	OrderDispatchDateSet OrderEventType = "dispatch_date_set"
+	OrderCouponApplied   OrderEventType = "coupon_applied"
+	OrderCouponRemoved   OrderEventType = "coupon_removed"
+	OrderStockReleased   OrderEventType = "stock_released"
+	// OrderImported holds a snapshot of an order stored before orders had an event log.
+	OrderImported OrderEventType = "order_imported"
+)
+
+// OrderEvent is an immutable record of a change made to an order. Replaying the events of an order
+// in sequence rebuilds the order. Only the fields which describe the given type of event are set.
+type OrderEvent struct {
+	OrderID  string         `json:"order_id"`
+	Sequence int            `json:"sequence"`
+	Version  int            `json:"version"`
+	Type     OrderEventType `json:"type"`
+	At       time.Time      `json:"at"`
+
+	CustomerID   string            `json:"customer_id,omitempty"`
+	Line         *OrderLine        `json:"line,omitempty"`
+	Transition   *StatusTransition `json:"transition,omitempty"`
+	DispatchDate string            `json:"dispatch_date,omitempty"`
+	Coupon       *AppliedCoupon    `json:"coupon,omitempty"`
+	Snapshot     json.RawMessage   `json:"snapshot,omitempty"`
+}
+
+// ReplayOrder rebuilds an order from its events, which must be given in sequence. The order is left
+// with the version it had once the last of the events was stored.
+func ReplayOrder(events []OrderEvent) (Order, error) {
+	order := Order{lines: make([]OrderLine, 0)}
+	for _, event := range events {
+		if event.Type == OrderImported {
+			if err := order.UnmarshalJSON(event.Snapshot); err != nil {
+				return Order{}, fmt.Errorf("could not read the snapshot of order #%s: %w", event.OrderID, err)
+			}
+		} else {
+			order.apply(event)
+		}
+		order.eventSequence = event.Sequence
+		order.version = event.Version
+	}
+	return order, nil
+}
+
+// EventsUntil returns the events which happened at or before the given time.
+func EventsUntil(events []OrderEvent, at time.Time) []OrderEvent {
+	until := make([]OrderEvent, 0, len(events))
+	for _, event := range events {
+		if event.At.After(at) {
+			break
+		}
+		until = append(until, event)
+	}
+	return until
+}
+
+// record applies a new event to the order and keeps it until the order is stored.
+func (order *Order) record(event OrderEvent) {
+	event.OrderID = order.id
+	event.Sequence = order.eventSequence + 1
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+	order.apply(event)
+	order.eventSequence = event.Sequence
+	order.pendingEvents = append(order.pendingEvents, event)
+}
+
+// apply changes the order as the event describes. Imported snapshots are only read by ReplayOrder,
+// since reading them may fail, and recording one leaves the order as it is.
+func (order *Order) apply(event OrderEvent) {
+	switch event.Type {
+	case OrderCreated:
+		order.id = event.OrderID
+		order.customerID = event.CustomerID
+		order.lines = make([]OrderLine, 0)
+		order.status = OrderOpen
+	case OrderProductAdded:
+		if idx := order.lineIndex(event.Line.productID); idx >= 0 {
+			order.lines[idx].quantity += event.Line.quantity
+			return
+		}
+		order.lines = append(order.lines, *event.Line)
+	case OrderStatusChanged:
+		order.history = append(order.history, *event.Transition)
+		order.status = event.Transition.To
+	case OrderDispatchDateSet:
+		order.dispatchDate = event.DispatchDate
+	case OrderCouponApplied:
+		order.coupons = append(order.coupons, *event.Coupon)
+	case OrderCouponRemoved:
+		for idx, applied := range order.coupons {
+			if applied.Code == event.Coupon.Code {
+				order.coupons = append(order.coupons[:idx:idx], order.coupons[idx+1:]...)
+				return
+			}
+		}
+	case OrderStockReleased:
+		order.stockReleased = true
+	}
+}
+
+// PendingEvents returns the events recorded since the order was read, which have to be appended to
+// the event log when the order is stored.
+func (order *Order) PendingEvents() []OrderEvent {
+	return order.pendingEvents
+}
+
+// RecordImport records a snapshot of the current state of the order, for orders which were stored
+// before orders had an event log.
+func (order *Order) RecordImport() error {
+	snapshot, err := order.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	order.record(OrderEvent{Type: OrderImported, Snapshot: snapshot})
+	return nil
+}
@@ -0,0 +1,88 @@
+package domain
+
+import "encoding/json"
+
+// OrderLine is a single product in an order along with the ordered quantity. The name, price and
+// category of the product are snapshotted when the product is first added to the order, so that
+// later changes to the catalogue do not change the value of existing orders.
+type OrderLine struct {
+	productID string
+	name      string
+	price     float64
+	category  ProductCategory
+	quantity  int
+}
+
+func NewOrderLine(product Product, quantity int) OrderLine {
+	return OrderLine{
+		productID: product.id,
+		name:      product.name,
+		price:     product.price,
+		category:  product.category,
+		quantity:  quantity,
+	}
+}
+
+func (line *OrderLine) ProductID() string {
+	return line.productID
+}
+
+func (line *OrderLine) Name() string {
+	return line.name
+}
+
+func (line *OrderLine) Price() float64 {
+	return line.price
+}
+
+func (line *OrderLine) Category() ProductCategory {
+	return line.category
+}
+
+func (line *OrderLine) Quantity() int {
+	return line.quantity
+}
+
+func (line *OrderLine) Total() float64 {
+	return line.price * float64(line.quantity)
+}
+
+func (line *OrderLine) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		ProductID string          `json:"product_id"`
+		Name      string          `json:"name"`
+		Price     float64         `json:"price"`
+		Category  ProductCategory `json:"category"`
+		Quantity  int             `json:"quantity"`
+	}{
+		ProductID: line.productID,
+		Name:      line.name,
+		Price:     line.price,
+		Category:  line.category,
+		Quantity:  line.quantity,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (line *OrderLine) UnmarshalJSON(data []byte) error {
+	type ln struct {
+		ProductID string          `json:"product_id"`
+		Name      string          `json:"name"`
+		Price     float64         `json:"price"`
+		Category  ProductCategory `json:"category"`
+		Quantity  int             `json:"quantity"`
+	}
+	l := &ln{}
+	if err := json.Unmarshal(data, l); err != nil {
+		return err
+	}
+	line.productID = l.ProductID
+	line.name = l.Name
+	line.price = l.Price
+	line.category = l.Category
+	line.quantity = l.Quantity
+	return nil
+}
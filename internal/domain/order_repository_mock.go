@@ -4,6 +4,7 @@
 package domain
 
 import (
+	"context"
 	"sync"
 )
 
@@ -17,13 +18,19 @@ var _ OrderRepository = &OrderRepositoryMock{}
 //
 //		// make and configure a mocked OrderRepository
 //		mockedOrderRepository := &OrderRepositoryMock{
-//			FindByIdFunc: func(id string) Order {
+//			EventsFunc: func(ctx context.Context, orderID string) ([]OrderEvent, error) {
+//				panic("mock out the Events method")
+//			},
+//			FindFunc: func(ctx context.Context, query OrderQuery) ([]Order, string, error) {
+//				panic("mock out the Find method")
+//			},
+//			FindByIdFunc: func(ctx context.Context, id string) Order {
 //				panic("mock out the FindById method")
 //			},
-//			GetAllFunc: func() []Order {
+//			GetAllFunc: func(ctx context.Context) []Order {
 //				panic("mock out the GetAll method")
 //			},
-//			StoreFunc: func(order Order) error {
+//			StoreFunc: func(ctx context.Context, order Order) error {
 //				panic("mock out the Store method")
 //			},
 //		}
@@ -33,50 +40,152 @@ var _ OrderRepository = &OrderRepositoryMock{}
 //
 //	}
 type OrderRepositoryMock struct {
+	// EventsFunc mocks the Events method.
+	EventsFunc func(ctx context.Context, orderID string) ([]OrderEvent, error)
+
+	// FindFunc mocks the Find method.
+	FindFunc func(ctx context.Context, query OrderQuery) ([]Order, string, error)
+
 	// FindByIdFunc mocks the FindById method.
-	FindByIdFunc func(id string) Order
+	FindByIdFunc func(ctx context.Context, id string) Order
 
 	// GetAllFunc mocks the GetAll method.
-	GetAllFunc func() []Order
+	GetAllFunc func(ctx context.Context) []Order
 
 	// StoreFunc mocks the Store method.
-	StoreFunc func(order Order) error
+	StoreFunc func(ctx context.Context, order Order) error
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// Events holds details about calls to the Events method.
+		Events []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// OrderID is the orderID argument value.
+			OrderID string
+		}
+		// Find holds details about calls to the Find method.
+		Find []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Query is the query argument value.
+			Query OrderQuery
+		}
 		// FindById holds details about calls to the FindById method.
 		FindById []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the id argument value.
 			ID string
 		}
 		// GetAll holds details about calls to the GetAll method.
 		GetAll []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 		}
 		// Store holds details about calls to the Store method.
 		Store []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// Order is the order argument value.
 			Order Order
 		}
 	}
+	lockEvents   sync.RWMutex
+	lockFind     sync.RWMutex
 	lockFindById sync.RWMutex
 	lockGetAll   sync.RWMutex
 	lockStore    sync.RWMutex
 }
 
+// Events calls EventsFunc.
+func (mock *OrderRepositoryMock) Events(ctx context.Context, orderID string) ([]OrderEvent, error) {
+	if mock.EventsFunc == nil {
+		panic("OrderRepositoryMock.EventsFunc: method is nil but OrderRepository.Events was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		OrderID string
+	}{
+		Ctx:     ctx,
+		OrderID: orderID,
+	}
+	mock.lockEvents.Lock()
+	mock.calls.Events = append(mock.calls.Events, callInfo)
+	mock.lockEvents.Unlock()
+	return mock.EventsFunc(ctx, orderID)
+}
+
+// EventsCalls gets all the calls that were made to Events.
+// Check the length with:
+//
+//	len(mockedOrderRepository.EventsCalls())
+func (mock *OrderRepositoryMock) EventsCalls() []struct {
+	Ctx     context.Context
+	OrderID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		OrderID string
+	}
+	mock.lockEvents.RLock()
+	calls = mock.calls.Events
+	mock.lockEvents.RUnlock()
+	return calls
+}
+
+// Find calls FindFunc.
+func (mock *OrderRepositoryMock) Find(ctx context.Context, query OrderQuery) ([]Order, string, error) {
+	if mock.FindFunc == nil {
+		panic("OrderRepositoryMock.FindFunc: method is nil but OrderRepository.Find was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Query OrderQuery
+	}{
+		Ctx:   ctx,
+		Query: query,
+	}
+	mock.lockFind.Lock()
+	mock.calls.Find = append(mock.calls.Find, callInfo)
+	mock.lockFind.Unlock()
+	return mock.FindFunc(ctx, query)
+}
+
+// FindCalls gets all the calls that were made to Find.
+// Check the length with:
+//
+//	len(mockedOrderRepository.FindCalls())
+func (mock *OrderRepositoryMock) FindCalls() []struct {
+	Ctx   context.Context
+	Query OrderQuery
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Query OrderQuery
+	}
+	mock.lockFind.RLock()
+	calls = mock.calls.Find
+	mock.lockFind.RUnlock()
+	return calls
+}
+
 // FindById calls FindByIdFunc.
-func (mock *OrderRepositoryMock) FindById(id string) Order {
+func (mock *OrderRepositoryMock) FindById(ctx context.Context, id string) Order {
 	if mock.FindByIdFunc == nil {
 		panic("OrderRepositoryMock.FindByIdFunc: method is nil but OrderRepository.FindById was just called")
 	}
 	callInfo := struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}{
-		ID: id,
+		Ctx: ctx,
+		ID:  id,
 	}
 	mock.lockFindById.Lock()
 	mock.calls.FindById = append(mock.calls.FindById, callInfo)
 	mock.lockFindById.Unlock()
-	return mock.FindByIdFunc(id)
+	return mock.FindByIdFunc(ctx, id)
 }
 
 // FindByIdCalls gets all the calls that were made to FindById.
@@ -84,10 +193,12 @@ func (mock *OrderRepositoryMock) FindById(id string) Order {
 //
 //	len(mockedOrderRepository.FindByIdCalls())
 func (mock *OrderRepositoryMock) FindByIdCalls() []struct {
-	ID string
+	Ctx context.Context
+	ID  string
 } {
 	var calls []struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}
 	mock.lockFindById.RLock()
 	calls = mock.calls.FindById
@@ -96,16 +207,19 @@ func (mock *OrderRepositoryMock) FindByIdCalls() []struct {
 }
 
 // GetAll calls GetAllFunc.
-func (mock *OrderRepositoryMock) GetAll() []Order {
+func (mock *OrderRepositoryMock) GetAll(ctx context.Context) []Order {
 	if mock.GetAllFunc == nil {
 		panic("OrderRepositoryMock.GetAllFunc: method is nil but OrderRepository.GetAll was just called")
 	}
 	callInfo := struct {
-	}{}
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
 	mock.lockGetAll.Lock()
 	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
 	mock.lockGetAll.Unlock()
-	return mock.GetAllFunc()
+	return mock.GetAllFunc(ctx)
 }
 
 // GetAllCalls gets all the calls that were made to GetAll.
@@ -113,8 +227,10 @@ func (mock *OrderRepositoryMock) GetAll() []Order {
 //
 //	len(mockedOrderRepository.GetAllCalls())
 func (mock *OrderRepositoryMock) GetAllCalls() []struct {
+	Ctx context.Context
 } {
 	var calls []struct {
+		Ctx context.Context
 	}
 	mock.lockGetAll.RLock()
 	calls = mock.calls.GetAll
@@ -123,19 +239,21 @@ func (mock *OrderRepositoryMock) GetAllCalls() []struct {
 }
 
 // Store calls StoreFunc.
-func (mock *OrderRepositoryMock) Store(order Order) error {
+func (mock *OrderRepositoryMock) Store(ctx context.Context, order Order) error {
 	if mock.StoreFunc == nil {
 		panic("OrderRepositoryMock.StoreFunc: method is nil but OrderRepository.Store was just called")
 	}
 	callInfo := struct {
+		Ctx   context.Context
 		Order Order
 	}{
+		Ctx:   ctx,
 		Order: order,
 	}
 	mock.lockStore.Lock()
 	mock.calls.Store = append(mock.calls.Store, callInfo)
 	mock.lockStore.Unlock()
-	return mock.StoreFunc(order)
+	return mock.StoreFunc(ctx, order)
 }
 
 // StoreCalls gets all the calls that were made to Store.
@@ -143,9 +261,11 @@ func (mock *OrderRepositoryMock) Store(order Order) error {
 //
 //	len(mockedOrderRepository.StoreCalls())
 func (mock *OrderRepositoryMock) StoreCalls() []struct {
+	Ctx   context.Context
 	Order Order
 } {
 	var calls []struct {
+		Ctx   context.Context
 		Order Order
 	}
 	mock.lockStore.RLock()
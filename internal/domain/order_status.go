@@ -1,10 +1,54 @@
 package domain
 
+import "time"
+
 type OrderStatus string
 
 const (
+	OrderOpen       OrderStatus = "open"
 	OrderPlaced     OrderStatus = "placed"
 	OrderDispatched OrderStatus = "dispatched"
 	OrderCompleted  OrderStatus = "completed"
 	OrderCancelled  OrderStatus = "cancelled"
 )
+
+// orderStatusTransitions lists, for every status, the statuses an order is allowed to move to.
+// An open order is a cart: products can be added to it, and their stock is only reserved until the
+// order is placed.
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderOpen:       {OrderPlaced, OrderCancelled},
+	OrderPlaced:     {OrderDispatched, OrderCancelled},
+	OrderDispatched: {OrderCompleted},
+	OrderCompleted:  {},
+	OrderCancelled:  {},
+}
+
+// OrderStatuses returns every status, in the order in which an order goes through them.
+func OrderStatuses() []OrderStatus {
+	return []OrderStatus{OrderOpen, OrderPlaced, OrderDispatched, OrderCompleted, OrderCancelled}
+}
+
+func (status OrderStatus) IsValid() bool {
+	_, ok := orderStatusTransitions[status]
+	return ok
+}
+
+func (status OrderStatus) IsTerminal() bool {
+	return len(orderStatusTransitions[status]) == 0
+}
+
+func (status OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderStatusTransitions[status] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type StatusTransition struct {
+	From  OrderStatus `json:"from"`
+	To    OrderStatus `json:"to"`
+	At    time.Time   `json:"at"`
+	Actor string      `json:"actor"`
+}
@@ -7,15 +7,14 @@ import (
 	"time"
 )
 
+var defaultEngine = domain.DefaultPricingEngine(domain.DefaultLimits())
+
 func TestCreateOrderWithProducts(t *testing.T) {
 	orderID := "123"
 
 	order := domain.NewOrder(orderID)
-	if len(order.Products()) != 0 {
-		t.Error("products in the order must be empty when a new order is created")
-	}
-	if order.ProductToCount() == nil {
-		t.Error("the map of products in the order to the count must be initialized")
+	if len(order.Lines()) != 0 {
+		t.Error("lines in the order must be empty when a new order is created")
 	}
 
 	product1 := domain.NewProduct("1", "nike shoes", 11.0, 3, domain.Premium)
@@ -25,13 +24,13 @@ func TestCreateOrderWithProducts(t *testing.T) {
 	order.Add(product2)
 
 	expectedOrderValue := product1.Price() + product2.Price()
-	actualOrderValue := order.Value()
+	actualOrderValue := order.Value(defaultEngine)
 
 	if actualOrderValue != expectedOrderValue {
 		t.Errorf("Expected order value: %v. Got order value: %v", expectedOrderValue, actualOrderValue)
 	}
 
-	actualProduct1Count := order.ProductToCount()["1"]
+	actualProduct1Count := order.Lines()[0].Quantity()
 	expectedProduct1Count := 1
 
 	if actualProduct1Count != expectedProduct1Count {
@@ -39,9 +38,95 @@ func TestCreateOrderWithProducts(t *testing.T) {
 	}
 
 	expectedTotalOrderedProducts := 2
-	actualTotalOrderedProducts := len(order.Products())
+	actualTotalOrderedProducts := order.ProductQuantity()
 	if expectedTotalOrderedProducts != actualTotalOrderedProducts {
-		t.Errorf("Expected total products: %d. Got total products: %d", expectedProduct1Count, actualProduct1Count)
+		t.Errorf("Expected total products: %d. Got total products: %d", expectedTotalOrderedProducts, actualTotalOrderedProducts)
+	}
+}
+
+func TestAddProductToOrder_SameProductIncreasesLineQuantity(t *testing.T) {
+	order := domain.NewOrder("123")
+	product := domain.NewProduct("1", "nike shoes", 11.0, 5, domain.Premium)
+
+	order.Add(product)
+	if err := order.AddQuantity(product, 3, domain.DefaultLimits()); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	if len(order.Lines()) != 1 {
+		t.Fatalf("Got: %v lines, Want: %v", len(order.Lines()), 1)
+	}
+	line := order.Lines()[0]
+	if line.Quantity() != 4 {
+		t.Errorf("Got: %v, Want: %v", line.Quantity(), 4)
+	}
+	if line.Total() != 44.0 {
+		t.Errorf("Got: %v, Want: %v", line.Total(), 44.0)
+	}
+}
+
+func TestAddProductToOrder_InsufficientStock(t *testing.T) {
+	order := domain.NewOrder("123")
+	product := domain.NewProduct("1", "nike shoes", 11.0, 2, domain.Premium)
+
+	got := order.AddQuantity(product, 3, domain.DefaultLimits())
+	want := domain.OrderError{Err: domain.ErrInsufficientStock(product.Name(), 2)}
+	if got == nil || got.Error() != want.Error() {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+	if len(order.Lines()) != 0 {
+		t.Error("the order must not change when the product cannot be added")
+	}
+}
+
+func TestAddProductToOrder_QuantityAboveTheLimits(t *testing.T) {
+	order := domain.NewOrder("123")
+	product := domain.NewProduct("1", "nike shoes", 11.0, 5, domain.Premium)
+	limits := domain.DefaultLimits()
+	limits.MaxQuantityPerProduct = 2
+
+	got := order.AddQuantity(product, 3, limits)
+	want := domain.OrderError{Err: domain.ErrMaxAllowedQuantity(product.Name(), 2)}
+	if got == nil || got.Error() != want.Error() {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestAddProductToOrder_InvalidQuantity(t *testing.T) {
+	order := domain.NewOrder("123")
+	product := domain.NewProduct("1", "nike shoes", 11.0, 2, domain.Premium)
+
+	got := order.AddQuantity(product, 0, domain.DefaultLimits())
+	want := domain.OrderError{Err: domain.ErrInvalidQuantity(product.Name())}
+	if got == nil || got.Error() != want.Error() {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestUnmarshalLegacyOrderWithDuplicatedProducts(t *testing.T) {
+	data := []byte(`{"id":"123","products":[` +
+		`{"Id":"1","Name":"nike shoes","Price":10,"Sku":3,"Category":"premium"},` +
+		`{"Id":"2","Name":"tie","Price":5,"Sku":3,"Category":"budget"},` +
+		`{"Id":"1","Name":"nike shoes","Price":10,"Sku":3,"Category":"premium"}],` +
+		`"product_to_count":{"1":2,"2":1},"status":"placed"}`)
+
+	order := &domain.Order{}
+	if err := order.UnmarshalJSON(data); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	lines := order.Lines()
+	if len(lines) != 2 {
+		t.Fatalf("Got: %v lines, Want: %v", len(lines), 2)
+	}
+	if lines[0].ProductID() != "1" || lines[0].Quantity() != 2 {
+		t.Errorf("Got: %v x %v, Want: %v x %v", lines[0].ProductID(), lines[0].Quantity(), "1", 2)
+	}
+	if lines[1].ProductID() != "2" || lines[1].Quantity() != 1 {
+		t.Errorf("Got: %v x %v, Want: %v x %v", lines[1].ProductID(), lines[1].Quantity(), "2", 1)
+	}
+	if order.Value(defaultEngine) != 25.0 {
+		t.Errorf("Got: %v, Want: %v", order.Value(defaultEngine), 25.0)
 	}
 }
 
@@ -60,8 +145,8 @@ func TestCreateOrderWithThreeUniqueProducts(t *testing.T) {
 
 	totalProductPrice := (product1.Price() + product2.Price() + product3.Price())
 
-	expectedOrderValue := totalProductPrice * float64(1-domain.DiscountValueIfThreeUniquePremProducts)
-	actualOrderValue := order.Value()
+	expectedOrderValue := totalProductPrice * float64(1-domain.DefaultPremiumBundleDiscount)
+	actualOrderValue := order.Value(defaultEngine)
 
 	if actualOrderValue != expectedOrderValue {
 		t.Errorf("Expected order value: %v. Got order value: %v", expectedOrderValue, actualOrderValue)
@@ -87,7 +172,8 @@ func TestCreateOrderWithDispatchDate_InvalidDateFormat(t *testing.T) {
 	order := domain.NewOrder(orderID)
 	product1 := domain.NewProduct("1", "nike shoes", 100.0, 3, domain.Premium)
 	order.Add(product1)
-	order.SetOrderStatus(domain.OrderDispatched)
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+	order.SetOrderStatus(domain.OrderDispatched, "tester")
 
 	got := order.SetDispatchDate("2023-31-12")
 	want := domain.OrderError{Err: domain.ErrInvalidDispatchDateFormat}
@@ -101,7 +187,8 @@ func TestCreateOrderWithDispatchDate_InvalidDispatchDate(t *testing.T) {
 	order := domain.NewOrder(orderID)
 	product1 := domain.NewProduct("1", "nike shoes", 100.0, 3, domain.Premium)
 	order.Add(product1)
-	order.SetOrderStatus(domain.OrderDispatched)
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+	order.SetOrderStatus(domain.OrderDispatched, "tester")
 
 	got := order.SetDispatchDate(time.Now().Add(-time.Duration(time.Now().Day())).Format(time.DateOnly))
 	want := domain.OrderError{Err: domain.ErrInvalidDispatchDate}
@@ -115,7 +202,8 @@ func TestCreateOrderWithDispatchDate_Success(t *testing.T) {
 	order := domain.NewOrder(orderID)
 	product1 := domain.NewProduct("1", "nike shoes", 100.0, 3, domain.Premium)
 	order.Add(product1)
-	order.SetOrderStatus(domain.OrderDispatched)
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+	order.SetOrderStatus(domain.OrderDispatched, "tester")
 
 	got := order.SetDispatchDate(time.Now().Add(48 * time.Hour).Format(time.DateOnly))
 	var want error = nil
@@ -149,7 +237,7 @@ func TestAddProductToOrder_MaxAllowedQuantityError(t *testing.T) {
 		order.Add(product1)
 	}
 	got := order.Add(product1)
-	want := domain.OrderError{Err: domain.ErrMaxAllowedQuantity(product1.Name())}
+	want := domain.OrderError{Err: domain.ErrMaxAllowedQuantity(product1.Name(), domain.DefaultMaxQuantityPerProduct)}
 	var orderErr *domain.OrderError
 
 	if !errors.As(got, &orderErr) {
@@ -160,3 +248,149 @@ func TestAddProductToOrder_MaxAllowedQuantityError(t *testing.T) {
 		t.Errorf("Got: %v, Want: %v", got, want)
 	}
 }
+
+func TestUpdateOrderStatus_ValidTransitions(t *testing.T) {
+	order := domain.NewOrder("123")
+
+	for _, status := range []domain.OrderStatus{domain.OrderPlaced, domain.OrderDispatched, domain.OrderCompleted} {
+		if err := order.SetOrderStatus(status, "tester"); err != nil {
+			t.Fatalf("Got: %v, Want: %v", err, nil)
+		}
+	}
+
+	got := order.GetOrderStatus()
+	want := domain.OrderCompleted
+	if got != want {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestUpdateOrderStatus_InvalidTransition(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+	order.SetOrderStatus(domain.OrderDispatched, "tester")
+	order.SetOrderStatus(domain.OrderCompleted, "tester")
+
+	got := order.SetOrderStatus(domain.OrderPlaced, "tester")
+	want := domain.OrderError{Err: domain.ErrInvalidStatusTransition(domain.OrderCompleted, domain.OrderPlaced)}
+	var orderErr *domain.OrderError
+
+	if !errors.As(got, &orderErr) {
+		t.Errorf("Got: %v, Want: %v", got, orderErr)
+	}
+
+	if got.Error() != want.Error() {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+
+	if order.GetOrderStatus() != domain.OrderCompleted {
+		t.Errorf("Got: %v, Want: %v", order.GetOrderStatus(), domain.OrderCompleted)
+	}
+}
+
+func TestUpdateOrderStatus_InvalidStatus(t *testing.T) {
+	order := domain.NewOrder("123")
+
+	got := order.SetOrderStatus(domain.OrderStatus("shipped"), "tester")
+	want := domain.OrderError{Err: domain.ErrInvalidOrderStatus}
+
+	if got == nil || got.Error() != want.Error() {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestUpdateOrderStatus_RecordsHistory(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.SetOrderStatus(domain.OrderPlaced, "customer")
+	order.SetOrderStatus(domain.OrderCancelled, "operator")
+
+	history := order.History()
+	if len(history) != 2 {
+		t.Fatalf("Got: %v, Want: %v", len(history), 2)
+	}
+
+	got := history[1]
+	if got.From != domain.OrderPlaced || got.To != domain.OrderCancelled || got.Actor != "operator" {
+		t.Errorf("Got: %+v, Want transition from %v to %v by %v", got, domain.OrderPlaced, domain.OrderCancelled, "operator")
+	}
+	if got.At.IsZero() {
+		t.Error("the time of the transition must be recorded")
+	}
+}
+
+func TestReleaseStock_OnlyOnce(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.AddQuantity(domain.NewProduct("1", "nike shoes", 100.0, 5, domain.Premium), 2, domain.DefaultLimits())
+
+	lines := order.ReleaseStock()
+	if len(lines) != 1 || lines[0].Quantity() != 2 {
+		t.Fatalf("Got: %v, Want: one line with a quantity of %v", lines, 2)
+	}
+	if !order.IsStockReleased() {
+		t.Error("the stock of the order must be marked as released")
+	}
+	if got := order.ReleaseStock(); got != nil {
+		t.Errorf("Got: %v, Want: %v", got, nil)
+	}
+}
+
+func TestReplayOrder_RebuildsOrderFromEvents(t *testing.T) {
+	now := time.Now()
+	order := domain.NewOrder("123")
+	order.AddQuantity(domain.NewProduct("1", "nike shoes", 100.0, 5, domain.Premium), 2, domain.DefaultLimits())
+	order.AddQuantity(domain.NewProduct("1", "nike shoes", 100.0, 5, domain.Premium), 1, domain.DefaultLimits())
+	order.ApplyCoupon(domain.NewCoupon("save10", domain.PercentDiscount, 0.1, now.Add(-time.Hour), now.Add(time.Hour), 0, true), now)
+	order.SetOrderStatus(domain.OrderPlaced, "customer")
+
+	events := order.PendingEvents()
+	if len(events) != 5 || events[4].Sequence != 5 {
+		t.Fatalf("Got: %v events, Want: %v events in sequence", len(events), 5)
+	}
+
+	got, err := domain.ReplayOrder(events)
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if got.ID() != "123" || got.ProductQuantity() != 3 || got.GetOrderStatus() != domain.OrderPlaced {
+		t.Errorf("Got: #%v with %v units %v, Want: #%v with %v units %v",
+			got.ID(), got.ProductQuantity(), got.GetOrderStatus(), "123", 3, domain.OrderPlaced)
+	}
+	if got.Value(defaultEngine) != order.Value(defaultEngine) || len(got.History()) != 1 {
+		t.Errorf("Got: %v value and %v transitions, Want: %v value and %v transitions", got.Value(defaultEngine), len(got.History()), order.Value(defaultEngine), 1)
+	}
+}
+
+func TestReplayOrder_ReadsImportedSnapshots(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.AddQuantity(domain.NewProduct("1", "nike shoes", 100.0, 5, domain.Premium), 2, domain.DefaultLimits())
+	snapshot, err := order.MarshalJSON()
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	events := []domain.OrderEvent{{OrderID: "123", Sequence: 1, Type: domain.OrderImported, Snapshot: snapshot}}
+
+	got, err := domain.ReplayOrder(events)
+	if err != nil || got.ID() != "123" || got.ProductQuantity() != 2 {
+		t.Errorf("Got: #%v with %v units (%v), Want: #%v with %v units", got.ID(), got.ProductQuantity(), err, "123", 2)
+	}
+
+	events[0].Snapshot = []byte(`{"id": "123", "lines": `)
+	if _, err := domain.ReplayOrder(events); err == nil {
+		t.Errorf("Got: %v, Want: an error", err)
+	}
+}
+
+func TestEventsUntil_ReplaysOrderAsOfTime(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.AddQuantity(domain.NewProduct("1", "nike shoes", 100.0, 5, domain.Premium), 2, domain.DefaultLimits())
+	events := order.PendingEvents()
+	events[1].At = events[0].At.Add(time.Minute)
+
+	got, err := domain.ReplayOrder(domain.EventsUntil(events, events[0].At))
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if got.ProductQuantity() != 0 || got.GetOrderStatus() != domain.OrderOpen {
+		t.Errorf("Got: %v units %v, Want: %v units %v", got.ProductQuantity(), got.GetOrderStatus(), 0, domain.OrderOpen)
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate moq -out outbox_repository_mock.go . OutboxRepository
+
+// OutboxRepository holds the order events waiting to be delivered to webhooks. Events are appended
+// to the outbox by the order repository in the transaction which stores the order, so an event is
+// published if and only if the change it describes has been stored.
+type OutboxRepository interface {
+	Pending(ctx context.Context, limit int) []OutboxMessage
+	Delete(ctx context.Context, id string) error
+	StoreDelivery(ctx context.Context, delivery WebhookDelivery) error
+	DueDeliveries(ctx context.Context, at time.Time, limit int) []WebhookDelivery
+	DeleteDelivery(ctx context.Context, id string) error
+	StoreDeadLetter(ctx context.Context, delivery WebhookDelivery) error
+	DeadLetters(ctx context.Context, webhookID string) []WebhookDelivery
+}
+
+// OutboxMessage is an order event which has not been handed to the webhooks subscribed to it yet.
+type OutboxMessage struct {
+	ID    string     `json:"id"`
+	Event OrderEvent `json:"event"`
+}
+
+// WebhookDelivery is the delivery of an outbox message to one webhook. A failed delivery is retried
+// with an exponential backoff, and is moved to the dead letters once it has failed too many times.
+type WebhookDelivery struct {
+	ID            string     `json:"id"`
+	WebhookID     string     `json:"webhook_id"`
+	MessageID     string     `json:"message_id"`
+	Event         OrderEvent `json:"event"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+func NewWebhookDelivery(webhookID string, message OutboxMessage, at time.Time) WebhookDelivery {
+	return WebhookDelivery{
+		ID:            message.ID + "-" + webhookID,
+		WebhookID:     webhookID,
+		MessageID:     message.ID,
+		Event:         message.Event,
+		NextAttemptAt: at,
+	}
+}
+
+// Fail records a failed attempt and schedules the next one after baseBackoff, doubled for every
+// previous attempt and capped at maxBackoff.
+func (delivery *WebhookDelivery) Fail(reason string, at time.Time, baseBackoff, maxBackoff time.Duration) {
+	delivery.Attempts += 1
+	delivery.LastError = reason
+	backoff := baseBackoff
+	for i := 1; i < delivery.Attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	delivery.NextAttemptAt = at.Add(backoff)
+}
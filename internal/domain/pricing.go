@@ -0,0 +1,69 @@
+package domain
+
+// PricingRule computes the discount it grants on an order. Rules are evaluated in order by a
+// PricingEngine and every rule is given the total left after the rules evaluated before it.
+type PricingRule interface {
+	Name() string
+	Discount(lines []OrderLine, total float64) float64
+}
+
+type PriceAdjustment struct {
+	Rule   string  `json:"rule"`
+	Amount float64 `json:"amount"`
+}
+
+type PriceBreakdown struct {
+	Subtotal    float64           `json:"subtotal"`
+	Adjustments []PriceAdjustment `json:"adjustments"`
+	Total       float64           `json:"total"`
+}
+
+type PricingEngine struct {
+	rules []PricingRule
+}
+
+func NewPricingEngine(rules ...PricingRule) PricingEngine {
+	return PricingEngine{rules: rules}
+}
+
+// DefaultPricingEngine only applies the premium bundle discount of the limits.
+func DefaultPricingEngine(limits Limits) PricingEngine {
+	return NewPricingEngine(PremiumBundleRule{
+		MinUniqueProducts: limits.PremiumBundleMinProducts,
+		Rate:              limits.PremiumBundleDiscount,
+	})
+}
+
+func (engine PricingEngine) Rules() []PricingRule {
+	return engine.rules
+}
+
+// Price evaluates every rule against the lines of the order. Only the rules which grant a discount
+// are reported in the breakdown, and a discount never takes the total below zero.
+func (engine PricingEngine) Price(lines []OrderLine) PriceBreakdown {
+	subtotal := 0.0
+	for _, line := range lines {
+		subtotal += line.Total()
+	}
+
+	breakdown := PriceBreakdown{
+		Subtotal:    subtotal,
+		Adjustments: make([]PriceAdjustment, 0),
+		Total:       subtotal,
+	}
+	for _, rule := range engine.rules {
+		discount := rule.Discount(lines, breakdown.Total)
+		if discount <= 0 {
+			continue
+		}
+		if discount > breakdown.Total {
+			discount = breakdown.Total
+		}
+		breakdown.Total -= discount
+		breakdown.Adjustments = append(breakdown.Adjustments, PriceAdjustment{
+			Rule:   rule.Name(),
+			Amount: -discount,
+		})
+	}
+	return breakdown
+}
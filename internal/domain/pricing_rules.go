@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	PremiumBundleRuleType      = "premium_bundle"
+	CategoryPercentOffRuleType = "category_percent_off"
+	BuyXGetYRuleType           = "buy_x_get_y"
+	ThresholdAmountOffRuleType = "threshold_amount_off"
+)
+
+var (
+	ErrInvalidDiscountRate = errors.New("discount must be greater than 0 and at most 1")
+	ErrUnknownPricingRule  = func(ruleType string) error {
+		return fmt.Errorf("unknown pricing rule type: %s", ruleType)
+	}
+)
+
+// PremiumBundleRule takes a percentage off the whole order when it holds at least the given number
+// of unique premium products.
+type PremiumBundleRule struct {
+	MinUniqueProducts int
+	Rate              float64
+}
+
+func (rule PremiumBundleRule) Name() string {
+	return fmt.Sprintf("%d unique premium products: %g%% off", rule.MinUniqueProducts, rule.Rate*100)
+}
+
+func (rule PremiumBundleRule) Discount(lines []OrderLine, total float64) float64 {
+	uniquePremiumProducts := 0
+	for _, line := range lines {
+		if line.category == Premium {
+			uniquePremiumProducts += 1
+		}
+	}
+	if uniquePremiumProducts < rule.MinUniqueProducts {
+		return 0
+	}
+	return total * rule.Rate
+}
+
+// CategoryPercentOffRule takes a percentage off the lines of the given category.
+type CategoryPercentOffRule struct {
+	Category ProductCategory
+	Rate     float64
+}
+
+func (rule CategoryPercentOffRule) Name() string {
+	return fmt.Sprintf("%s products: %g%% off", rule.Category, rule.Rate*100)
+}
+
+func (rule CategoryPercentOffRule) Discount(lines []OrderLine, _ float64) float64 {
+	discount := 0.0
+	for _, line := range lines {
+		if line.category == rule.Category {
+			discount += line.Total() * rule.Rate
+		}
+	}
+	return discount
+}
+
+// BuyXGetYRule makes Free units of the product free for every Buy+Free units ordered.
+type BuyXGetYRule struct {
+	ProductID string
+	Buy       int
+	Free      int
+}
+
+func (rule BuyXGetYRule) Name() string {
+	return fmt.Sprintf("product #%s: buy %d get %d free", rule.ProductID, rule.Buy, rule.Free)
+}
+
+func (rule BuyXGetYRule) Discount(lines []OrderLine, _ float64) float64 {
+	for _, line := range lines {
+		if line.productID != rule.ProductID {
+			continue
+		}
+		freeUnits := (line.quantity / (rule.Buy + rule.Free)) * rule.Free
+		return float64(freeUnits) * line.price
+	}
+	return 0
+}
+
+// ThresholdAmountOffRule takes a fixed amount off the order once its total reaches the threshold.
+type ThresholdAmountOffRule struct {
+	Threshold float64
+	Amount    float64
+}
+
+func (rule ThresholdAmountOffRule) Name() string {
+	return fmt.Sprintf("%g off orders of %g or more", rule.Amount, rule.Threshold)
+}
+
+func (rule ThresholdAmountOffRule) Discount(_ []OrderLine, total float64) float64 {
+	if total < rule.Threshold {
+		return 0
+	}
+	return rule.Amount
+}
+
+// PricingRuleConfig describes a pricing rule in configuration. Only the fields used by the rule of
+// the given type need to be set.
+type PricingRuleConfig struct {
+	Type              string          `json:"type"`
+	MinUniqueProducts int             `json:"min_unique_products,omitempty"`
+	Category          ProductCategory `json:"category,omitempty"`
+	Discount          float64         `json:"discount,omitempty"`
+	ProductID         string          `json:"product_id,omitempty"`
+	Buy               int             `json:"buy,omitempty"`
+	Free              int             `json:"free,omitempty"`
+	Threshold         float64         `json:"threshold,omitempty"`
+	Amount            float64         `json:"amount,omitempty"`
+}
+
+func (config PricingRuleConfig) Build() (PricingRule, error) {
+	switch config.Type {
+	case PremiumBundleRuleType:
+		if config.MinUniqueProducts < 1 {
+			return nil, errors.New("premium bundle rule: min_unique_products must be at least 1")
+		}
+		if config.Discount <= 0 || config.Discount > 1 {
+			return nil, fmt.Errorf("premium bundle rule: %w", ErrInvalidDiscountRate)
+		}
+		return PremiumBundleRule{MinUniqueProducts: config.MinUniqueProducts, Rate: config.Discount}, nil
+	case CategoryPercentOffRuleType:
+		if !config.Category.IsValid() {
+			return nil, fmt.Errorf("category percent off rule: %w", ErrInvalidProductCategory)
+		}
+		if config.Discount <= 0 || config.Discount > 1 {
+			return nil, fmt.Errorf("category percent off rule: %w", ErrInvalidDiscountRate)
+		}
+		return CategoryPercentOffRule{Category: config.Category, Rate: config.Discount}, nil
+	case BuyXGetYRuleType:
+		if config.ProductID == "" {
+			return nil, errors.New("buy x get y rule: product_id must not be empty")
+		}
+		if config.Buy < 1 || config.Free < 1 {
+			return nil, errors.New("buy x get y rule: buy and free must be at least 1")
+		}
+		return BuyXGetYRule{ProductID: config.ProductID, Buy: config.Buy, Free: config.Free}, nil
+	case ThresholdAmountOffRuleType:
+		if config.Threshold <= 0 || config.Amount <= 0 {
+			return nil, errors.New("threshold amount off rule: threshold and amount must be greater than zero")
+		}
+		return ThresholdAmountOffRule{Threshold: config.Threshold, Amount: config.Amount}, nil
+	}
+	return nil, ErrUnknownPricingRule(config.Type)
+}
+
+// ParsePricingRules builds the pricing rules described by a JSON array of PricingRuleConfig, in the
+// order in which they appear.
+func ParsePricingRules(data []byte) ([]PricingRule, error) {
+	var configs []PricingRuleConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+	rules := make([]PricingRule, len(configs))
+	for idx, config := range configs {
+		rule, err := config.Build()
+		if err != nil {
+			return nil, err
+		}
+		rules[idx] = rule
+	}
+	return rules, nil
+}
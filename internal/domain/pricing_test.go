@@ -0,0 +1,112 @@
+package domain_test
+
+import (
+	"simple-order-service/internal/domain"
+	"testing"
+)
+
+func TestPremiumBundleRule_FourUniquePremiumProducts(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.Add(domain.NewProduct("1", "nike shoes", 10.0, 3, domain.Premium))
+	order.Add(domain.NewProduct("2", "adidas shoes", 20.0, 3, domain.Premium))
+	order.Add(domain.NewProduct("3", "puma shoes", 30.0, 3, domain.Premium))
+	order.Add(domain.NewProduct("4", "reebok shoes", 40.0, 3, domain.Premium))
+
+	got := order.Value(defaultEngine)
+	want := 100.0 * (1 - domain.DefaultPremiumBundleDiscount)
+	if got != want {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestPricingEngine_RulesAreEvaluatedInOrder(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.AddQuantity(domain.NewProduct("1", "socks", 10.0, 10, domain.Budget), 3, domain.DefaultLimits())
+	order.AddQuantity(domain.NewProduct("2", "shirt", 50.0, 10, domain.Regular), 2, domain.DefaultLimits())
+
+	engine := domain.NewPricingEngine(
+		domain.BuyXGetYRule{ProductID: "1", Buy: 2, Free: 1},
+		domain.CategoryPercentOffRule{Category: domain.Regular, Rate: 0.1},
+		domain.ThresholdAmountOffRule{Threshold: 100.0, Amount: 5.0},
+	)
+
+	got := order.Price(engine)
+	if got.Subtotal != 130.0 {
+		t.Errorf("Got subtotal: %v, Want: %v", got.Subtotal, 130.0)
+	}
+	wantAmounts := []float64{-10.0, -10.0, -5.0}
+	if len(got.Adjustments) != len(wantAmounts) {
+		t.Fatalf("Got: %v adjustments, Want: %v", len(got.Adjustments), len(wantAmounts))
+	}
+	for idx, want := range wantAmounts {
+		if got.Adjustments[idx].Amount != want {
+			t.Errorf("Got: %v for %s, Want: %v", got.Adjustments[idx].Amount, got.Adjustments[idx].Rule, want)
+		}
+	}
+	if got.Total != 105.0 {
+		t.Errorf("Got total: %v, Want: %v", got.Total, 105.0)
+	}
+}
+
+func TestPricingEngine_ThresholdIsCheckedAfterEarlierDiscounts(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.AddQuantity(domain.NewProduct("1", "shirt", 50.0, 10, domain.Regular), 2, domain.DefaultLimits())
+
+	engine := domain.NewPricingEngine(
+		domain.CategoryPercentOffRule{Category: domain.Regular, Rate: 0.1},
+		domain.ThresholdAmountOffRule{Threshold: 100.0, Amount: 5.0},
+	)
+
+	got := order.Price(engine)
+	if len(got.Adjustments) != 1 || got.Total != 90.0 {
+		t.Errorf("Got: %+v, Want a single adjustment and a total of %v", got, 90.0)
+	}
+}
+
+func TestPricingEngine_TotalIsNeverNegative(t *testing.T) {
+	order := domain.NewOrder("123")
+	order.Add(domain.NewProduct("1", "socks", 10.0, 10, domain.Budget))
+
+	engine := domain.NewPricingEngine(domain.ThresholdAmountOffRule{Threshold: 1.0, Amount: 50.0})
+
+	got := order.Price(engine)
+	if got.Total != 0 || got.Adjustments[0].Amount != -10.0 {
+		t.Errorf("Got: %+v, Want a total of %v", got, 0)
+	}
+}
+
+func TestParsePricingRules(t *testing.T) {
+	data := []byte(`[
+		{"type": "premium_bundle", "min_unique_products": 3, "discount": 0.1},
+		{"type": "category_percent_off", "category": "budget", "discount": 0.05},
+		{"type": "buy_x_get_y", "product_id": "4", "buy": 2, "free": 1},
+		{"type": "threshold_amount_off", "threshold": 100, "amount": 10}
+	]`)
+
+	rules, err := domain.ParsePricingRules(data)
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if len(rules) != 4 {
+		t.Fatalf("Got: %v rules, Want: %v", len(rules), 4)
+	}
+	if _, ok := rules[2].(domain.BuyXGetYRule); !ok {
+		t.Errorf("Got: %T, Want: %T", rules[2], domain.BuyXGetYRule{})
+	}
+}
+
+func TestParsePricingRules_Errors(t *testing.T) {
+	tests := []string{
+		`[{"type": "mystery"}]`,
+		`[{"type": "premium_bundle", "min_unique_products": 3, "discount": 1.5}]`,
+		`[{"type": "category_percent_off", "category": "luxury", "discount": 0.1}]`,
+		`[{"type": "buy_x_get_y", "product_id": "4", "buy": 0, "free": 1}]`,
+		`[{"type": "threshold_amount_off", "threshold": 100}]`,
+	}
+
+	for _, data := range tests {
+		if _, err := domain.ParsePricingRules([]byte(data)); err == nil {
+			t.Errorf("Got: %v, Want an error for %s", err, data)
+		}
+	}
+}
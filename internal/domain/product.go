@@ -1,13 +1,41 @@
 package domain
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
 
 //go:generate moq -out product_repository_mock.go . ProductRepository
 
 type ProductRepository interface {
-	Store(product Product) error
-	FindById(id string) Product
-	GetAll() []Product // Ideally this API should return paginated results, but for the sake of simplicity, we will be returning all results, assuming results are not many
+	Store(ctx context.Context, product Product) error
+	FindById(ctx context.Context, id string) Product
+	GetAll(ctx context.Context) []Product
+	Find(ctx context.Context, query ProductQuery) ([]Product, string, error)
+	Delete(ctx context.Context, id string) error
+}
+
+var (
+	ErrMissingProductID       = errors.New("product id must not be empty")
+	ErrMissingProductName     = errors.New("product name must not be empty")
+	ErrInvalidProductPrice    = errors.New("product price must be greater than zero")
+	ErrInvalidProductStock    = errors.New("product stock must not be negative")
+	ErrInvalidProductCategory = errors.New("invalid product category. the different product category values are: 'premium', 'regular' and 'budget'")
+
+	ErrInsufficientStockToReserve = func(name string, available int) error {
+		return fmt.Errorf("product: %s cannot be reserved as only %d units are available", name, available)
+	}
+)
+
+type ProductError struct {
+	Err error
+}
+
+func (e ProductError) Error() string {
+	return e.Err.Error()
 }
 
 type Product struct {
@@ -15,7 +43,9 @@ type Product struct {
 	name     string
 	price    float64
 	sku      int
+	reserved int
 	category ProductCategory
+	version  int
 }
 
 func NewProduct(id string, name string, price float64, sku int, category ProductCategory) Product {
@@ -48,6 +78,51 @@ func (product *Product) Category() ProductCategory {
 	return product.category
 }
 
+// Reserved is the number of units held for open orders.
+func (product *Product) Reserved() int {
+	return product.reserved
+}
+
+// Available is the number of units which can still be added to orders, i.e., the stock which is not
+// held for open orders.
+func (product *Product) Available() int {
+	if product.reserved >= product.sku {
+		return 0
+	}
+	return product.sku - product.reserved
+}
+
+// WithDetails returns a copy of the product with the given catalogue details, keeping the units
+// reserved for open orders.
+func (product Product) WithDetails(name string, price float64, sku int, category ProductCategory) Product {
+	product.name = name
+	product.price = price
+	product.sku = sku
+	product.category = category
+	return product
+}
+
+func (product *Product) Reserve(quantity int) error {
+	if quantity > product.Available() {
+		return &ProductError{Err: ErrInsufficientStockToReserve(product.name, product.Available())}
+	}
+	product.reserved += quantity
+	return nil
+}
+
+func (product *Product) ReleaseReservation(quantity int) {
+	product.reserved -= quantity
+	if product.reserved < 0 {
+		product.reserved = 0
+	}
+}
+
+// CommitReservation turns reserved units into sold units once the order holding them is placed.
+func (product *Product) CommitReservation(quantity int) {
+	product.ReleaseReservation(quantity)
+	product.DecreaseStockBy(quantity)
+}
+
 func (product *Product) DecreaseStockBy(decreaseBy int) {
 	currentStock := product.sku
 	currentStock -= decreaseBy
@@ -58,8 +133,46 @@ func (product *Product) DecreaseStockBy(decreaseBy int) {
 	product.sku = currentStock
 }
 
+// Validate checks that the product can be listed in the catalogue.
+func (product *Product) Validate() error {
+	if strings.TrimSpace(product.id) == "" {
+		return &ProductError{Err: ErrMissingProductID}
+	}
+	if strings.TrimSpace(product.name) == "" {
+		return &ProductError{Err: ErrMissingProductName}
+	}
+	if product.price <= 0 {
+		return &ProductError{Err: ErrInvalidProductPrice}
+	}
+	if product.sku < 0 {
+		return &ProductError{Err: ErrInvalidProductStock}
+	}
+	if !product.category.IsValid() {
+		return &ProductError{Err: ErrInvalidProductCategory}
+	}
+	return nil
+}
+
+func (product *Product) IncreaseStockBy(increaseBy int) {
+	if increaseBy <= 0 {
+		return
+	}
+	product.sku += increaseBy
+}
+
 func (product *Product) IsAvailable() bool {
-	return product.sku > 0
+	return product.Available() > 0
+}
+
+// Version is the number of times the product has been stored. It is 0 for a product which has never
+// been stored.
+func (product *Product) Version() int {
+	return product.version
+}
+
+// IncrementVersion records that repositories have stored the product.
+func (product *Product) IncrementVersion() {
+	product.version += 1
 }
 
 func (product *Product) MarshalJSON() ([]byte, error) {
@@ -68,13 +181,17 @@ func (product *Product) MarshalJSON() ([]byte, error) {
 		Name     string
 		Price    float64
 		Sku      int
+		Reserved int
 		Category ProductCategory
+		Version  int
 	}{
 		Id:       product.id,
 		Name:     product.name,
 		Price:    product.price,
 		Sku:      product.sku,
+		Reserved: product.reserved,
 		Category: product.category,
+		Version:  product.version,
 	})
 	if err != nil {
 		return nil, err
@@ -88,7 +205,9 @@ func (product *Product) UnmarshalJSON(data []byte) error {
 		Name     string          `json:"name"`
 		Price    float64         `json:"price"`
 		Sku      int             `json:"sku"`
+		Reserved int             `json:"reserved"`
 		Category ProductCategory `json:"category"`
+		Version  int             `json:"version"`
 	}
 	p := &prod{}
 	if err := json.Unmarshal(data, p); err != nil {
@@ -99,6 +218,8 @@ func (product *Product) UnmarshalJSON(data []byte) error {
 	product.name = p.Name
 	product.price = p.Price
 	product.sku = p.Sku
+	product.reserved = p.Reserved
 	product.category = p.Category
+	product.version = p.Version
 	return nil
 }
@@ -7,3 +7,16 @@ const (
 	Regular ProductCategory = "regular"
 	Budget  ProductCategory = "budget"
 )
+
+// ProductCategories returns every category, from the most to the least expensive.
+func ProductCategories() []ProductCategory {
+	return []ProductCategory{Premium, Regular, Budget}
+}
+
+func (category ProductCategory) IsValid() bool {
+	switch category {
+	case Premium, Regular, Budget:
+		return true
+	}
+	return false
+}
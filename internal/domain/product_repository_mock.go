@@ -4,6 +4,7 @@
 package domain
 
 import (
+	"context"
 	"sync"
 )
 
@@ -17,13 +18,19 @@ var _ ProductRepository = &ProductRepositoryMock{}
 //
 //		// make and configure a mocked ProductRepository
 //		mockedProductRepository := &ProductRepositoryMock{
-//			FindByIdFunc: func(id string) Product {
+//			DeleteFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the Delete method")
+//			},
+//			FindFunc: func(ctx context.Context, query ProductQuery) ([]Product, string, error) {
+//				panic("mock out the Find method")
+//			},
+//			FindByIdFunc: func(ctx context.Context, id string) Product {
 //				panic("mock out the FindById method")
 //			},
-//			GetAllFunc: func() []Product {
+//			GetAllFunc: func(ctx context.Context) []Product {
 //				panic("mock out the GetAll method")
 //			},
-//			StoreFunc: func(product Product) error {
+//			StoreFunc: func(ctx context.Context, product Product) error {
 //				panic("mock out the Store method")
 //			},
 //		}
@@ -33,50 +40,152 @@ var _ ProductRepository = &ProductRepositoryMock{}
 //
 //	}
 type ProductRepositoryMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, id string) error
+
+	// FindFunc mocks the Find method.
+	FindFunc func(ctx context.Context, query ProductQuery) ([]Product, string, error)
+
 	// FindByIdFunc mocks the FindById method.
-	FindByIdFunc func(id string) Product
+	FindByIdFunc func(ctx context.Context, id string) Product
 
 	// GetAllFunc mocks the GetAll method.
-	GetAllFunc func() []Product
+	GetAllFunc func(ctx context.Context) []Product
 
 	// StoreFunc mocks the Store method.
-	StoreFunc func(product Product) error
+	StoreFunc func(ctx context.Context, product Product) error
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// Find holds details about calls to the Find method.
+		Find []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Query is the query argument value.
+			Query ProductQuery
+		}
 		// FindById holds details about calls to the FindById method.
 		FindById []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the id argument value.
 			ID string
 		}
 		// GetAll holds details about calls to the GetAll method.
 		GetAll []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 		}
 		// Store holds details about calls to the Store method.
 		Store []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// Product is the product argument value.
 			Product Product
 		}
 	}
+	lockDelete   sync.RWMutex
+	lockFind     sync.RWMutex
 	lockFindById sync.RWMutex
 	lockGetAll   sync.RWMutex
 	lockStore    sync.RWMutex
 }
 
+// Delete calls DeleteFunc.
+func (mock *ProductRepositoryMock) Delete(ctx context.Context, id string) error {
+	if mock.DeleteFunc == nil {
+		panic("ProductRepositoryMock.DeleteFunc: method is nil but ProductRepository.Delete was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(ctx, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedProductRepository.DeleteCalls())
+func (mock *ProductRepositoryMock) DeleteCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Find calls FindFunc.
+func (mock *ProductRepositoryMock) Find(ctx context.Context, query ProductQuery) ([]Product, string, error) {
+	if mock.FindFunc == nil {
+		panic("ProductRepositoryMock.FindFunc: method is nil but ProductRepository.Find was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Query ProductQuery
+	}{
+		Ctx:   ctx,
+		Query: query,
+	}
+	mock.lockFind.Lock()
+	mock.calls.Find = append(mock.calls.Find, callInfo)
+	mock.lockFind.Unlock()
+	return mock.FindFunc(ctx, query)
+}
+
+// FindCalls gets all the calls that were made to Find.
+// Check the length with:
+//
+//	len(mockedProductRepository.FindCalls())
+func (mock *ProductRepositoryMock) FindCalls() []struct {
+	Ctx   context.Context
+	Query ProductQuery
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Query ProductQuery
+	}
+	mock.lockFind.RLock()
+	calls = mock.calls.Find
+	mock.lockFind.RUnlock()
+	return calls
+}
+
 // FindById calls FindByIdFunc.
-func (mock *ProductRepositoryMock) FindById(id string) Product {
+func (mock *ProductRepositoryMock) FindById(ctx context.Context, id string) Product {
 	if mock.FindByIdFunc == nil {
 		panic("ProductRepositoryMock.FindByIdFunc: method is nil but ProductRepository.FindById was just called")
 	}
 	callInfo := struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}{
-		ID: id,
+		Ctx: ctx,
+		ID:  id,
 	}
 	mock.lockFindById.Lock()
 	mock.calls.FindById = append(mock.calls.FindById, callInfo)
 	mock.lockFindById.Unlock()
-	return mock.FindByIdFunc(id)
+	return mock.FindByIdFunc(ctx, id)
 }
 
 // FindByIdCalls gets all the calls that were made to FindById.
@@ -84,10 +193,12 @@ func (mock *ProductRepositoryMock) FindById(id string) Product {
 //
 //	len(mockedProductRepository.FindByIdCalls())
 func (mock *ProductRepositoryMock) FindByIdCalls() []struct {
-	ID string
+	Ctx context.Context
+	ID  string
 } {
 	var calls []struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}
 	mock.lockFindById.RLock()
 	calls = mock.calls.FindById
@@ -96,16 +207,19 @@ func (mock *ProductRepositoryMock) FindByIdCalls() []struct {
 }
 
 // GetAll calls GetAllFunc.
-func (mock *ProductRepositoryMock) GetAll() []Product {
+func (mock *ProductRepositoryMock) GetAll(ctx context.Context) []Product {
 	if mock.GetAllFunc == nil {
 		panic("ProductRepositoryMock.GetAllFunc: method is nil but ProductRepository.GetAll was just called")
 	}
 	callInfo := struct {
-	}{}
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
 	mock.lockGetAll.Lock()
 	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
 	mock.lockGetAll.Unlock()
-	return mock.GetAllFunc()
+	return mock.GetAllFunc(ctx)
 }
 
 // GetAllCalls gets all the calls that were made to GetAll.
@@ -113,8 +227,10 @@ func (mock *ProductRepositoryMock) GetAll() []Product {
 //
 //	len(mockedProductRepository.GetAllCalls())
 func (mock *ProductRepositoryMock) GetAllCalls() []struct {
+	Ctx context.Context
 } {
 	var calls []struct {
+		Ctx context.Context
 	}
 	mock.lockGetAll.RLock()
 	calls = mock.calls.GetAll
@@ -123,19 +239,21 @@ func (mock *ProductRepositoryMock) GetAllCalls() []struct {
 }
 
 // Store calls StoreFunc.
-func (mock *ProductRepositoryMock) Store(product Product) error {
+func (mock *ProductRepositoryMock) Store(ctx context.Context, product Product) error {
 	if mock.StoreFunc == nil {
 		panic("ProductRepositoryMock.StoreFunc: method is nil but ProductRepository.Store was just called")
 	}
 	callInfo := struct {
+		Ctx     context.Context
 		Product Product
 	}{
+		Ctx:     ctx,
 		Product: product,
 	}
 	mock.lockStore.Lock()
 	mock.calls.Store = append(mock.calls.Store, callInfo)
 	mock.lockStore.Unlock()
-	return mock.StoreFunc(product)
+	return mock.StoreFunc(ctx, product)
 }
 
 // StoreCalls gets all the calls that were made to Store.
@@ -143,9 +261,11 @@ func (mock *ProductRepositoryMock) Store(product Product) error {
 //
 //	len(mockedProductRepository.StoreCalls())
 func (mock *ProductRepositoryMock) StoreCalls() []struct {
+	Ctx     context.Context
 	Product Product
 } {
 	var calls []struct {
+		Ctx     context.Context
 		Product Product
 	}
 	mock.lockStore.RLock()
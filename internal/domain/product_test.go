@@ -1,6 +1,7 @@
 package domain_test
 
 import (
+	"errors"
 	"simple-order-service/internal/domain"
 	"testing"
 )
@@ -52,3 +53,80 @@ func TestDecreaseProductIsAvailable_False(t *testing.T) {
 		t.Errorf("Got: %v, Want: %v", got, want)
 	}
 }
+
+func TestValidateProduct_Success(t *testing.T) {
+	product := domain.NewProduct("1", "nike shoes", 100.0, 0, domain.Regular)
+
+	if got := product.Validate(); got != nil {
+		t.Errorf("Got: %v, Want: %v", got, nil)
+	}
+}
+
+func TestValidateProduct_Errors(t *testing.T) {
+	tests := []struct {
+		product domain.Product
+		want    error
+	}{
+		{domain.NewProduct("", "nike shoes", 100.0, 1, domain.Premium), domain.ErrMissingProductID},
+		{domain.NewProduct("1", " ", 100.0, 1, domain.Premium), domain.ErrMissingProductName},
+		{domain.NewProduct("1", "nike shoes", 0, 1, domain.Premium), domain.ErrInvalidProductPrice},
+		{domain.NewProduct("1", "nike shoes", 100.0, -1, domain.Premium), domain.ErrInvalidProductStock},
+		{domain.NewProduct("1", "nike shoes", 100.0, 1, domain.ProductCategory("luxury")), domain.ErrInvalidProductCategory},
+	}
+
+	for _, test := range tests {
+		got := test.product.Validate()
+		var productErr *domain.ProductError
+		if !errors.As(got, &productErr) || !errors.Is(productErr.Err, test.want) {
+			t.Errorf("Got: %v, Want: %v", got, test.want)
+		}
+	}
+}
+
+func TestIncreaseProductStock(t *testing.T) {
+	product := domain.NewProduct("1", "nike shoes", 100.0, 0, domain.Premium)
+
+	product.IncreaseStockBy(3)
+	got := product.SKU()
+	want := 3
+
+	if got != want {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestReserveProduct_ReducesAvailableStock(t *testing.T) {
+	product := domain.NewProduct("1", "nike shoes", 100.0, 5, domain.Premium)
+
+	if err := product.Reserve(3); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if product.SKU() != 5 || product.Reserved() != 3 || product.Available() != 2 {
+		t.Errorf("Got: %v sku, %v reserved, %v available, Want: 5 sku, 3 reserved, 2 available",
+			product.SKU(), product.Reserved(), product.Available())
+	}
+}
+
+func TestReserveProduct_InsufficientStock(t *testing.T) {
+	product := domain.NewProduct("1", "nike shoes", 100.0, 1, domain.Premium)
+	product.Reserve(1)
+
+	got := product.Reserve(1)
+	var productErr *domain.ProductError
+	if !errors.As(got, &productErr) {
+		t.Errorf("Got: %v, Want: %v", got, domain.ErrInsufficientStockToReserve("nike shoes", 0))
+	}
+	if product.IsAvailable() {
+		t.Error("a fully reserved product must not be available")
+	}
+}
+
+func TestCommitReservation(t *testing.T) {
+	product := domain.NewProduct("1", "nike shoes", 100.0, 5, domain.Premium)
+	product.Reserve(3)
+
+	product.CommitReservation(3)
+	if product.SKU() != 2 || product.Reserved() != 0 {
+		t.Errorf("Got: %v sku, %v reserved, Want: 2 sku, 0 reserved", product.SKU(), product.Reserved())
+	}
+}
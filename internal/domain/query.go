@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCursor is returned for a cursor which was not returned along with a page.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+var ErrInvalidPageLimit = errors.New("invalid page limit")
+
+// Page selects a page of results ordered by id. Cursor is the opaque value returned along with the
+// previous page, and is empty for the first page.
+type Page struct {
+	Cursor     string
+	Limit      int
+	Descending bool
+}
+
+func (page Page) Validate(limits Limits) error {
+	if page.Limit < 1 || page.Limit > limits.MaxPageLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPageLimit, limits.MaxPageLimit)
+	}
+	return nil
+}
+
+type OrderQuery struct {
+	Page
+	Status     OrderStatus
+	CustomerID string
+}
+
+func (query OrderQuery) Matches(order Order) bool {
+	if query.CustomerID != "" && order.customerID != query.CustomerID {
+		return false
+	}
+	return query.Status == "" || order.status == query.Status
+}
+
+type ProductQuery struct {
+	Page
+	Category    ProductCategory
+	MinPrice    float64
+	InStockOnly bool
+}
+
+func (query ProductQuery) Matches(product Product) bool {
+	if query.Category != "" && product.category != query.Category {
+		return false
+	}
+	if product.price < query.MinPrice {
+		return false
+	}
+	return !query.InStockOnly || product.IsAvailable()
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+//go:generate moq -out reservation_repository_mock.go . ReservationRepository
+
+type ReservationRepository interface {
+	Store(ctx context.Context, reservation Reservation) error
+	Find(ctx context.Context, orderID, productID string) Reservation
+	FindByOrder(ctx context.Context, orderID string) []Reservation
+	FindExpired(ctx context.Context, at time.Time) []Reservation
+	Delete(ctx context.Context, orderID, productID string) error
+}
+
+// Reservation holds units of a product for an open order until it expires, so that the units
+// cannot be added to other orders in the meantime.
+type Reservation struct {
+	orderID   string
+	productID string
+	quantity  int
+	expiresAt time.Time
+}
+
+func NewReservation(orderID, productID string, quantity int, expiresAt time.Time) Reservation {
+	return Reservation{
+		orderID:   orderID,
+		productID: productID,
+		quantity:  quantity,
+		expiresAt: expiresAt,
+	}
+}
+
+func (reservation *Reservation) OrderID() string {
+	return reservation.orderID
+}
+
+func (reservation *Reservation) ProductID() string {
+	return reservation.productID
+}
+
+func (reservation *Reservation) Quantity() int {
+	return reservation.quantity
+}
+
+func (reservation *Reservation) ExpiresAt() time.Time {
+	return reservation.expiresAt
+}
+
+func (reservation *Reservation) IsExpired(at time.Time) bool {
+	return !at.Before(reservation.expiresAt)
+}
+
+// Extend holds more units of the product and pushes back the expiry of the reservation.
+func (reservation *Reservation) Extend(quantity int, expiresAt time.Time) {
+	reservation.quantity += quantity
+	reservation.expiresAt = expiresAt
+}
+
+func (reservation *Reservation) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		OrderID   string    `json:"order_id"`
+		ProductID string    `json:"product_id"`
+		Quantity  int       `json:"quantity"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}{
+		OrderID:   reservation.orderID,
+		ProductID: reservation.productID,
+		Quantity:  reservation.quantity,
+		ExpiresAt: reservation.expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (reservation *Reservation) UnmarshalJSON(data []byte) error {
+	type rsv struct {
+		OrderID   string    `json:"order_id"`
+		ProductID string    `json:"product_id"`
+		Quantity  int       `json:"quantity"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	r := &rsv{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return err
+	}
+	reservation.orderID = r.OrderID
+	reservation.productID = r.ProductID
+	reservation.quantity = r.Quantity
+	reservation.expiresAt = r.ExpiresAt
+	return nil
+}
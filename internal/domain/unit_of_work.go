@@ -0,0 +1,24 @@
+package domain
+
+import "context"
+
+//go:generate moq -out unit_of_work_mock.go . UnitOfWork
+
+// Repositories groups the repositories which take part in a single unit of work.
+type Repositories struct {
+	Orders       OrderRepository
+	Products     ProductRepository
+	Coupons      CouponRepository
+	Reservations ReservationRepository
+	Webhooks     WebhookRepository
+	Outbox       OutboxRepository
+	APIKeys      APIKeyRepository
+	Customers    CustomerRepository
+}
+
+// UnitOfWork runs a function against repositories which share one transaction: every change made
+// through them is committed if the function returns nil and rolled back otherwise. The repositories
+// log with the logger of ctx.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AnyVersion is given as the expected version of an order or a product by callers which do not
+// need their update to be conditional on the version they have read.
+const AnyVersion = -1
+
+var ErrVersionConflict = errors.New("the resource has been modified since it was read")
+
+// CheckVersion returns ErrVersionConflict if the current version of an entity is not the expected one.
+func CheckVersion(expected, current int) error {
+	if expected == AnyVersion || expected == current {
+		return nil
+	}
+	return fmt.Errorf("%w: expected version %d, current version is %d", ErrVersionConflict, expected, current)
+}
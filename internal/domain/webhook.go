@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//go:generate moq -out webhook_repository_mock.go . WebhookRepository
+
+type WebhookRepository interface {
+	Store(ctx context.Context, webhook Webhook) error
+	FindById(ctx context.Context, id string) Webhook
+	GetAll(ctx context.Context) []Webhook
+	Delete(ctx context.Context, id string) error
+}
+
+var (
+	ErrInvalidWebhookURL    = errors.New("webhook url must be an absolute http or https url")
+	ErrInternalWebhookURL   = errors.New("webhook url must not target a loopback, link-local or private address")
+	ErrMissingWebhookSecret = errors.New("webhook secret must not be empty")
+	ErrInvalidWebhookEvent  = errors.New("invalid webhook event type. the different event types are: 'order_created', 'product_added', 'status_changed', 'dispatch_date_set', 'coupon_applied', 'coupon_removed' and 'stock_released'")
+)
+
+type WebhookError struct {
+	Err error
+}
+
+func (e WebhookError) Error() string {
+	return e.Err.Error()
+}
+
+// WebhookEventTypes returns the types of the events of orders which webhooks can subscribe to.
+func WebhookEventTypes() []OrderEventType {
+	return []OrderEventType{OrderCreated, OrderProductAdded, OrderStatusChanged, OrderDispatchDateSet,
+		OrderCouponApplied, OrderCouponRemoved, OrderStockReleased}
+}
+
+func isWebhookEventType(eventType OrderEventType) bool {
+	for _, candidate := range WebhookEventTypes() {
+		if eventType == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook is a subscription of an external system to the events of orders. The events are posted to
+// url, signed with secret. A webhook without event types is subscribed to every event.
+type Webhook struct {
+	id         string
+	url        string
+	secret     string
+	eventTypes []OrderEventType
+	createdAt  time.Time
+}
+
+func NewWebhook(id, url, secret string, eventTypes []OrderEventType, createdAt time.Time) Webhook {
+	return Webhook{
+		id:         id,
+		url:        url,
+		secret:     secret,
+		eventTypes: eventTypes,
+		createdAt:  createdAt,
+	}
+}
+
+func (webhook *Webhook) ID() string {
+	return webhook.id
+}
+
+func (webhook *Webhook) URL() string {
+	return webhook.url
+}
+
+func (webhook *Webhook) Secret() string {
+	return webhook.secret
+}
+
+func (webhook *Webhook) EventTypes() []OrderEventType {
+	return webhook.eventTypes
+}
+
+func (webhook *Webhook) CreatedAt() time.Time {
+	return webhook.createdAt
+}
+
+// Validate checks the webhook. Its url may not target a loopback, link-local or private address, so
+// that webhooks cannot be used to reach the network of the service, unless its host is one of
+// allowedHosts. Hosts which are named rather than given by address are checked once they are
+// resolved, when events are delivered.
+func (webhook *Webhook) Validate(allowedHosts []string) error {
+	target, err := url.Parse(webhook.url)
+	if err != nil || !target.IsAbs() || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return &WebhookError{Err: ErrInvalidWebhookURL}
+	}
+	if isInternalHost(target.Hostname()) && !IsAllowedWebhookHost(target.Hostname(), allowedHosts) {
+		return &WebhookError{Err: ErrInternalWebhookURL}
+	}
+	if webhook.secret == "" {
+		return &WebhookError{Err: ErrMissingWebhookSecret}
+	}
+	for _, eventType := range webhook.eventTypes {
+		if !isWebhookEventType(eventType) {
+			return &WebhookError{Err: ErrInvalidWebhookEvent}
+		}
+	}
+	return nil
+}
+
+// IsAllowedWebhookHost reports whether the host is one of allowedHosts, which webhooks may target
+// whatever its address.
+func IsAllowedWebhookHost(host string, allowedHosts []string) bool {
+	for _, allowed := range allowedHosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInternalAddress reports whether the address is a loopback, link-local, private or unspecified
+// one, which webhooks may not target.
+func IsInternalAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+func isInternalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && IsInternalAddress(ip)
+}
+
+func (webhook *Webhook) Subscribes(eventType OrderEventType) bool {
+	if len(webhook.eventTypes) == 0 {
+		return true
+	}
+	for _, subscribed := range webhook.eventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload, keyed with the secret of the webhook, so
+// that the receiver can check that the payload was sent by this service.
+func (webhook *Webhook) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(webhook.secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (webhook *Webhook) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		ID         string           `json:"id"`
+		URL        string           `json:"url"`
+		Secret     string           `json:"secret"`
+		EventTypes []OrderEventType `json:"event_types,omitempty"`
+		CreatedAt  time.Time        `json:"created_at"`
+	}{
+		ID:         webhook.id,
+		URL:        webhook.url,
+		Secret:     webhook.secret,
+		EventTypes: webhook.eventTypes,
+		CreatedAt:  webhook.createdAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (webhook *Webhook) UnmarshalJSON(data []byte) error {
+	type hook struct {
+		ID         string           `json:"id"`
+		URL        string           `json:"url"`
+		Secret     string           `json:"secret"`
+		EventTypes []OrderEventType `json:"event_types"`
+		CreatedAt  time.Time        `json:"created_at"`
+	}
+	h := &hook{}
+	if err := json.Unmarshal(data, h); err != nil {
+		return err
+	}
+	webhook.id = h.ID
+	webhook.url = h.URL
+	webhook.secret = h.Secret
+	webhook.eventTypes = h.EventTypes
+	webhook.createdAt = h.CreatedAt
+	return nil
+}
@@ -0,0 +1,89 @@
+package domain_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"simple-order-service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestValidateWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		secret     string
+		eventTypes []domain.OrderEventType
+		want       error
+	}{
+		{"valid", "https://example.com/hooks", "secret", []domain.OrderEventType{domain.OrderStatusChanged}, nil},
+		{"relative url", "/hooks", "secret", nil, domain.ErrInvalidWebhookURL},
+		{"unsupported scheme", "ftp://example.com/hooks", "secret", nil, domain.ErrInvalidWebhookURL},
+		{"missing secret", "https://example.com/hooks", "", nil, domain.ErrMissingWebhookSecret},
+		{"unknown event type", "https://example.com/hooks", "secret", []domain.OrderEventType{"order_deleted"}, domain.ErrInvalidWebhookEvent},
+		{"loopback address", "http://127.0.0.1:8080/hooks", "secret", nil, domain.ErrInternalWebhookURL},
+		{"localhost", "http://localhost/hooks", "secret", nil, domain.ErrInternalWebhookURL},
+		{"link-local address", "http://169.254.169.254/latest/meta-data", "secret", nil, domain.ErrInternalWebhookURL},
+		{"private address", "https://10.0.0.5/hooks", "secret", nil, domain.ErrInternalWebhookURL},
+		{"private ipv6 address", "https://[fd00::1]/hooks", "secret", nil, domain.ErrInternalWebhookURL},
+		{"allowed private host", "http://hooks.internal:9000/hooks", "secret", nil, nil},
+		{"allowed loopback address", "http://127.0.0.2/hooks", "secret", nil, nil},
+	}
+	for _, test := range tests {
+		webhook := domain.NewWebhook("1", test.url, test.secret, test.eventTypes, time.Now())
+		err := webhook.Validate([]string{"hooks.internal", "127.0.0.2"})
+		if test.want == nil {
+			if err != nil {
+				t.Errorf("%s: Got: %v, Want: %v", test.name, err, nil)
+			}
+			continue
+		}
+		var webhookErr *domain.WebhookError
+		if !errors.As(err, &webhookErr) || !errors.Is(webhookErr.Err, test.want) {
+			t.Errorf("%s: Got: %v, Want: %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestWebhookSubscribes(t *testing.T) {
+	all := domain.NewWebhook("1", "https://example.com/hooks", "secret", nil, time.Now())
+	if !all.Subscribes(domain.OrderCreated) {
+		t.Errorf("Got: %v, Want: %v", false, true)
+	}
+
+	statusOnly := domain.NewWebhook("2", "https://example.com/hooks", "secret", []domain.OrderEventType{domain.OrderStatusChanged}, time.Now())
+	if !statusOnly.Subscribes(domain.OrderStatusChanged) || statusOnly.Subscribes(domain.OrderProductAdded) {
+		t.Errorf("Got: subscribed to %v, Want: subscribed to %v only", statusOnly.EventTypes(), domain.OrderStatusChanged)
+	}
+}
+
+func TestWebhookSign(t *testing.T) {
+	webhook := domain.NewWebhook("1", "https://example.com/hooks", "secret", nil, time.Now())
+	payload := []byte(`{"id":"1"}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if got := webhook.Sign(payload); got != want {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestWebhookDeliveryFail_BacksOffExponentially(t *testing.T) {
+	at := time.Now()
+	delivery := domain.NewWebhookDelivery("1", domain.OutboxMessage{ID: "7"}, at)
+
+	wantBackoffs := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for idx, want := range wantBackoffs {
+		delivery.Fail("timeout", at, time.Second, 5*time.Second)
+		if got := delivery.NextAttemptAt.Sub(at); got != want {
+			t.Errorf("attempt %d: Got: %v, Want: %v", idx+1, got, want)
+		}
+	}
+	if delivery.Attempts != len(wantBackoffs) || delivery.LastError != "timeout" {
+		t.Errorf("Got: %v attempts, %q, Want: %v attempts, %q", delivery.Attempts, delivery.LastError, len(wantBackoffs), "timeout")
+	}
+}
@@ -0,0 +1,122 @@
+package grpcservice
+
+import (
+	"context"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/logging"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyMetadata carries the API key of a call. Calls can be authenticated with a bearer token in the
+// authorization metadata instead, which is obtained by exchanging an API key at /tokens on the web
+// service.
+const APIKeyMetadata = "x-api-key"
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, apiKey string) (domain.Principal, error)
+	VerifyToken(bearer string) (domain.Principal, error)
+}
+
+// The roles allowed on the methods are the ones allowed on the routes of the web service which do the
+// same thing, except for ListOrders: it serves both GET /orders, which is left to the staff, and
+// GET /customers/{id}/orders, which customers may call for their own orders. Customers may therefore
+// call it, and are only allowed to list their own orders by the order interactor.
+var (
+	anyRole       = []domain.Role{domain.RoleCustomer, domain.RoleOperator, domain.RoleAdmin}
+	customerRoles = []domain.Role{domain.RoleCustomer, domain.RoleAdmin}
+	staffRoles    = []domain.Role{domain.RoleOperator, domain.RoleAdmin}
+	adminRoles    = []domain.Role{domain.RoleAdmin}
+)
+
+var methodRoles = map[string][]domain.Role{
+	orderpb.OrderService_CreateOrder_FullMethodName:         anyRole,
+	orderpb.OrderService_GetOrder_FullMethodName:            anyRole,
+	orderpb.OrderService_ListOrders_FullMethodName:          anyRole,
+	orderpb.OrderService_ListOrderedProducts_FullMethodName: anyRole,
+	orderpb.OrderService_AddProduct_FullMethodName:          customerRoles,
+	orderpb.OrderService_GetOrderHistory_FullMethodName:     anyRole,
+	orderpb.OrderService_UpdateOrderStatus_FullMethodName:   anyRole,
+	orderpb.OrderService_SetDispatchDate_FullMethodName:     staffRoles,
+	orderpb.OrderService_ApplyCoupon_FullMethodName:         customerRoles,
+	orderpb.OrderService_RemoveCoupon_FullMethodName:        customerRoles,
+	orderpb.OrderService_WatchOrderStatus_FullMethodName:    anyRole,
+	orderpb.ProductService_GetProduct_FullMethodName:        anyRole,
+	orderpb.ProductService_ListProducts_FullMethodName:      anyRole,
+	orderpb.ProductService_CreateProduct_FullMethodName:     adminRoles,
+	orderpb.ProductService_ReplaceProduct_FullMethodName:    adminRoles,
+	orderpb.ProductService_UpdateProduct_FullMethodName:     adminRoles,
+	orderpb.ProductService_DeleteProduct_FullMethodName:     adminRoles,
+}
+
+type AuthInterceptor struct {
+	authenticator Authenticator
+}
+
+func NewAuthInterceptor(authenticator Authenticator) *AuthInterceptor {
+	return &AuthInterceptor{authenticator: authenticator}
+}
+
+// Unary authenticates the calls, and passes the principal they are made by down to the interactors
+// in the context of the call. Calls without credentials, or with invalid ones, are rejected with
+// UNAUTHENTICATED; calls by a principal whose role is not allowed on the method are rejected with
+// PERMISSION_DENIED.
+func (interceptor *AuthInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := interceptor.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream authorizes the streaming calls as Unary does the unary ones.
+func (interceptor *AuthInterceptor) Stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := interceptor.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+}
+
+func (interceptor *AuthInterceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var principal domain.Principal
+	var err error
+	if bearer, ok := bearerToken(md); ok {
+		principal, err = interceptor.authenticator.VerifyToken(bearer)
+	} else if apiKeys := md.Get(APIKeyMetadata); len(apiKeys) > 0 && apiKeys[0] != "" {
+		principal, err = interceptor.authenticator.Authenticate(ctx, apiKeys[0])
+	} else {
+		return ctx, status.Error(codes.Unauthenticated, "authentication required. send an api key in the "+APIKeyMetadata+" metadata, or a bearer token")
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("request failed", "error", err)
+		return ctx, status.Error(codes.Unauthenticated, usecases.ErrInvalidCredentials.Error())
+	}
+
+	for _, role := range methodRoles[method] {
+		if principal.Role == role {
+			ctx = usecases.WithPrincipal(ctx, principal)
+			return logging.With(ctx, "subject", principal.Subject, "role", string(principal.Role)), nil
+		}
+	}
+	return ctx, status.Error(codes.PermissionDenied, "the "+string(principal.Role)+" role is not allowed to do this")
+}
+
+func bearerToken(md metadata.MD) (string, bool) {
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	scheme, bearer, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(bearer), true
+}
@@ -0,0 +1,99 @@
+package grpcservice_test
+
+import (
+	"context"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/grpcservice"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/metrics"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeAuthenticator map[string]domain.Principal
+
+func (authenticator fakeAuthenticator) Authenticate(ctx context.Context, apiKey string) (domain.Principal, error) {
+	if principal, ok := authenticator[apiKey]; ok {
+		return principal, nil
+	}
+	return domain.Principal{}, usecases.ErrInvalidCredentials
+}
+
+func (authenticator fakeAuthenticator) VerifyToken(bearer string) (domain.Principal, error) {
+	return authenticator.Authenticate(context.Background(), "token:"+bearer)
+}
+
+var authenticator = fakeAuthenticator{
+	"sos_customer": {Subject: "c-1", Role: domain.RoleCustomer},
+	"sos_admin":    {Subject: "adm", Role: domain.RoleAdmin},
+	"token:abc":    {Subject: "ops", Role: domain.RoleOperator},
+}
+
+func callWith(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func TestAuthInterceptor_AuthorizesByRole(t *testing.T) {
+	interceptor := grpcservice.NewAuthInterceptor(authenticator)
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		want   codes.Code
+	}{
+		{"no credentials", context.Background(), orderpb.OrderService_GetOrder_FullMethodName, codes.Unauthenticated},
+		{"unknown api key", callWith(grpcservice.APIKeyMetadata, "sos_unknown"), orderpb.OrderService_GetOrder_FullMethodName, codes.Unauthenticated},
+		{"invalid token", callWith("authorization", "Bearer xyz"), orderpb.OrderService_GetOrder_FullMethodName, codes.Unauthenticated},
+		{"customer changing the catalogue", callWith(grpcservice.APIKeyMetadata, "sos_customer"), orderpb.ProductService_CreateProduct_FullMethodName, codes.PermissionDenied},
+		{"operator setting a dispatch date", callWith("authorization", "Bearer abc"), orderpb.OrderService_SetDispatchDate_FullMethodName, codes.OK},
+		{"customer placing an order", callWith(grpcservice.APIKeyMetadata, "sos_customer"), orderpb.OrderService_UpdateOrderStatus_FullMethodName, codes.OK},
+		{"customer setting a dispatch date", callWith(grpcservice.APIKeyMetadata, "sos_customer"), orderpb.OrderService_SetDispatchDate_FullMethodName, codes.PermissionDenied},
+		{"admin changing the catalogue", callWith(grpcservice.APIKeyMetadata, "sos_admin"), orderpb.ProductService_CreateProduct_FullMethodName, codes.OK},
+	}
+	for _, test := range tests {
+		var principal domain.Principal
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			principal, _ = usecases.PrincipalFrom(ctx)
+			return nil, nil
+		}
+		_, err := interceptor.Unary(test.ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method}, handler)
+		if got := status.Code(err); got != test.want {
+			t.Errorf("%s: Got: %v, Want: %v", test.name, got, test.want)
+		}
+		if test.want == codes.OK && principal.Subject == "" {
+			t.Errorf("%s: the principal must be passed down to the handler", test.name)
+		}
+	}
+}
+
+func TestListOrders_CustomerCannotListOrdersOfAnotherCustomer(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindFunc: func(ctx context.Context, query domain.OrderQuery) ([]domain.Order, string, error) {
+			return []domain.Order{domain.NewCustomerOrder("1", query.CustomerID)}, "", nil
+		},
+	}
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, &domain.UnitOfWorkMock{}, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	service := grpcservice.NewOrderService(orderInteractor, &domain.FeedRepositoryMock{}, context.Background())
+
+	ctx := usecases.WithPrincipal(context.Background(), domain.Principal{Subject: "c-1", Role: domain.RoleCustomer})
+	if _, err := service.ListOrders(ctx, &orderpb.ListOrdersRequest{CustomerId: "c-2"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Got: %v, Want: %v", status.Code(err), codes.PermissionDenied)
+	}
+	if _, err := service.ListOrders(ctx, &orderpb.ListOrdersRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Got: %v, Want: %v", status.Code(err), codes.PermissionDenied)
+	}
+	if len(orderRepoMock.FindCalls()) != 0 {
+		t.Fatal("orders must not be read for a customer listing the orders of somebody else")
+	}
+
+	response, err := service.ListOrders(ctx, &orderpb.ListOrdersRequest{CustomerId: "c-1"})
+	if err != nil || len(response.Orders) != 1 || response.Orders[0].CustomerId != "c-1" {
+		t.Errorf("Got: %v, %v, Want: the order of c-1", response, err)
+	}
+}
@@ -0,0 +1,93 @@
+package grpcservice
+
+import (
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toOrderMessage(order usecases.Order) *orderpb.Order {
+	adjustments := make([]*orderpb.PriceAdjustment, len(order.Pricing.Adjustments))
+	for idx, adjustment := range order.Pricing.Adjustments {
+		adjustments[idx] = &orderpb.PriceAdjustment{Rule: adjustment.Rule, Amount: adjustment.Amount}
+	}
+	return &orderpb.Order{
+		Id:            order.ID,
+		TotalQuantity: int32(order.TotalQuantity),
+		Products:      toProductMessages(order.Products),
+		DispatchDate:  order.DispatchDate,
+		Status:        order.Status,
+		Value:         order.Value,
+		Pricing: &orderpb.Pricing{
+			Subtotal:    order.Pricing.Subtotal,
+			Adjustments: adjustments,
+			Total:       order.Pricing.Total,
+		},
+		Coupons:    order.Coupons,
+		CustomerId: order.CustomerID,
+		Version:    int32(order.Version),
+	}
+}
+
+func toOrderMessages(orders []usecases.Order) []*orderpb.Order {
+	messages := make([]*orderpb.Order, len(orders))
+	for idx, order := range orders {
+		messages[idx] = toOrderMessage(order)
+	}
+	return messages
+}
+
+func toProductMessage(product usecases.Product) *orderpb.Product {
+	return &orderpb.Product{
+		Id:        product.ID,
+		Name:      product.Name,
+		Category:  product.Category,
+		Price:     product.Price,
+		Sku:       int32(product.SKU),
+		Available: int32(product.Available),
+		Reserved:  int32(product.Reserved),
+		Quantity:  int32(product.Quantity),
+		LineTotal: product.LineTotal,
+		Version:   int32(product.Version),
+	}
+}
+
+func toProductMessages(products []usecases.Product) []*orderpb.Product {
+	messages := make([]*orderpb.Product, len(products))
+	for idx, product := range products {
+		messages[idx] = toProductMessage(product)
+	}
+	return messages
+}
+
+func toStatusTransitionMessages(transitions []usecases.StatusTransition) []*orderpb.StatusTransition {
+	messages := make([]*orderpb.StatusTransition, len(transitions))
+	for idx, transition := range transitions {
+		messages[idx] = &orderpb.StatusTransition{
+			From:  transition.From,
+			To:    transition.To,
+			At:    timestamppb.New(transition.At),
+			Actor: transition.Actor,
+		}
+	}
+	return messages
+}
+
+// toPage reads the page of a list request. A page which is not given, or has no limit, is given the
+// default number of results by the interactors.
+func toPage(page *orderpb.Page) domain.Page {
+	if page == nil {
+		return domain.Page{}
+	}
+	return domain.Page{Limit: int(page.Limit), Cursor: page.Cursor, Descending: page.Descending}
+}
+
+// expectedVersion reads the expected_version of a request, in which 0 makes the change unconditional.
+func expectedVersion(version int32) int {
+	if version == 0 {
+		return domain.AnyVersion
+	}
+	return int(version)
+}
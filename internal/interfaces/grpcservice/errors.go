@@ -0,0 +1,80 @@
+package grpcservice
+
+import (
+	"context"
+	"errors"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// orderError gives the error of an order interactor the code matching the status the web service
+// answers with for it.
+func orderError(err error) error {
+	code := codes.InvalidArgument
+	switch {
+	case errors.Is(err, usecases.ErrOrderNotFound), errors.Is(err, usecases.ErrProductNotFound), errors.Is(err, usecases.ErrCouponNotFound),
+		errors.Is(err, usecases.ErrCustomerNotFound):
+		code = codes.NotFound
+	case errors.Is(err, usecases.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, domain.ErrVersionConflict):
+		code = codes.FailedPrecondition
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(code, err.Error())
+}
+
+// createOrderError gives the error of creating an order the code matching the status the web service
+// answers with for it: the id of the order is generated by the service, so only the customer can be
+// wrong.
+func createOrderError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, usecases.ErrCustomerNotFound):
+		code = codes.NotFound
+	case errors.Is(err, usecases.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(code, err.Error())
+}
+
+// productError gives the error of a product interactor the code matching the status the web service
+// answers with for it.
+func productError(err error) error {
+	var productErr *domain.ProductError
+	code := codes.Internal
+	switch {
+	case errors.Is(err, usecases.ErrProductNotFound):
+		code = codes.NotFound
+	case errors.Is(err, usecases.ErrProductAlreadyExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, domain.ErrVersionConflict):
+		code = codes.FailedPrecondition
+	case errors.As(err, &productErr), errors.Is(err, usecases.ErrInvalidQuery):
+		code = codes.InvalidArgument
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(code, err.Error())
+}
+
+// listError gives the error of a list the code matching the status the web service answers with for
+// it: an invalid query is the only error the caller can fix.
+func listError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, usecases.ErrInvalidQuery):
+		code = codes.InvalidArgument
+	case errors.Is(err, usecases.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,202 @@
+package grpcservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/logging"
+	"sync"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// IdempotencyKeyMetadata lets clients retry a call which changes the shop safely, as the
+// Idempotency-Key header of the web service does: the first answer given to a key is stored and
+// replayed for every retry of the same call with that key.
+const IdempotencyKeyMetadata = "idempotency-key"
+
+// IdempotentReplayedMetadata is set in the header of the answers which are replayed from a previous
+// call.
+const IdempotentReplayedMetadata = "idempotent-replayed"
+
+const maxIdempotencyKeyLength = 255
+
+var idempotentMethods = map[string]bool{
+	orderpb.OrderService_CreateOrder_FullMethodName:       true,
+	orderpb.OrderService_AddProduct_FullMethodName:        true,
+	orderpb.OrderService_UpdateOrderStatus_FullMethodName: true,
+	orderpb.OrderService_SetDispatchDate_FullMethodName:   true,
+	orderpb.OrderService_ApplyCoupon_FullMethodName:       true,
+	orderpb.OrderService_RemoveCoupon_FullMethodName:      true,
+	orderpb.ProductService_CreateProduct_FullMethodName:   true,
+	orderpb.ProductService_ReplaceProduct_FullMethodName:  true,
+	orderpb.ProductService_UpdateProduct_FullMethodName:   true,
+	orderpb.ProductService_DeleteProduct_FullMethodName:   true,
+}
+
+// IdempotencyInterceptor stores the answers given to the calls which carry an idempotency key for
+// ttl, in the repository the web service stores its responses in. The purge of the web service
+// removes the expired ones.
+type IdempotencyInterceptor struct {
+	repository domain.IdempotencyRepository
+	ttl        time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+func NewIdempotencyInterceptor(repository domain.IdempotencyRepository, ttl time.Duration) *IdempotencyInterceptor {
+	return &IdempotencyInterceptor{repository: repository, ttl: ttl, inFlight: make(map[string]bool)}
+}
+
+// Unary replays the stored answer of a call made again with the same idempotency key. A key reused
+// for a different call is rejected with INVALID_ARGUMENT, and a key whose first call is still being
+// handled is rejected with ABORTED so that the client retries later. Keys are scoped by the principal
+// the call is made by, as they are on the web service.
+//
+// Answers with a code which may go away when the call is retried, such as UNAVAILABLE or
+// RESOURCE_EXHAUSTED, are not stored. The answer is stored once the call has committed its changes,
+// in a transaction of its own.
+func (interceptor *IdempotencyInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	keys := md.Get(IdempotencyKeyMetadata)
+	if len(keys) == 0 || keys[0] == "" || !idempotentMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	if len(keys[0]) > maxIdempotencyKeyLength {
+		return nil, status.Error(codes.InvalidArgument, "idempotency key must be at most 255 characters long")
+	}
+	message, ok := req.(proto.Message)
+	if !ok {
+		return handler(ctx, req)
+	}
+	fingerprint, err := callFingerprint(info.FullMethod, message)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	principal, _ := usecases.PrincipalFrom(ctx)
+	key := "grpc " + string(principal.Role) + ":" + principal.Subject + "\n" + keys[0]
+
+	record, inFlight := interceptor.begin(ctx, key)
+	if inFlight {
+		return nil, status.Error(codes.Aborted, "a call with the same idempotency key is still being processed")
+	}
+	if record.Key != "" {
+		if record.Fingerprint != fingerprint {
+			return nil, status.Error(codes.InvalidArgument, "idempotency key has already been used for a different call")
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedMetadata, "true"))
+		return replayCall(record)
+	}
+	defer interceptor.end(key)
+
+	resp, callErr := handler(ctx, req)
+	if !isStoredCode(status.Code(callErr)) {
+		return resp, callErr
+	}
+	if err := interceptor.store(ctx, key, fingerprint, resp, callErr); err != nil {
+		logging.FromContext(ctx).Error("could not store the answer of the call", "error", err)
+	}
+	return resp, callErr
+}
+
+// store keeps the answer of the call: the response, wrapped in an Any so that it can be read back
+// whichever its type, or the status of the error.
+func (interceptor *IdempotencyInterceptor) store(ctx context.Context, key, fingerprint string, resp interface{}, callErr error) error {
+	var answer proto.Message = status.Convert(callErr).Proto()
+	if callErr == nil {
+		var err error
+		if answer, err = anypb.New(resp.(proto.Message)); err != nil {
+			return err
+		}
+	}
+	body, err := proto.Marshal(answer)
+	if err != nil {
+		return err
+	}
+	return interceptor.repository.Store(ctx, domain.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		StatusCode:  int(status.Code(callErr)),
+		Body:        body,
+		ExpiresAt:   time.Now().UTC().Add(interceptor.ttl),
+	})
+}
+
+// begin returns the live record stored for the key, or leaves the key marked as in flight when there
+// is none, as the middleware of the web service does.
+func (interceptor *IdempotencyInterceptor) begin(ctx context.Context, key string) (domain.IdempotencyRecord, bool) {
+	interceptor.mu.Lock()
+	if interceptor.inFlight[key] {
+		interceptor.mu.Unlock()
+		return domain.IdempotencyRecord{}, true
+	}
+	interceptor.inFlight[key] = true
+	interceptor.mu.Unlock()
+
+	record := interceptor.repository.FindByKey(ctx, key)
+	if record.Key != "" && !record.IsExpired(time.Now()) {
+		interceptor.end(key)
+		return record, false
+	}
+	return domain.IdempotencyRecord{}, false
+}
+
+func (interceptor *IdempotencyInterceptor) end(key string) {
+	interceptor.mu.Lock()
+	defer interceptor.mu.Unlock()
+	delete(interceptor.inFlight, key)
+}
+
+// isStoredCode reports whether an answer with the code is given again to a retry, rather than the
+// call being made again.
+func isStoredCode(code codes.Code) bool {
+	switch code {
+	case codes.OK, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied, codes.FailedPrecondition:
+		return true
+	}
+	return false
+}
+
+// callFingerprint identifies a call by its method and its request, so that a key reused for a
+// different call can be told apart from a retry.
+func callFingerprint(method string, req proto.Message) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(method + "\n"))
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// replayCall gives the answer stored in the record: the response of the call, or its error.
+func replayCall(record domain.IdempotencyRecord) (interface{}, error) {
+	if codes.Code(record.StatusCode) != codes.OK {
+		answer := &spb.Status{}
+		if err := proto.Unmarshal(record.Body, answer); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return nil, status.ErrorProto(answer)
+	}
+	answer := &anypb.Any{}
+	if err := proto.Unmarshal(record.Body, answer); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp, err := answer.UnmarshalNew()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return resp, nil
+}
@@ -0,0 +1,104 @@
+package grpcservice_test
+
+import (
+	"context"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/grpcservice"
+	"simple-order-service/internal/usecases"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func newIdempotencyRepoMock() *domain.IdempotencyRepositoryMock {
+	var mu sync.Mutex
+	records := make(map[string]domain.IdempotencyRecord)
+	return &domain.IdempotencyRepositoryMock{
+		StoreFunc: func(ctx context.Context, record domain.IdempotencyRecord) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records[record.Key] = record
+			return nil
+		},
+		FindByKeyFunc: func(ctx context.Context, key string) domain.IdempotencyRecord {
+			mu.Lock()
+			defer mu.Unlock()
+			return records[key]
+		},
+	}
+}
+
+func idempotentCall(key string, principal domain.Principal) context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcservice.IdempotencyKeyMetadata, key))
+	return usecases.WithPrincipal(ctx, principal)
+}
+
+func TestIdempotencyInterceptor_ReplaysAnswerOfRetry(t *testing.T) {
+	interceptor := grpcservice.NewIdempotencyInterceptor(newIdempotencyRepoMock(), time.Hour)
+	info := &grpc.UnaryServerInfo{FullMethod: orderpb.OrderService_UpdateOrderStatus_FullMethodName}
+	customer := domain.Principal{Subject: "c-1", Role: domain.RoleCustomer}
+	req := &orderpb.UpdateOrderStatusRequest{OrderId: "1", Status: "placed"}
+
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls += 1
+		return &orderpb.Order{Id: "1", Status: "placed", Version: int32(calls)}, nil
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := interceptor.Unary(idempotentCall("key-1", customer), req, info, handler)
+		want := &orderpb.Order{Id: "1", Status: "placed", Version: 1}
+		if err != nil || !proto.Equal(resp.(proto.Message), want) {
+			t.Errorf("Got: %v, %v, Want: %v, nil", resp, err, want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Got: %v calls, Want: 1", calls)
+	}
+
+	different := &orderpb.UpdateOrderStatusRequest{OrderId: "1", Status: "cancelled"}
+	if _, err := interceptor.Unary(idempotentCall("key-1", customer), different, info, handler); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Got: %v, Want: %v", status.Code(err), codes.InvalidArgument)
+	}
+	other := domain.Principal{Subject: "c-2", Role: domain.RoleCustomer}
+	if _, err := interceptor.Unary(idempotentCall("key-1", other), req, info, handler); err != nil || calls != 2 {
+		t.Errorf("Got: %v, %v calls, Want: nil, 2 calls", err, calls)
+	}
+}
+
+func TestIdempotencyInterceptor_ReplaysOnlyErrorsWhichDoNotGoAway(t *testing.T) {
+	interceptor := grpcservice.NewIdempotencyInterceptor(newIdempotencyRepoMock(), time.Hour)
+	info := &grpc.UnaryServerInfo{FullMethod: orderpb.OrderService_AddProduct_FullMethodName}
+	customer := domain.Principal{Subject: "c-1", Role: domain.RoleCustomer}
+
+	tests := []struct {
+		key       string
+		err       error
+		wantCalls int
+	}{
+		{"not-found", status.Error(codes.NotFound, "order not found"), 1},
+		{"unavailable", status.Error(codes.Unavailable, "the server is shutting down"), 2},
+	}
+	for _, test := range tests {
+		calls := 0
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls += 1
+			return nil, test.err
+		}
+		for i := 0; i < 2; i++ {
+			_, err := interceptor.Unary(idempotentCall(test.key, customer), &orderpb.AddProductRequest{OrderId: "1", ProductId: "123"}, info, handler)
+			if status.Code(err) != status.Code(test.err) || status.Convert(err).Message() != status.Convert(test.err).Message() {
+				t.Errorf("%s: Got: %v, Want: %v", test.key, err, test.err)
+			}
+		}
+		if calls != test.wantCalls {
+			t.Errorf("%s: Got: %v calls, Want: %v", test.key, calls, test.wantCalls)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package grpcservice
+
+import (
+	"context"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type OrderInteractor interface {
+	Create(ctx context.Context, customerID string) (usecases.Order, error)
+	Products(ctx context.Context, orderId string) ([]usecases.Product, error)
+	Add(ctx context.Context, orderId, productId string, quantity int, expectedVersion int) error
+	GetDetails(ctx context.Context, orderId string) (usecases.Order, error)
+	GetDetailsAsOf(ctx context.Context, orderId string, at time.Time) (usecases.Order, error)
+	List(ctx context.Context, query domain.OrderQuery) ([]usecases.Order, string, error)
+	History(ctx context.Context, orderId string) ([]usecases.StatusTransition, error)
+	UpdateDispatchDate(ctx context.Context, orderId, date string, expectedVersion int) error
+	UpdateOrderStatus(ctx context.Context, orderId string, status domain.OrderStatus, actor string, expectedVersion int) (int, error)
+	ApplyCoupon(ctx context.Context, orderId, code string, expectedVersion int) error
+	RemoveCoupon(ctx context.Context, orderId, code string, expectedVersion int) error
+}
+
+const watchBatchSize = 100
+
+// OrderService serves the orders. The methods which change an order answer with the order as it is
+// once changed.
+type OrderService struct {
+	orderpb.UnimplementedOrderServiceServer
+	orderInteractor OrderInteractor
+	feedRepository  domain.FeedRepository
+	streams         context.Context
+}
+
+// NewOrderService builds an OrderService whose watches end when the streams context is done.
+func NewOrderService(orderInteractor OrderInteractor, feedRepository domain.FeedRepository, streams context.Context) *OrderService {
+	return &OrderService{orderInteractor: orderInteractor, feedRepository: feedRepository, streams: streams}
+}
+
+func (service *OrderService) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*orderpb.Order, error) {
+	order, err := service.orderInteractor.Create(ctx, req.CustomerId)
+	if err != nil {
+		return nil, createOrderError(err)
+	}
+	return toOrderMessage(order), nil
+}
+
+func (service *OrderService) GetOrder(ctx context.Context, req *orderpb.GetOrderRequest) (*orderpb.Order, error) {
+	var order usecases.Order
+	var err error
+	if req.AsOf != nil {
+		order, err = service.orderInteractor.GetDetailsAsOf(ctx, req.Id, req.AsOf.AsTime())
+	} else {
+		order, err = service.orderInteractor.GetDetails(ctx, req.Id)
+	}
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return toOrderMessage(order), nil
+}
+
+func (service *OrderService) ListOrders(ctx context.Context, req *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+	query := domain.OrderQuery{Page: toPage(req.Page), Status: domain.OrderStatus(req.Status), CustomerID: req.CustomerId}
+	orders, nextCursor, err := service.orderInteractor.List(ctx, query)
+	if err != nil {
+		return nil, listError(err)
+	}
+	return &orderpb.ListOrdersResponse{Orders: toOrderMessages(orders), NextCursor: nextCursor}, nil
+}
+
+func (service *OrderService) ListOrderedProducts(ctx context.Context, req *orderpb.ListOrderedProductsRequest) (*orderpb.ListOrderedProductsResponse, error) {
+	products, err := service.orderInteractor.Products(ctx, req.OrderId)
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return &orderpb.ListOrderedProductsResponse{Products: toProductMessages(products)}, nil
+}
+
+// AddProduct adds one unit of the product when no quantity is given, as the web service does.
+func (service *OrderService) AddProduct(ctx context.Context, req *orderpb.AddProductRequest) (*orderpb.Order, error) {
+	quantity := int(req.Quantity)
+	if quantity == 0 {
+		quantity = 1
+	}
+	err := service.orderInteractor.Add(ctx, req.OrderId, req.ProductId, quantity, expectedVersion(req.ExpectedVersion))
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return service.getOrder(ctx, req.OrderId)
+}
+
+func (service *OrderService) GetOrderHistory(ctx context.Context, req *orderpb.GetOrderHistoryRequest) (*orderpb.GetOrderHistoryResponse, error) {
+	transitions, err := service.orderInteractor.History(ctx, req.OrderId)
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return &orderpb.GetOrderHistoryResponse{Transitions: toStatusTransitionMessages(transitions)}, nil
+}
+
+func (service *OrderService) UpdateOrderStatus(ctx context.Context, req *orderpb.UpdateOrderStatusRequest) (*orderpb.Order, error) {
+	_, err := service.orderInteractor.UpdateOrderStatus(ctx, req.OrderId, domain.OrderStatus(req.Status), callActor(ctx), expectedVersion(req.ExpectedVersion))
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return service.getOrder(ctx, req.OrderId)
+}
+
+func (service *OrderService) SetDispatchDate(ctx context.Context, req *orderpb.SetDispatchDateRequest) (*orderpb.Order, error) {
+	err := service.orderInteractor.UpdateDispatchDate(ctx, req.OrderId, req.DispatchDate, expectedVersion(req.ExpectedVersion))
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return service.getOrder(ctx, req.OrderId)
+}
+
+func (service *OrderService) ApplyCoupon(ctx context.Context, req *orderpb.ApplyCouponRequest) (*orderpb.Order, error) {
+	if err := service.orderInteractor.ApplyCoupon(ctx, req.OrderId, req.Code, expectedVersion(req.ExpectedVersion)); err != nil {
+		return nil, orderError(err)
+	}
+	return service.getOrder(ctx, req.OrderId)
+}
+
+func (service *OrderService) RemoveCoupon(ctx context.Context, req *orderpb.RemoveCouponRequest) (*orderpb.Order, error) {
+	if err := service.orderInteractor.RemoveCoupon(ctx, req.OrderId, req.Code, expectedVersion(req.ExpectedVersion)); err != nil {
+		return nil, orderError(err)
+	}
+	return service.getOrder(ctx, req.OrderId)
+}
+
+// WatchOrderStatus follows the feed of the service, which the web service streams at /events, for the
+// changes to the status of the order.
+func (service *OrderService) WatchOrderStatus(req *orderpb.WatchOrderStatusRequest, stream orderpb.OrderService_WatchOrderStatusServer) error {
+	ctx := stream.Context()
+
+	// Watch and read the last sequence before reading the order, so that no change committed in
+	// between is missed. Such a change is sent again after the first message.
+	notifications, stop := service.feedRepository.Watch()
+	defer stop()
+	lastSequence := service.feedRepository.LastSequence(ctx)
+
+	order, err := service.orderInteractor.GetDetails(ctx, req.OrderId)
+	if err != nil {
+		return orderError(err)
+	}
+	err = stream.Send(&orderpb.OrderStatusChange{
+		OrderId:  order.ID,
+		Status:   order.Status,
+		At:       timestamppb.Now(),
+		Sequence: lastSequence,
+	})
+	if err != nil || domain.OrderStatus(order.Status).IsTerminal() {
+		return err
+	}
+
+	for {
+		events := service.feedRepository.Since(ctx, lastSequence, watchBatchSize)
+		for _, event := range events {
+			lastSequence = event.Sequence
+			if event.Type != domain.FeedOrderStatusChanged || event.OrderID != order.ID {
+				continue
+			}
+			err := stream.Send(&orderpb.OrderStatusChange{
+				OrderId:        event.OrderID,
+				Status:         string(event.Status),
+				PreviousStatus: string(event.PreviousStatus),
+				At:             timestamppb.New(event.At),
+				Sequence:       event.Sequence,
+			})
+			if err != nil || event.Status.IsTerminal() {
+				return err
+			}
+		}
+		if len(events) == watchBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-service.streams.Done():
+			return status.Error(codes.Unavailable, "the server is shutting down. watch the order again")
+		case <-notifications:
+		}
+	}
+}
+
+func (service *OrderService) getOrder(ctx context.Context, orderID string) (*orderpb.Order, error) {
+	order, err := service.orderInteractor.GetDetails(ctx, orderID)
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return toOrderMessage(order), nil
+}
+
+// callActor is the subject of the principal the call is made by, which is recorded in the status
+// history of the order.
+func callActor(ctx context.Context) string {
+	principal, _ := usecases.PrincipalFrom(ctx)
+	return principal.Subject
+}
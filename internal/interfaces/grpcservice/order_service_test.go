@@ -0,0 +1,245 @@
+package grpcservice_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/grpcservice"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/ulid"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeOrderInteractor answers every call with its order, or fails with its error.
+type fakeOrderInteractor struct {
+	order usecases.Order
+	err   error
+}
+
+func (fake fakeOrderInteractor) Create(ctx context.Context, customerID string) (usecases.Order, error) {
+	return fake.order, fake.err
+}
+
+func (fake fakeOrderInteractor) Products(ctx context.Context, orderId string) ([]usecases.Product, error) {
+	return fake.order.Products, fake.err
+}
+
+func (fake fakeOrderInteractor) Add(ctx context.Context, orderId, productId string, quantity int, expectedVersion int) error {
+	return fake.err
+}
+
+func (fake fakeOrderInteractor) GetDetails(ctx context.Context, orderId string) (usecases.Order, error) {
+	return fake.order, fake.err
+}
+
+func (fake fakeOrderInteractor) GetDetailsAsOf(ctx context.Context, orderId string, at time.Time) (usecases.Order, error) {
+	return fake.order, fake.err
+}
+
+func (fake fakeOrderInteractor) List(ctx context.Context, query domain.OrderQuery) ([]usecases.Order, string, error) {
+	return []usecases.Order{fake.order}, "", fake.err
+}
+
+func (fake fakeOrderInteractor) History(ctx context.Context, orderId string) ([]usecases.StatusTransition, error) {
+	return nil, fake.err
+}
+
+func (fake fakeOrderInteractor) UpdateDispatchDate(ctx context.Context, orderId, date string, expectedVersion int) error {
+	return fake.err
+}
+
+func (fake fakeOrderInteractor) UpdateOrderStatus(ctx context.Context, orderId string, status domain.OrderStatus, actor string, expectedVersion int) (int, error) {
+	return fake.order.Version, fake.err
+}
+
+func (fake fakeOrderInteractor) ApplyCoupon(ctx context.Context, orderId, code string, expectedVersion int) error {
+	return fake.err
+}
+
+func (fake fakeOrderInteractor) RemoveCoupon(ctx context.Context, orderId, code string, expectedVersion int) error {
+	return fake.err
+}
+
+func TestOrderService_GivesErrorsTheCodesOfTheWebServiceStatuses(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{usecases.ErrOrderNotFound, codes.NotFound},
+		{fmt.Errorf("%w: 42", usecases.ErrProductNotFound), codes.NotFound},
+		{fmt.Errorf("%w: save10", usecases.ErrCouponNotFound), codes.NotFound},
+		{usecases.ErrCustomerNotFound, codes.NotFound},
+		{usecases.ErrForbidden, codes.PermissionDenied},
+		{fmt.Errorf("%w: order is at version 3", domain.ErrVersionConflict), codes.FailedPrecondition},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{context.Canceled, codes.Canceled},
+		{&domain.OrderError{Err: domain.ErrInvalidOrderStatus}, codes.InvalidArgument},
+	}
+	for _, test := range tests {
+		service := grpcservice.NewOrderService(fakeOrderInteractor{err: test.err}, &domain.FeedRepositoryMock{}, context.Background())
+		if _, err := service.GetOrder(context.Background(), &orderpb.GetOrderRequest{Id: "1"}); status.Code(err) != test.want {
+			t.Errorf("%v: Got: %v, Want: %v", test.err, status.Code(err), test.want)
+		}
+	}
+}
+
+func TestOrderService_AnswersCreateOrderFailuresOfTheServiceWithInternal(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{usecases.ErrCustomerNotFound, codes.NotFound},
+		{usecases.ErrForbidden, codes.PermissionDenied},
+		{ulid.ErrOverflow, codes.Internal},
+		{errors.New("database is closed"), codes.Internal},
+	}
+	for _, test := range tests {
+		service := grpcservice.NewOrderService(fakeOrderInteractor{err: test.err}, &domain.FeedRepositoryMock{}, context.Background())
+		if _, err := service.CreateOrder(context.Background(), &orderpb.CreateOrderRequest{}); status.Code(err) != test.want {
+			t.Errorf("%v: Got: %v, Want: %v", test.err, status.Code(err), test.want)
+		}
+	}
+}
+
+// fakeProductInteractor fails every call with its error.
+type fakeProductInteractor struct {
+	err error
+}
+
+func (fake fakeProductInteractor) GetDetails(ctx context.Context, productId string) (usecases.Product, error) {
+	return usecases.Product{}, fake.err
+}
+
+func (fake fakeProductInteractor) List(ctx context.Context, query domain.ProductQuery) ([]usecases.Product, string, error) {
+	return nil, "", fake.err
+}
+
+func (fake fakeProductInteractor) Create(ctx context.Context, input usecases.Product) (usecases.Product, error) {
+	return usecases.Product{}, fake.err
+}
+
+func (fake fakeProductInteractor) Replace(ctx context.Context, productId string, input usecases.Product, expectedVersion int) (usecases.Product, error) {
+	return usecases.Product{}, fake.err
+}
+
+func (fake fakeProductInteractor) Update(ctx context.Context, productId string, update usecases.ProductUpdate, expectedVersion int) (usecases.Product, error) {
+	return usecases.Product{}, fake.err
+}
+
+func (fake fakeProductInteractor) Delete(ctx context.Context, productId string, expectedVersion int) error {
+	return fake.err
+}
+
+func TestProductService_GivesErrorsTheCodesOfTheWebServiceStatuses(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{usecases.ErrProductNotFound, codes.NotFound},
+		{usecases.ErrProductAlreadyExists, codes.AlreadyExists},
+		{domain.ErrVersionConflict, codes.FailedPrecondition},
+		{&domain.ProductError{Err: errors.New("price must be greater than 0")}, codes.InvalidArgument},
+		{fmt.Errorf("%w: limit must be at most 100", usecases.ErrInvalidQuery), codes.InvalidArgument},
+		{context.Canceled, codes.Canceled},
+		{errors.New("database is closed"), codes.Internal},
+	}
+	for _, test := range tests {
+		service := grpcservice.NewProductService(fakeProductInteractor{err: test.err})
+		if _, err := service.GetProduct(context.Background(), &orderpb.GetProductRequest{Id: "1"}); status.Code(err) != test.want {
+			t.Errorf("%v: Got: %v, Want: %v", test.err, status.Code(err), test.want)
+		}
+	}
+}
+
+// watchStream collects the messages sent on a watch.
+type watchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *orderpb.OrderStatusChange
+}
+
+func (stream *watchStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *watchStream) Send(change *orderpb.OrderStatusChange) error {
+	stream.sent <- change
+	return nil
+}
+
+func newWatchFeed(events []domain.FeedEvent) (*domain.FeedRepositoryMock, chan struct{}) {
+	notifications := make(chan struct{}, 1)
+	return &domain.FeedRepositoryMock{
+		WatchFunc: func() (<-chan struct{}, func()) {
+			return notifications, func() {}
+		},
+		LastSequenceFunc: func(ctx context.Context) uint64 {
+			return 0
+		},
+		SinceFunc: func(ctx context.Context, sequence uint64, limit int) []domain.FeedEvent {
+			var since []domain.FeedEvent
+			for _, event := range events {
+				if event.Sequence > sequence {
+					since = append(since, event)
+				}
+			}
+			return since
+		},
+	}, notifications
+}
+
+func TestWatchOrderStatus_EndsOnTerminalStatus(t *testing.T) {
+	feedRepoMock, _ := newWatchFeed([]domain.FeedEvent{
+		{Sequence: 1, Type: domain.FeedOrderStatusChanged, OrderID: "1", Status: domain.OrderPlaced, PreviousStatus: domain.OrderOpen},
+		{Sequence: 2, Type: domain.FeedOrderStatusChanged, OrderID: "2", Status: domain.OrderPlaced, PreviousStatus: domain.OrderOpen},
+		{Sequence: 3, Type: domain.FeedOrderStatusChanged, OrderID: "1", Status: domain.OrderCancelled, PreviousStatus: domain.OrderPlaced},
+	})
+	order := usecases.Order{ID: "1", Status: string(domain.OrderOpen)}
+	service := grpcservice.NewOrderService(fakeOrderInteractor{order: order}, feedRepoMock, context.Background())
+
+	stream := &watchStream{ctx: context.Background(), sent: make(chan *orderpb.OrderStatusChange, 10)}
+	if err := service.WatchOrderStatus(&orderpb.WatchOrderStatusRequest{OrderId: "1"}, stream); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	close(stream.sent)
+
+	var statuses []string
+	for change := range stream.sent {
+		statuses = append(statuses, change.Status)
+	}
+	want := []string{"open", "placed", "cancelled"}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Errorf("Got: %v, Want: %v", statuses, want)
+	}
+}
+
+func TestWatchOrderStatus_EndsWhenStreamsAreEnded(t *testing.T) {
+	feedRepoMock, _ := newWatchFeed(nil)
+	order := usecases.Order{ID: "1", Status: string(domain.OrderOpen)}
+	streams, endStreams := context.WithCancel(context.Background())
+	service := grpcservice.NewOrderService(fakeOrderInteractor{order: order}, feedRepoMock, streams)
+
+	stream := &watchStream{ctx: context.Background(), sent: make(chan *orderpb.OrderStatusChange, 10)}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- service.WatchOrderStatus(&orderpb.WatchOrderStatusRequest{OrderId: "1"}, stream)
+	}()
+	<-stream.sent
+	endStreams()
+
+	select {
+	case err := <-errs:
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("Got: %v, Want: %v", status.Code(err), codes.Unavailable)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the watch must end once the streams are ended")
+	}
+}
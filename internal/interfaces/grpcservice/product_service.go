@@ -0,0 +1,87 @@
+package grpcservice
+
+import (
+	"context"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+)
+
+type ProductInteractor interface {
+	GetDetails(ctx context.Context, productId string) (usecases.Product, error)
+	List(ctx context.Context, query domain.ProductQuery) ([]usecases.Product, string, error)
+	Create(ctx context.Context, input usecases.Product) (usecases.Product, error)
+	Replace(ctx context.Context, productId string, input usecases.Product, expectedVersion int) (usecases.Product, error)
+	Update(ctx context.Context, productId string, update usecases.ProductUpdate, expectedVersion int) (usecases.Product, error)
+	Delete(ctx context.Context, productId string, expectedVersion int) error
+}
+
+// ProductService serves the catalogue.
+type ProductService struct {
+	orderpb.UnimplementedProductServiceServer
+	productInteractor ProductInteractor
+}
+
+func NewProductService(productInteractor ProductInteractor) *ProductService {
+	return &ProductService{productInteractor: productInteractor}
+}
+
+func (service *ProductService) GetProduct(ctx context.Context, req *orderpb.GetProductRequest) (*orderpb.Product, error) {
+	product, err := service.productInteractor.GetDetails(ctx, req.Id)
+	if err != nil {
+		return nil, productError(err)
+	}
+	return toProductMessage(product), nil
+}
+
+func (service *ProductService) ListProducts(ctx context.Context, req *orderpb.ListProductsRequest) (*orderpb.ListProductsResponse, error) {
+	query := domain.ProductQuery{Page: toPage(req.Page), Category: domain.ProductCategory(req.Category), MinPrice: req.MinPrice}
+	products, nextCursor, err := service.productInteractor.List(ctx, query)
+	if err != nil {
+		return nil, listError(err)
+	}
+	return &orderpb.ListProductsResponse{Products: toProductMessages(products), NextCursor: nextCursor}, nil
+}
+
+func (service *ProductService) CreateProduct(ctx context.Context, req *orderpb.CreateProductRequest) (*orderpb.Product, error) {
+	product, err := service.productInteractor.Create(ctx, usecases.Product{
+		ID:       req.Id,
+		Name:     req.Name,
+		Category: req.Category,
+		Price:    req.Price,
+		SKU:      int(req.Sku),
+	})
+	if err != nil {
+		return nil, productError(err)
+	}
+	return toProductMessage(product), nil
+}
+
+func (service *ProductService) ReplaceProduct(ctx context.Context, req *orderpb.ReplaceProductRequest) (*orderpb.Product, error) {
+	input := usecases.Product{Name: req.Name, Category: req.Category, Price: req.Price, SKU: int(req.Sku)}
+	product, err := service.productInteractor.Replace(ctx, req.Id, input, expectedVersion(req.ExpectedVersion))
+	if err != nil {
+		return nil, productError(err)
+	}
+	return toProductMessage(product), nil
+}
+
+func (service *ProductService) UpdateProduct(ctx context.Context, req *orderpb.UpdateProductRequest) (*orderpb.Product, error) {
+	update := usecases.ProductUpdate{Name: req.Name, Category: req.Category, Price: req.Price}
+	if req.Sku != nil {
+		sku := int(*req.Sku)
+		update.SKU = &sku
+	}
+	product, err := service.productInteractor.Update(ctx, req.Id, update, expectedVersion(req.ExpectedVersion))
+	if err != nil {
+		return nil, productError(err)
+	}
+	return toProductMessage(product), nil
+}
+
+func (service *ProductService) DeleteProduct(ctx context.Context, req *orderpb.DeleteProductRequest) (*orderpb.DeleteProductResponse, error) {
+	if err := service.productInteractor.Delete(ctx, req.Id, expectedVersion(req.ExpectedVersion)); err != nil {
+		return nil, productError(err)
+	}
+	return &orderpb.DeleteProductResponse{}, nil
+}
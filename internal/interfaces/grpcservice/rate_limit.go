@@ -0,0 +1,83 @@
+package grpcservice
+
+import (
+	"context"
+	"math"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/metrics"
+	"simple-order-service/pkg/ratelimit"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var grpcRateLimitedRequests = metrics.Default.NewCounterVec("grpc_rate_limited_requests_total",
+	"gRPC calls rejected because the client went over its rate limit, by group of methods.", "group")
+
+// RateLimits holds the limiters of the groups of methods: reading the catalogue, and changing orders.
+// They are the limiters of the matching groups of routes of the web service, so that a client is held
+// to the same limits whichever API it calls. A nil limiter does not limit the calls.
+type RateLimits struct {
+	CatalogueReads *ratelimit.Limiter
+	OrderWrites    *ratelimit.Limiter
+}
+
+var methodRateLimitGroups = map[string]string{
+	orderpb.ProductService_GetProduct_FullMethodName:      "catalogue_reads",
+	orderpb.ProductService_ListProducts_FullMethodName:    "catalogue_reads",
+	orderpb.OrderService_CreateOrder_FullMethodName:       "order_writes",
+	orderpb.OrderService_AddProduct_FullMethodName:        "order_writes",
+	orderpb.OrderService_UpdateOrderStatus_FullMethodName: "order_writes",
+	orderpb.OrderService_SetDispatchDate_FullMethodName:   "order_writes",
+	orderpb.OrderService_ApplyCoupon_FullMethodName:       "order_writes",
+	orderpb.OrderService_RemoveCoupon_FullMethodName:      "order_writes",
+}
+
+func (rateLimits RateLimits) limiter(group string) *ratelimit.Limiter {
+	switch group {
+	case "catalogue_reads":
+		return rateLimits.CatalogueReads
+	case "order_writes":
+		return rateLimits.OrderWrites
+	}
+	return nil
+}
+
+// Unary rejects the calls of a client which went over its limit with RESOURCE_EXHAUSTED, telling it
+// when to retry in the retry-after header. The x-ratelimit headers tell the client how many calls it
+// may make, how many it has left and in how many seconds it will have all of them again, as the web
+// service does. It runs after the authentication, since clients are told apart by their principal.
+func (rateLimits RateLimits) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	group := methodRateLimitGroups[info.FullMethod]
+	limiter := rateLimits.limiter(group)
+	principal, ok := usecases.PrincipalFrom(ctx)
+	if limiter == nil || !ok {
+		return handler(ctx, req)
+	}
+
+	decision := limiter.Allow(ratelimit.PrincipalKey(string(principal.Role), principal.Subject), time.Now())
+	header := metadata.Pairs(
+		"x-ratelimit-limit", strconv.Itoa(decision.Limit),
+		"x-ratelimit-remaining", strconv.Itoa(decision.Remaining),
+		"x-ratelimit-reset", strconv.Itoa(ceilSeconds(decision.Reset)),
+	)
+	if decision.Allowed {
+		grpc.SetHeader(ctx, header)
+		return handler(ctx, req)
+	}
+
+	grpcRateLimitedRequests.Inc(group)
+	retryAfter := ceilSeconds(decision.RetryAfter)
+	header.Set("retry-after", strconv.Itoa(retryAfter))
+	grpc.SetHeader(ctx, header)
+	return nil, status.Error(codes.ResourceExhausted, "too many requests. retry in "+strconv.Itoa(retryAfter)+" seconds")
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,48 @@
+package grpcservice_test
+
+import (
+	"context"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/grpcservice"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/ratelimit"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimits_HoldEveryPrincipalToItsLimit(t *testing.T) {
+	orderWrites := ratelimit.NewLimiter(0.001, 2, 10)
+	rateLimits := grpcservice.RateLimits{OrderWrites: orderWrites}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	call := func(principal domain.Principal, method string) codes.Code {
+		ctx := usecases.WithPrincipal(context.Background(), principal)
+		_, err := rateLimits.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return status.Code(err)
+	}
+
+	customer := domain.Principal{Subject: "c-1", Role: domain.RoleCustomer}
+	want := []codes.Code{codes.OK, codes.OK, codes.ResourceExhausted}
+	for _, code := range want {
+		if got := call(customer, orderpb.OrderService_AddProduct_FullMethodName); got != code {
+			t.Errorf("Got: %v, Want: %v", got, code)
+		}
+	}
+	if got := call(domain.Principal{Subject: "c-2", Role: domain.RoleCustomer}, orderpb.OrderService_AddProduct_FullMethodName); got != codes.OK {
+		t.Errorf("another principal: Got: %v, Want: %v", got, codes.OK)
+	}
+	if got := call(customer, orderpb.OrderService_GetOrder_FullMethodName); got != codes.OK {
+		t.Errorf("a method which is not limited: Got: %v, Want: %v", got, codes.OK)
+	}
+
+	// The limiter is shared with the web service, which takes from the same bucket.
+	if decision := orderWrites.Allow(ratelimit.PrincipalKey("customer", "c-2"), time.Now()); decision.Remaining != 0 {
+		t.Errorf("Got: %v remaining, Want: 0", decision.Remaining)
+	}
+}
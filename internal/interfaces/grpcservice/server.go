@@ -0,0 +1,167 @@
+package grpcservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/logging"
+	"simple-order-service/pkg/metrics"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDMetadata carries the id of a call. An id sent by the client is kept so that a call can be
+// followed across services; one is generated otherwise. The id is sent back in the header of the
+// response either way.
+const RequestIDMetadata = "x-request-id"
+
+const maxRequestIDLength = 128
+
+var (
+	grpcRequests = metrics.Default.NewCounterVec("grpc_requests_total",
+		"gRPC calls handled, by method and status code.", "method", "code")
+	grpcRequestDuration = metrics.Default.NewHistogramVec("grpc_request_duration_seconds",
+		"Latency of the gRPC calls, by method. Streams are observed once they end.", metrics.DefaultBuckets, "method")
+)
+
+// Server serves the gRPC API of the order service, with the same interactors as the web service.
+type Server struct {
+	server     *grpc.Server
+	endStreams context.CancelFunc
+}
+
+// NewServer registers the services of the gRPC API. Every call is given a request id and a logger,
+// counted in the metrics, and authenticated and authorized by the role of its principal. The unary
+// calls are then validated, replayed when they are retried with an idempotency key, and held to the
+// rate limits of their client, in the order in which the web service does the same for its requests.
+func NewServer(orderInteractor OrderInteractor, productInteractor ProductInteractor, feedRepository domain.FeedRepository, authenticator Authenticator, idempotency *IdempotencyInterceptor, rateLimits RateLimits, limits domain.Limits) *Server {
+	streams, endStreams := context.WithCancel(context.Background())
+	auth := NewAuthInterceptor(authenticator)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestIDUnaryInterceptor, auth.Unary, NewValidationInterceptor(limits).Unary, idempotency.Unary, rateLimits.Unary),
+		grpc.ChainStreamInterceptor(requestIDStreamInterceptor, auth.Stream),
+	)
+	orderpb.RegisterOrderServiceServer(server, NewOrderService(orderInteractor, feedRepository, streams))
+	orderpb.RegisterProductServiceServer(server, NewProductService(productInteractor))
+	return &Server{server: server, endStreams: endStreams}
+}
+
+// Start serves the API on addr until the context is done, then stops accepting calls and waits up to
+// shutdownTimeout for the calls in flight to complete. Watches are ended as soon as the shutdown
+// starts, since they only end on their own once their order is completed or cancelled.
+func (server *Server) Start(ctx context.Context, addr string, shutdownTimeout time.Duration) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		logging.Default().Info("starting gRPC server", "addr", addr)
+		errs <- server.server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	logging.Default().Info("shutting down gRPC server")
+	server.endStreams()
+	stopped := make(chan struct{})
+	go func() {
+		server.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		server.server.Stop()
+	}
+	return nil
+}
+
+func requestIDUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, logger := withRequestID(ctx, info.FullMethod)
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(logger, info.FullMethod, err, time.Since(start))
+	return resp, err
+}
+
+func requestIDStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, logger := withRequestID(stream.Context(), info.FullMethod)
+	start := time.Now()
+	err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	logCall(logger, info.FullMethod, err, time.Since(start))
+	return err
+}
+
+// withRequestID passes a logger carrying the request id and the method of the call down to the
+// interactors and repositories, as the web service does for its requests.
+func withRequestID(ctx context.Context, method string) (context.Context, *logging.Logger) {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadata); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if !isValidRequestID(requestID) {
+		requestID = newRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, requestID))
+
+	logger := logging.FromContext(ctx).With("request_id", requestID, "method", method)
+	return logging.NewContext(ctx, logger), logger
+}
+
+// logCall logs a call once it has been handled, and counts it in the metrics.
+func logCall(logger *logging.Logger, method string, err error, duration time.Duration) {
+	code := status.Code(err)
+	grpcRequests.Inc(method, code.String())
+	grpcRequestDuration.Observe(duration.Seconds(), method)
+	if err != nil {
+		logger.Warn("request failed", "error", err)
+	}
+	logger.Info("request handled", "code", code.String(), "duration_ms", duration.Milliseconds())
+}
+
+// isValidRequestID accepts the ids which can be written to the logs and echoed in metadata as they
+// are: printable ASCII without spaces, of a bounded length.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, char := range requestID {
+		if char <= ' ' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
+
+// contextStream is a stream whose context carries the values the interceptors add to the context of
+// the call.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *contextStream) Context() context.Context {
+	return stream.ctx
+}
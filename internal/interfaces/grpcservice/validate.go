@@ -0,0 +1,177 @@
+package grpcservice
+
+import (
+	"context"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ValidationInterceptor rejects the calls whose request does not hold the fields the matching route
+// of the web service requires of its request, within the same limits.
+type ValidationInterceptor struct {
+	limits domain.Limits
+}
+
+func NewValidationInterceptor(limits domain.Limits) *ValidationInterceptor {
+	return &ValidationInterceptor{limits: limits}
+}
+
+// Unary rejects the invalid requests with INVALID_ARGUMENT and a BadRequest detail listing the fields
+// in error. The interactors check the requests against the business rules.
+func (interceptor *ValidationInterceptor) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	violations := validateRequest(req, interceptor.limits)
+	if len(violations) == 0 {
+		return handler(ctx, req)
+	}
+
+	reasons := make([]string, len(violations))
+	for idx, violation := range violations {
+		reasons[idx] = violation.Field + ": " + violation.Description
+	}
+	st := status.New(codes.InvalidArgument, "invalid request: "+strings.Join(reasons, "; "))
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return nil, st.Err()
+}
+
+func validateRequest(req interface{}, limits domain.Limits) []*errdetails.BadRequest_FieldViolation {
+	var v violations
+	switch req := req.(type) {
+	case *orderpb.GetOrderRequest:
+		v.required("id", req.Id)
+	case *orderpb.ListOrdersRequest:
+		v.page(req.Page, limits)
+		if req.Status != "" {
+			v.oneOf("status", req.Status, orderStatuses())
+		}
+	case *orderpb.ListOrderedProductsRequest:
+		v.required("order_id", req.OrderId)
+	case *orderpb.AddProductRequest:
+		v.required("order_id", req.OrderId)
+		v.required("product_id", req.ProductId)
+		if req.Quantity != 0 && (req.Quantity < 1 || int(req.Quantity) > limits.MaxQuantityPerProduct) {
+			v.add("quantity", "must be between 1 and "+strconv.Itoa(limits.MaxQuantityPerProduct))
+		}
+		v.version(req.ExpectedVersion)
+	case *orderpb.GetOrderHistoryRequest:
+		v.required("order_id", req.OrderId)
+	case *orderpb.UpdateOrderStatusRequest:
+		v.required("order_id", req.OrderId)
+		v.oneOf("status", req.Status, orderStatuses())
+		v.version(req.ExpectedVersion)
+	case *orderpb.SetDispatchDateRequest:
+		v.required("order_id", req.OrderId)
+		if _, err := time.Parse("2006-01-02", req.DispatchDate); err != nil {
+			v.add("dispatch_date", "must be a date, such as 2006-01-02")
+		}
+		v.version(req.ExpectedVersion)
+	case *orderpb.ApplyCouponRequest:
+		v.required("order_id", req.OrderId)
+		v.required("code", req.Code)
+		v.version(req.ExpectedVersion)
+	case *orderpb.RemoveCouponRequest:
+		v.required("order_id", req.OrderId)
+		v.required("code", req.Code)
+		v.version(req.ExpectedVersion)
+	case *orderpb.GetProductRequest:
+		v.required("id", req.Id)
+	case *orderpb.ListProductsRequest:
+		v.page(req.Page, limits)
+		if req.Category != "" {
+			v.oneOf("category", req.Category, productCategories())
+		}
+		if req.MinPrice < 0 {
+			v.add("min_price", "must not be negative")
+		}
+	case *orderpb.CreateProductRequest:
+		v.required("id", req.Id)
+		v.product(&req.Name, &req.Category, &req.Price, &req.Sku)
+	case *orderpb.ReplaceProductRequest:
+		v.required("id", req.Id)
+		v.product(&req.Name, &req.Category, &req.Price, &req.Sku)
+		v.version(req.ExpectedVersion)
+	case *orderpb.UpdateProductRequest:
+		v.required("id", req.Id)
+		v.product(req.Name, req.Category, req.Price, req.Sku)
+		v.version(req.ExpectedVersion)
+	case *orderpb.DeleteProductRequest:
+		v.required("id", req.Id)
+		v.version(req.ExpectedVersion)
+	}
+	return v
+}
+
+type violations []*errdetails.BadRequest_FieldViolation
+
+func (v *violations) add(field, description string) {
+	*v = append(*v, &errdetails.BadRequest_FieldViolation{Field: field, Description: description})
+}
+
+func (v *violations) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *violations) oneOf(field, value string, allowed []string) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	v.add(field, "must be one of "+strings.Join(allowed, ", "))
+}
+
+func (v *violations) version(expectedVersion int32) {
+	if expectedVersion < 0 {
+		v.add("expected_version", "must not be negative")
+	}
+}
+
+func (v *violations) page(page *orderpb.Page, limits domain.Limits) {
+	if page != nil && (page.Limit < 0 || int(page.Limit) > limits.MaxPageLimit) {
+		v.add("page.limit", "must be between 1 and "+strconv.Itoa(limits.MaxPageLimit))
+	}
+}
+
+// product checks the fields of a product which are given, which are all of them unless the product
+// is being updated.
+func (v *violations) product(name, category *string, price *float64, sku *int32) {
+	if name != nil {
+		v.required("name", *name)
+	}
+	if category != nil {
+		v.oneOf("category", *category, productCategories())
+	}
+	if price != nil && *price <= 0 {
+		v.add("price", "must be greater than 0")
+	}
+	if sku != nil && *sku < 0 {
+		v.add("sku", "must not be negative")
+	}
+}
+
+func orderStatuses() []string {
+	statuses := make([]string, 0)
+	for _, status := range domain.OrderStatuses() {
+		statuses = append(statuses, string(status))
+	}
+	return statuses
+}
+
+func productCategories() []string {
+	categories := make([]string, 0)
+	for _, category := range domain.ProductCategories() {
+		categories = append(categories, string(category))
+	}
+	return categories
+}
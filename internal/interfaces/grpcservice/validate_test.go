@@ -0,0 +1,55 @@
+package grpcservice_test
+
+import (
+	"context"
+	"simple-order-service/api/orderpb"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/grpcservice"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidationInterceptor_ListsFieldsInError(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: orderpb.OrderService_AddProduct_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &orderpb.Order{}, nil
+	}
+
+	_, err := grpcservice.NewValidationInterceptor(domain.DefaultLimits()).Unary(context.Background(), &orderpb.AddProductRequest{OrderId: "1", Quantity: -1}, info, handler)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Got: %v, Want: %v", status.Code(err), codes.InvalidArgument)
+	}
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+	if len(fields) != 2 || fields[0] != "product_id" || fields[1] != "quantity" {
+		t.Errorf("Got: %v, Want: [product_id quantity]", fields)
+	}
+
+	if _, err := grpcservice.NewValidationInterceptor(domain.DefaultLimits()).Unary(context.Background(), &orderpb.AddProductRequest{OrderId: "1", ProductId: "123"}, info, handler); err != nil {
+		t.Errorf("Got: %v, Want: %v", err, nil)
+	}
+}
+
+func TestValidationInterceptor_HoldsQuantitiesToTheLimits(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: orderpb.OrderService_AddProduct_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &orderpb.Order{}, nil
+	}
+	limits := domain.DefaultLimits()
+	limits.MaxQuantityPerProduct = 2
+
+	_, err := grpcservice.NewValidationInterceptor(limits).Unary(context.Background(), &orderpb.AddProductRequest{OrderId: "1", ProductId: "123", Quantity: 3}, info, handler)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Got: %v, Want: %v", status.Code(err), codes.InvalidArgument)
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+)
+
+const APIKeysSchema = "api_keys"
+
+type apiKeysRepo struct {
+	dbClient database.Store
+}
+
+func NewAPIKeysRepo(db database.Store) apiKeysRepo {
+	return apiKeysRepo{dbClient: db}
+}
+
+func (keyRepo apiKeysRepo) Store(ctx context.Context, key domain.APIKey) error {
+	logger := logging.FromContext(ctx).With("key_id", key.ID())
+	data, err := key.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := keyRepo.dbClient.Put([]byte(APIKeysSchema), []byte(key.ID()), data); err != nil {
+		logger.Warn("could not store api key", "error", err)
+		return err
+	}
+	logger.Debug("stored api key")
+	return nil
+}
+
+func (keyRepo apiKeysRepo) FindById(ctx context.Context, id string) domain.APIKey {
+	key := &domain.APIKey{}
+	data := keyRepo.dbClient.Get([]byte(APIKeysSchema), []byte(id))
+	logging.FromContext(ctx).Debug("read api key", "key_id", id, "found", data != nil)
+	if data == nil {
+		return *key
+	}
+	key.UnmarshalJSON(data)
+	return *key
+}
+
+func (keyRepo apiKeysRepo) GetAll(ctx context.Context) []domain.APIKey {
+	data := keyRepo.dbClient.GetAll([]byte(APIKeysSchema))
+	keys := make([]domain.APIKey, len(data))
+	for idx, val := range data {
+		key := &domain.APIKey{}
+		key.UnmarshalJSON(val)
+		keys[idx] = *key
+	}
+	return keys
+}
+
+func (keyRepo apiKeysRepo) Delete(ctx context.Context, id string) error {
+	logging.FromContext(ctx).Debug("deleted api key", "key_id", id)
+	return keyRepo.dbClient.Delete([]byte(APIKeysSchema), []byte(id))
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+)
+
+const CouponsSchema = "coupons"
+
+type couponsRepo struct {
+	dbClient database.Store
+}
+
+func NewCouponsRepo(db database.Store) couponsRepo {
+	return couponsRepo{dbClient: db}
+}
+
+func (cpnRepo couponsRepo) Store(ctx context.Context, coupon domain.Coupon) error {
+	logger := logging.FromContext(ctx).With("coupon_code", coupon.Code())
+	data, err := coupon.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := cpnRepo.dbClient.Put([]byte(CouponsSchema), []byte(coupon.Code()), data); err != nil {
+		logger.Warn("could not store coupon", "error", err)
+		return err
+	}
+	logger.Debug("stored coupon")
+	return nil
+}
+
+func (cpnRepo couponsRepo) FindByCode(ctx context.Context, code string) domain.Coupon {
+	code = domain.NormalizeCouponCode(code)
+	coupon := &domain.Coupon{}
+	data := cpnRepo.dbClient.Get([]byte(CouponsSchema), []byte(code))
+	logging.FromContext(ctx).Debug("read coupon", "coupon_code", code, "found", data != nil)
+	if data == nil {
+		return *coupon
+	}
+	coupon.UnmarshalJSON(data)
+	return *coupon
+}
+
+func (cpnRepo couponsRepo) GetAll(ctx context.Context) []domain.Coupon {
+	data := cpnRepo.dbClient.GetAll([]byte(CouponsSchema))
+	if len(data) == 0 {
+		return []domain.Coupon{}
+	}
+	coupons := make([]domain.Coupon, len(data))
+	for idx, val := range data {
+		coupon := &domain.Coupon{}
+		coupon.UnmarshalJSON(val)
+		coupons[idx] = *coupon
+	}
+	return coupons
+}
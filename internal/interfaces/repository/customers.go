@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+)
+
+const CustomersSchema = "customers"
+
+type customersRepo struct {
+	dbClient database.Store
+}
+
+func NewCustomersRepo(db database.Store) customersRepo {
+	return customersRepo{dbClient: db}
+}
+
+func (custRepo customersRepo) Store(ctx context.Context, customer domain.Customer) error {
+	logger := logging.FromContext(ctx).With("customer_id", customer.ID())
+	data, err := customer.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := custRepo.dbClient.Put([]byte(CustomersSchema), []byte(customer.ID()), data); err != nil {
+		logger.Warn("could not store customer", "error", err)
+		return err
+	}
+	logger.Debug("stored customer")
+	return nil
+}
+
+func (custRepo customersRepo) FindById(ctx context.Context, id string) domain.Customer {
+	customer := &domain.Customer{}
+	data := custRepo.dbClient.Get([]byte(CustomersSchema), []byte(id))
+	logging.FromContext(ctx).Debug("read customer", "customer_id", id, "found", data != nil)
+	if data == nil {
+		return *customer
+	}
+	customer.UnmarshalJSON(data)
+	return *customer
+}
+
+// FindByEmail scans the customers, which are keyed by id.
+func (custRepo customersRepo) FindByEmail(ctx context.Context, email string) domain.Customer {
+	email = domain.NormalizeEmail(email)
+	for _, customer := range custRepo.GetAll(ctx) {
+		if customer.Email() == email {
+			return customer
+		}
+	}
+	return domain.Customer{}
+}
+
+func (custRepo customersRepo) GetAll(ctx context.Context) []domain.Customer {
+	data := custRepo.dbClient.GetAll([]byte(CustomersSchema))
+	customers := make([]domain.Customer, len(data))
+	for idx, val := range data {
+		customer := &domain.Customer{}
+		customer.UnmarshalJSON(val)
+		customers[idx] = *customer
+	}
+	return customers
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+	"time"
+)
+
+// feedTrimBatchSize is the number of events read at once while trimming the feed.
+const feedTrimBatchSize = 500
+
+const FeedSchema = "feed"
+
+type feedRepo struct {
+	db *database.DB
+}
+
+// NewFeedRepo needs the database itself rather than a database.Store, since the feed is watched for
+// the transactions committed by everyone.
+func NewFeedRepo(db *database.DB) feedRepo {
+	return feedRepo{db: db}
+}
+
+// appendToFeed publishes the event in the transaction which stores the change it describes. Events
+// are keyed by their zero padded sequence number so that they are read in sequence.
+func appendToFeed(tx *database.Tx, event domain.FeedEvent) error {
+	seq, err := tx.NextSequence([]byte(FeedSchema))
+	if err != nil {
+		return err
+	}
+	event.Sequence = seq
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return tx.Put([]byte(FeedSchema), feedKey(seq), data)
+}
+
+func feedKey(sequence uint64) []byte {
+	return []byte(fmt.Sprintf("%020d", sequence))
+}
+
+// Since returns up to limit events which come after the given sequence number.
+func (fdRepo feedRepo) Since(ctx context.Context, sequence uint64, limit int) []domain.FeedEvent {
+	data, _ := fdRepo.db.Page([]byte(FeedSchema), database.PageQuery{After: feedKey(sequence), Limit: limit}, nil)
+	events := make([]domain.FeedEvent, len(data))
+	for idx, val := range data {
+		json.Unmarshal(val, &events[idx])
+	}
+	logging.FromContext(ctx).Debug("read feed", "after", sequence, "events", len(events))
+	return events
+}
+
+func (fdRepo feedRepo) LastSequence(ctx context.Context) uint64 {
+	data, _ := fdRepo.db.Page([]byte(FeedSchema), database.PageQuery{Limit: 1, Descending: true}, nil)
+	if len(data) == 0 {
+		return 0
+	}
+	event := domain.FeedEvent{}
+	json.Unmarshal(data[0], &event)
+	return event.Sequence
+}
+
+// DeleteBefore removes the events which happened before the given time, oldest first, and returns how
+// many were removed. The last event is kept whatever its age, so that LastSequence still reports it.
+func (fdRepo feedRepo) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := fdRepo.db.Tx(func(tx *database.Tx) error {
+		deleted = 0
+		last, _ := tx.Page([]byte(FeedSchema), database.PageQuery{Limit: 1, Descending: true}, nil)
+		if len(last) == 0 {
+			return nil
+		}
+		lastEvent := domain.FeedEvent{}
+		if err := json.Unmarshal(last[0], &lastEvent); err != nil {
+			return err
+		}
+		for {
+			data, _ := tx.Page([]byte(FeedSchema), database.PageQuery{Limit: feedTrimBatchSize}, nil)
+			if len(data) == 0 {
+				return nil
+			}
+			for _, val := range data {
+				event := domain.FeedEvent{}
+				if err := json.Unmarshal(val, &event); err != nil {
+					return err
+				}
+				if event.Sequence >= lastEvent.Sequence || !event.At.Before(before) {
+					return nil
+				}
+				if err := tx.Delete([]byte(FeedSchema), feedKey(event.Sequence)); err != nil {
+					return err
+				}
+				deleted += 1
+			}
+		}
+	})
+	if err != nil {
+		logging.FromContext(ctx).Warn("could not trim feed", "before", before, "error", err)
+		return 0, err
+	}
+	logging.FromContext(ctx).Debug("trimmed feed", "before", before, "deleted", deleted)
+	return deleted, nil
+}
+
+func (fdRepo feedRepo) Watch() (<-chan struct{}, func()) {
+	return fdRepo.db.Watch([]byte(FeedSchema))
+}
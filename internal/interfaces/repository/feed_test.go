@@ -0,0 +1,51 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/repository"
+	"testing"
+	"time"
+)
+
+func TestFeedRepo_DeleteBeforeKeepsTheRecentEventsAndTheLastOne(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	feedRepo := repository.NewFeedRepo(db)
+	for i := 1; i <= 3; i++ {
+		storeOrder(t, db, fmt.Sprint(i), func(order *domain.Order) {
+			order.SetOrderStatus(domain.OrderPlaced, "tester")
+		})
+	}
+	last := feedRepo.LastSequence(ctx)
+	if last == 0 {
+		t.Fatalf("Got: %v, Want: the events of the placed orders", last)
+	}
+
+	deleted, err := feedRepo.DeleteBefore(ctx, time.Now().Add(-time.Hour))
+	if err != nil || deleted != 0 {
+		t.Errorf("Got: %v events deleted (%v), Want: none", deleted, err)
+	}
+
+	deleted, err = feedRepo.DeleteBefore(ctx, time.Now().Add(time.Hour))
+	if err != nil || deleted != int(last)-1 {
+		t.Errorf("Got: %v events deleted (%v), Want: %v", deleted, err, last-1)
+	}
+	if got := feedRepo.LastSequence(ctx); got != last {
+		t.Errorf("Got: %v, Want: %v", got, last)
+	}
+	events := feedRepo.Since(ctx, 0, 10)
+	if len(events) != 1 || events[0].Sequence != last {
+		t.Fatalf("Got: %v events, Want: event %v only", len(events), last)
+	}
+
+	// The sequence carries on from the last event.
+	storeOrder(t, db, "4", func(order *domain.Order) {
+		order.SetOrderStatus(domain.OrderPlaced, "tester")
+	})
+	events = feedRepo.Since(ctx, last, 10)
+	if len(events) == 0 || events[0].Sequence != last+1 {
+		t.Errorf("Got: %v, Want: event %v first", events, last+1)
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+	"time"
+)
+
+const IdempotencyKeysSchema = "idempotency_keys"
+
+type idempotencyKeysRepo struct {
+	dbClient database.Store
+}
+
+func NewIdempotencyKeysRepo(db database.Store) idempotencyKeysRepo {
+	return idempotencyKeysRepo{dbClient: db}
+}
+
+func (idemRepo idempotencyKeysRepo) Store(ctx context.Context, record domain.IdempotencyRecord) error {
+	logger := logging.FromContext(ctx).With("idempotency_key", record.Key)
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := idemRepo.dbClient.Put([]byte(IdempotencyKeysSchema), []byte(record.Key), data); err != nil {
+		logger.Warn("could not store idempotency record", "error", err)
+		return err
+	}
+	logger.Debug("stored idempotency record", "status_code", record.StatusCode, "expires_at", record.ExpiresAt)
+	return nil
+}
+
+func (idemRepo idempotencyKeysRepo) FindByKey(ctx context.Context, key string) domain.IdempotencyRecord {
+	record := domain.IdempotencyRecord{}
+	data := idemRepo.dbClient.Get([]byte(IdempotencyKeysSchema), []byte(key))
+	logging.FromContext(ctx).Debug("read idempotency record", "idempotency_key", key, "found", data != nil)
+	if data == nil {
+		return record
+	}
+	json.Unmarshal(data, &record)
+	return record
+}
+
+// DeleteExpired removes the records which have expired at the given time, and returns how many were
+// removed.
+func (idemRepo idempotencyKeysRepo) DeleteExpired(ctx context.Context, at time.Time) (int, error) {
+	deleted := 0
+	for _, val := range idemRepo.dbClient.GetAll([]byte(IdempotencyKeysSchema)) {
+		record := domain.IdempotencyRecord{}
+		if err := json.Unmarshal(val, &record); err != nil {
+			continue
+		}
+		if !record.IsExpired(at) {
+			continue
+		}
+		if err := idemRepo.dbClient.Delete([]byte(IdempotencyKeysSchema), []byte(record.Key)); err != nil {
+			logging.FromContext(ctx).Warn("could not delete idempotency record", "idempotency_key", record.Key, "error", err)
+			return deleted, err
+		}
+		deleted += 1
+	}
+	logging.FromContext(ctx).Debug("deleted expired idempotency records", "deleted", deleted)
+	return deleted, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"time"
+)
+
+const MigrationsSchema = "migrations"
+
+type migration struct {
+	name string
+	run  func(tx *database.Tx) error
+}
+
+// migrations are applied in order and each of them only once; the name of an applied migration is
+// recorded in the migrations bucket. New migrations must be appended to the end of the list.
+var migrations = []migration{
+	{name: "0001_order_lines", run: migrateOrdersToLines},
+	{name: "0002_order_events", run: migrateOrdersToEventLog},
+}
+
+// Migrate applies the pending storage migrations. Every migration runs in its own transaction, so a
+// failed migration leaves the records it touched unchanged.
+func Migrate(db *database.DB) error {
+	for _, m := range migrations {
+		err := db.Tx(func(tx *database.Tx) error {
+			if tx.Get([]byte(MigrationsSchema), []byte(m.name)) != nil {
+				return nil
+			}
+			if err := m.run(tx); err != nil {
+				return err
+			}
+			appliedAt := time.Now().UTC().Format(time.RFC3339)
+			return tx.Put([]byte(MigrationsSchema), []byte(m.name), []byte(appliedAt))
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+var ErrPendingMigration = func(name string) error {
+	return fmt.Errorf("migration %s has not been applied", name)
+}
+
+// CheckStorage verifies that the database can be read and that every migration has been applied.
+func CheckStorage(db *database.DB) error {
+	if err := db.Check([]byte(MigrationsSchema)); err != nil {
+		return err
+	}
+	return db.View(func(tx *database.Tx) error {
+		for _, m := range migrations {
+			if tx.Get([]byte(MigrationsSchema), []byte(m.name)) == nil {
+				return ErrPendingMigration(m.name)
+			}
+		}
+		return nil
+	})
+}
+
+// migrateOrdersToLines rewrites orders which hold a copy of the product for every ordered unit into
+// orders with one line per product.
+func migrateOrdersToLines(tx *database.Tx) error {
+	for _, data := range tx.GetAll([]byte(OrdersSchema)) {
+		order := &domain.Order{}
+		if err := order.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		migrated, err := order.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if err := tx.Put([]byte(OrdersSchema), []byte(order.ID()), migrated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateOrdersToEventLog starts the event log of every order stored before orders had one with a
+// snapshot of the order. The snapshot is only written to the event log: the import is not a change
+// to deliver to webhooks or to the feed, and the order keeps its version, so that the ETags held by
+// clients stay valid.
+func migrateOrdersToEventLog(tx *database.Tx) error {
+	ctx := context.Background()
+	ordersRepo := NewOrdersRepo(tx)
+	for _, order := range ordersRepo.GetAll(ctx) {
+		events, err := ordersRepo.Events(ctx, order.ID())
+		if err != nil {
+			return err
+		}
+		if len(events) > 0 {
+			continue
+		}
+		if err := order.RecordImport(); err != nil {
+			return err
+		}
+		for _, event := range order.PendingEvents() {
+			event.Version = order.Version()
+			if err := appendOrderEvent(tx, event); err != nil {
+				return err
+			}
+		}
+		data, err := order.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if err := tx.Put([]byte(OrdersSchema), []byte(order.ID()), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"encoding/json"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+)
+
+// RebuildOrders rewrites the orders bucket by replaying the event log of every order, and returns
+// the number of orders rebuilt. The orders are rebuilt in a single transaction.
+func RebuildOrders(db *database.DB) (int, error) {
+	rebuilt := 0
+	err := db.Tx(func(tx *database.Tx) error {
+		rebuilt = 0
+		var events []domain.OrderEvent
+		flush := func() error {
+			if len(events) == 0 {
+				return nil
+			}
+			order, err := domain.ReplayOrder(events)
+			if err != nil {
+				return err
+			}
+			data, err := order.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			events = nil
+			rebuilt += 1
+			return tx.Put([]byte(OrdersSchema), []byte(order.ID()), data)
+		}
+
+		// The log is keyed by order id and then by sequence, so the events of an order are read
+		// together and in sequence.
+		for _, val := range tx.GetAll([]byte(OrderEventsSchema)) {
+			event := domain.OrderEvent{}
+			if err := json.Unmarshal(val, &event); err != nil {
+				return err
+			}
+			if len(events) > 0 && events[0].OrderID != event.OrderID {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			events = append(events, event)
+		}
+		return flush()
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rebuilt, nil
+}
@@ -1,36 +1,122 @@
 package repository
 
 import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
 	"simple-order-service/internal/domain"
 	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
 )
 
-const OrdersSchema = "orders"
+const (
+	OrdersSchema      = "orders"
+	OrderEventsSchema = "order_events"
+)
 
 type ordersRepo struct {
-	dbClient database.DB
+	dbClient database.Store
 }
 
-func NewOrdersRepo(db *database.DB) ordersRepo {
-	return ordersRepo{dbClient: *db}
+func NewOrdersRepo(db database.Store) ordersRepo {
+	return ordersRepo{dbClient: db}
 }
 
-func (ordRepo ordersRepo) Store(order domain.Order) error {
-	data, err := order.MarshalJSON()
+// Store writes the order if nobody else has stored it since it was read, and fails with
+// domain.ErrVersionConflict otherwise. The events recorded by the order since it was read are
+// appended to the event log in the same transaction; the orders bucket only holds a projection of
+// the log. The events are also queued in the outbox, to be delivered to webhooks, and the changes of
+// status and dispatch date are published to the feed.
+func (ordRepo ordersRepo) Store(ctx context.Context, order domain.Order) error {
+	logger := logging.FromContext(ctx).With("order_id", order.ID())
+	expectedVersion := order.Version()
+	err := ordRepo.dbClient.Tx(func(tx *database.Tx) error {
+		err := tx.Update([]byte(OrdersSchema), []byte(order.ID()), func(current []byte) ([]byte, error) {
+			if err := checkStoredVersion(current, order.Version()); err != nil {
+				return nil, err
+			}
+			order.IncrementVersion()
+			return order.MarshalJSON()
+		})
+		if err != nil {
+			return err
+		}
+		for _, event := range order.PendingEvents() {
+			event.Version = order.Version()
+			if err := appendOrderEvent(tx, event); err != nil {
+				return err
+			}
+			if err := appendToOutbox(tx, event); err != nil {
+				return err
+			}
+			if feedEvent, ok := domain.FeedEventFromOrderEvent(event); ok {
+				if err := appendToFeed(tx, feedEvent); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
 	if err != nil {
+		logger.Warn("could not store order", "version", expectedVersion, "error", err)
 		return err
 	}
-	return ordRepo.dbClient.Put([]byte(OrdersSchema), []byte(order.ID()), data)
+	logger.Debug("stored order", "version", order.Version(), "events", len(order.PendingEvents()))
+	return nil
+}
+
+// Events returns the event log of the order in sequence.
+func (ordRepo ordersRepo) Events(ctx context.Context, orderID string) ([]domain.OrderEvent, error) {
+	data := ordRepo.dbClient.Scan([]byte(OrderEventsSchema), orderEventPrefix(orderID))
+	events := make([]domain.OrderEvent, len(data))
+	for idx, val := range data {
+		if err := json.Unmarshal(val, &events[idx]); err != nil {
+			logging.FromContext(ctx).Warn("could not read order event", "id", orderID, "error", err)
+			return nil, fmt.Errorf("could not read event %d of order #%s: %w", idx+1, orderID, err)
+		}
+	}
+	return events, nil
+}
+
+// orderEventPrefix keys the events of an order by the id of the order, followed by the zero padded
+// sequence of the event so that events are kept in sequence.
+func orderEventPrefix(orderID string) []byte {
+	return keyPart(nil, orderID)
+}
+
+func orderEventKey(orderID string, sequence int) []byte {
+	return append(orderEventPrefix(orderID), fmt.Sprintf("%020d", sequence)...)
 }
 
-func (ordRepo ordersRepo) FindById(id string) domain.Order {
+// keyPart appends a part of a key made of several ids, prefixed by its length so that no id can run
+// into the next one, whichever bytes the ids hold.
+func keyPart(key []byte, id string) []byte {
+	key = binary.BigEndian.AppendUint32(key, uint32(len(id)))
+	return append(key, id...)
+}
+
+// appendOrderEvent writes an event to the log. Events are immutable, so an event is never written
+// twice.
+func appendOrderEvent(tx *database.Tx, event domain.OrderEvent) error {
+	key := orderEventKey(event.OrderID, event.Sequence)
+	return tx.Update([]byte(OrderEventsSchema), key, func(current []byte) ([]byte, error) {
+		if current != nil {
+			return nil, fmt.Errorf("%w: event %d of order #%s has already been recorded", domain.ErrVersionConflict, event.Sequence, event.OrderID)
+		}
+		return json.Marshal(event)
+	})
+}
+
+func (ordRepo ordersRepo) FindById(ctx context.Context, id string) domain.Order {
 	data := ordRepo.dbClient.Get([]byte(OrdersSchema), []byte(id))
+	logging.FromContext(ctx).Debug("read order", "order_id", id, "found", data != nil)
 	order := &domain.Order{}
 	order.UnmarshalJSON(data)
 	return *order
 }
 
-func (ordRepo ordersRepo) GetAll() []domain.Order {
+func (ordRepo ordersRepo) GetAll(ctx context.Context) []domain.Order {
 	data := ordRepo.dbClient.GetAll([]byte(OrdersSchema))
 	if len(data) == 0 {
 		return []domain.Order{}
@@ -43,3 +129,23 @@ func (ordRepo ordersRepo) GetAll() []domain.Order {
 	}
 	return orders
 }
+
+func (ordRepo ordersRepo) Find(ctx context.Context, query domain.OrderQuery) ([]domain.Order, string, error) {
+	after, err := database.DecodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", domain.ErrInvalidCursor
+	}
+	// The matching orders are kept as they are decoded, so that each record is decoded once. The
+	// record matched after the last one of the page only tells whether there is a next page.
+	orders := make([]domain.Order, 0)
+	pageQuery := database.PageQuery{After: after, Limit: query.Limit, Descending: query.Descending}
+	data, next := ordRepo.dbClient.Page([]byte(OrdersSchema), pageQuery, func(value []byte) bool {
+		order := &domain.Order{}
+		if err := order.UnmarshalJSON(value); err != nil || !query.Matches(*order) {
+			return false
+		}
+		orders = append(orders, *order)
+		return true
+	})
+	return orders[:len(data)], database.EncodeCursor(next), nil
+}
@@ -0,0 +1,175 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/repository"
+	"simple-order-service/pkg/database"
+	"testing"
+)
+
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewInstance(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// storeOrder stores the changes made by change to the order as it is stored, or to a new order when
+// none is, and returns the order as it is stored afterwards.
+func storeOrder(t *testing.T, db *database.DB, id string, change func(order *domain.Order)) domain.Order {
+	t.Helper()
+	ctx := context.Background()
+	ordersRepo := repository.NewOrdersRepo(db)
+	order := ordersRepo.FindById(ctx, id)
+	if order.ID() == "" {
+		order = domain.NewOrder(id)
+	}
+	change(&order)
+	if err := ordersRepo.Store(ctx, order); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	return ordersRepo.FindById(ctx, id)
+}
+
+func addShoes(order *domain.Order) {
+	order.Add(domain.NewProduct("1", "nike shoes", 100.0, 5, domain.Premium))
+}
+
+func marshal(t *testing.T, order domain.Order) string {
+	t.Helper()
+	data, err := order.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestOrdersRepo_StoreRejectsStaleVersions(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	ordersRepo := repository.NewOrdersRepo(db)
+	storeOrder(t, db, "1", func(order *domain.Order) {})
+
+	first := ordersRepo.FindById(ctx, "1")
+	second := ordersRepo.FindById(ctx, "1")
+	addShoes(&first)
+	if err := ordersRepo.Store(ctx, first); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	addShoes(&second)
+	if err := ordersRepo.Store(ctx, second); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("Got: %v, Want: %v", err, domain.ErrVersionConflict)
+	}
+
+	stored := ordersRepo.FindById(ctx, "1")
+	events, err := ordersRepo.Events(ctx, "1")
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if stored.Version() != 2 || stored.ProductQuantity() != 1 || len(events) != 2 {
+		t.Errorf("Got: version %v with %v units and %v events, Want: version %v with %v units and %v events",
+			stored.Version(), stored.ProductQuantity(), len(events), 2, 1, 2)
+	}
+}
+
+func TestOrdersRepo_EventsOfIdsSharingAPrefixDoNotCollide(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	// The second id holds what the key of the first event of the first order would be, had the
+	// ids only been followed by a separator.
+	ids := []string{"a", "a\x0000000000000000000001", "ab"}
+	for idx, id := range ids {
+		storeOrder(t, db, id, func(order *domain.Order) {
+			for i := 0; i < idx; i++ {
+				addShoes(order)
+			}
+		})
+	}
+
+	ordersRepo := repository.NewOrdersRepo(db)
+	for idx, id := range ids {
+		events, err := ordersRepo.Events(ctx, id)
+		if err != nil {
+			t.Fatalf("Got: %v, Want: %v", err, nil)
+		}
+		if len(events) != idx+1 {
+			t.Errorf("Got: %v events of order %q, Want: %v", len(events), id, idx+1)
+		}
+		for sequence, event := range events {
+			if event.OrderID != id || event.Sequence != sequence+1 {
+				t.Errorf("Got: event %v of order %q, Want: event %v of order %q", event.Sequence, event.OrderID, sequence+1, id)
+			}
+		}
+	}
+}
+
+func TestRebuildOrders_ProjectsTheStoredOrdersFromTheirEvents(t *testing.T) {
+	db := newTestDB(t)
+	storeOrder(t, db, "1", addShoes)
+	storeOrder(t, db, "1", addShoes)
+	want := storeOrder(t, db, "1", func(order *domain.Order) {
+		order.SetOrderStatus(domain.OrderPlaced, "tester")
+	})
+	other := storeOrder(t, db, "2", func(order *domain.Order) {})
+
+	// The projection is lost, and rebuilt from the event log.
+	db.Put([]byte(repository.OrdersSchema), []byte("1"), []byte(marshal(t, domain.NewOrder("1"))))
+	rebuilt, err := repository.RebuildOrders(db)
+	if err != nil || rebuilt != 2 {
+		t.Fatalf("Got: %v orders rebuilt (%v), Want: %v", rebuilt, err, 2)
+	}
+
+	ordersRepo := repository.NewOrdersRepo(db)
+	if got := marshal(t, ordersRepo.FindById(context.Background(), "1")); got != marshal(t, want) {
+		t.Errorf("Got: %v, Want: %v", got, marshal(t, want))
+	}
+	if got := marshal(t, ordersRepo.FindById(context.Background(), "2")); got != marshal(t, other) {
+		t.Errorf("Got: %v, Want: %v", got, marshal(t, other))
+	}
+}
+
+func TestMigrate_ImportsLegacyOrdersIntoTheEventLogOnce(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	// An order stored before orders had an event log.
+	legacy := domain.NewOrder("1")
+	addShoes(&legacy)
+	legacy.IncrementVersion()
+	db.Put([]byte(repository.OrdersSchema), []byte("1"), []byte(marshal(t, legacy)))
+
+	if err := repository.Migrate(db); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	// The migration is run again, as if it had not been recorded as applied.
+	db.Delete([]byte(repository.MigrationsSchema), []byte("0002_order_events"))
+	if err := repository.Migrate(db); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	ordersRepo := repository.NewOrdersRepo(db)
+	events, err := ordersRepo.Events(ctx, "1")
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if len(events) != 1 || events[0].Type != domain.OrderImported {
+		t.Fatalf("Got: %v events, Want: the import of the order only", len(events))
+	}
+	stored := ordersRepo.FindById(ctx, "1")
+	if stored.Version() != legacy.Version() || stored.ProductQuantity() != 1 {
+		t.Errorf("Got: version %v with %v units, Want: version %v with %v units", stored.Version(), stored.ProductQuantity(), legacy.Version(), 1)
+	}
+	if err := repository.CheckStorage(db); err != nil {
+		t.Errorf("Got: %v, Want: %v", err, nil)
+	}
+
+	replayed, err := domain.ReplayOrder(events)
+	if err != nil || replayed.ProductQuantity() != 1 || replayed.Version() != legacy.Version() {
+		t.Errorf("Got: version %v with %v units (%v), Want: version %v with %v units", replayed.Version(), replayed.ProductQuantity(), err, legacy.Version(), 1)
+	}
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+	"time"
+)
+
+const (
+	OutboxSchema             = "outbox"
+	WebhookDeliveriesSchema  = "webhook_deliveries"
+	WebhookDeadLettersSchema = "webhook_dead_letters"
+)
+
+type outboxRepo struct {
+	dbClient database.Store
+}
+
+func NewOutboxRepo(db database.Store) outboxRepo {
+	return outboxRepo{dbClient: db}
+}
+
+// appendToOutbox queues the event for delivery to webhooks. Messages are keyed by a zero padded
+// sequence number so that they are read in the order in which they were appended.
+func appendToOutbox(tx *database.Tx, event domain.OrderEvent) error {
+	seq, err := tx.NextSequence([]byte(OutboxSchema))
+	if err != nil {
+		return err
+	}
+	message := domain.OutboxMessage{ID: fmt.Sprintf("%020d", seq), Event: event}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return tx.Put([]byte(OutboxSchema), []byte(message.ID), data)
+}
+
+func (obRepo outboxRepo) Pending(ctx context.Context, limit int) []domain.OutboxMessage {
+	data, _ := obRepo.dbClient.Page([]byte(OutboxSchema), database.PageQuery{Limit: limit}, nil)
+	messages := make([]domain.OutboxMessage, len(data))
+	for idx, val := range data {
+		json.Unmarshal(val, &messages[idx])
+	}
+	logging.FromContext(ctx).Debug("read outbox", "messages", len(messages))
+	return messages
+}
+
+func (obRepo outboxRepo) Delete(ctx context.Context, id string) error {
+	return obRepo.delete(ctx, OutboxSchema, id)
+}
+
+func (obRepo outboxRepo) StoreDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	return obRepo.storeDelivery(ctx, WebhookDeliveriesSchema, delivery)
+}
+
+func (obRepo outboxRepo) DueDeliveries(ctx context.Context, at time.Time, limit int) []domain.WebhookDelivery {
+	data, _ := obRepo.dbClient.Page([]byte(WebhookDeliveriesSchema), database.PageQuery{Limit: limit}, func(value []byte) bool {
+		delivery := domain.WebhookDelivery{}
+		if err := json.Unmarshal(value, &delivery); err != nil {
+			return false
+		}
+		return !delivery.NextAttemptAt.After(at)
+	})
+	logging.FromContext(ctx).Debug("read due webhook deliveries", "deliveries", len(data))
+	return unmarshalDeliveries(data)
+}
+
+func (obRepo outboxRepo) DeleteDelivery(ctx context.Context, id string) error {
+	return obRepo.delete(ctx, WebhookDeliveriesSchema, id)
+}
+
+func (obRepo outboxRepo) StoreDeadLetter(ctx context.Context, delivery domain.WebhookDelivery) error {
+	return obRepo.storeDelivery(ctx, WebhookDeadLettersSchema, delivery)
+}
+
+func (obRepo outboxRepo) DeadLetters(ctx context.Context, webhookID string) []domain.WebhookDelivery {
+	deliveries := make([]domain.WebhookDelivery, 0)
+	for _, delivery := range unmarshalDeliveries(obRepo.dbClient.GetAll([]byte(WebhookDeadLettersSchema))) {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries
+}
+
+func (obRepo outboxRepo) storeDelivery(ctx context.Context, schema string, delivery domain.WebhookDelivery) error {
+	logger := logging.FromContext(ctx).With("bucket", schema, "delivery_id", delivery.ID, "webhook_id", delivery.WebhookID)
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	if err := obRepo.dbClient.Put([]byte(schema), []byte(delivery.ID), data); err != nil {
+		logger.Warn("could not store webhook delivery", "error", err)
+		return err
+	}
+	logger.Debug("stored webhook delivery", "attempts", delivery.Attempts)
+	return nil
+}
+
+func (obRepo outboxRepo) delete(ctx context.Context, schema, id string) error {
+	logger := logging.FromContext(ctx).With("bucket", schema, "id", id)
+	if err := obRepo.dbClient.Delete([]byte(schema), []byte(id)); err != nil {
+		logger.Warn("could not delete from the outbox", "error", err)
+		return err
+	}
+	logger.Debug("deleted from the outbox")
+	return nil
+}
+
+func unmarshalDeliveries(data [][]byte) []domain.WebhookDelivery {
+	deliveries := make([]domain.WebhookDelivery, len(data))
+	for idx, val := range data {
+		json.Unmarshal(val, &deliveries[idx])
+	}
+	return deliveries
+}
@@ -1,32 +1,63 @@
 package repository
 
 import (
+	"context"
 	"encoding/json"
 	"simple-order-service/internal/domain"
 	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+	"time"
 )
 
 const ProductsSchema = "products"
 
 type productsRepo struct {
-	dbClient database.DB
+	dbClient database.Store
 }
 
-func NewProductsRepo(db *database.DB) productsRepo {
-	return productsRepo{dbClient: *db}
+func NewProductsRepo(db database.Store) productsRepo {
+	return productsRepo{dbClient: db}
 }
 
-func (prodRepo productsRepo) Store(product domain.Product) error {
-	data, err := json.Marshal(&product)
+// Store writes the product if nobody else has stored it since it was read, and fails with
+// domain.ErrVersionConflict otherwise. A change to the stock of the product is published to the feed
+// in the same transaction.
+func (prodRepo productsRepo) Store(ctx context.Context, product domain.Product) error {
+	logger := logging.FromContext(ctx).With("product_id", product.ID())
+	expectedVersion := product.Version()
+	err := prodRepo.dbClient.Tx(func(tx *database.Tx) error {
+		stockChanged := false
+		err := tx.Update([]byte(ProductsSchema), []byte(product.ID()), func(current []byte) ([]byte, error) {
+			if err := checkStoredVersion(current, product.Version()); err != nil {
+				return nil, err
+			}
+			previous := domain.Product{}
+			if current != nil {
+				if err := previous.UnmarshalJSON(current); err != nil {
+					return nil, err
+				}
+			}
+			stockChanged = current == nil || product.StockChanged(previous)
+			product.IncrementVersion()
+			return json.Marshal(&product)
+		})
+		if err != nil || !stockChanged {
+			return err
+		}
+		return appendToFeed(tx, domain.NewStockChangedEvent(product, time.Now().UTC()))
+	})
 	if err != nil {
+		logger.Warn("could not store product", "version", expectedVersion, "error", err)
 		return err
 	}
-	return prodRepo.dbClient.Put([]byte(ProductsSchema), []byte(product.ID()), data)
+	logger.Debug("stored product", "version", product.Version(), "available", product.Available(), "reserved", product.Reserved())
+	return nil
 }
 
-func (prodRepo productsRepo) FindById(id string) domain.Product {
+func (prodRepo productsRepo) FindById(ctx context.Context, id string) domain.Product {
 	product := &domain.Product{}
 	data := prodRepo.dbClient.Get([]byte(ProductsSchema), []byte(id))
+	logging.FromContext(ctx).Debug("read product", "product_id", id, "found", data != nil)
 	if data == nil {
 		return *product
 	}
@@ -34,7 +65,7 @@ func (prodRepo productsRepo) FindById(id string) domain.Product {
 	return *product
 }
 
-func (prodRepo productsRepo) GetAll() []domain.Product {
+func (prodRepo productsRepo) GetAll(ctx context.Context) []domain.Product {
 	data := prodRepo.dbClient.GetAll([]byte(ProductsSchema))
 	if len(data) == 0 {
 		return []domain.Product{}
@@ -47,3 +78,27 @@ func (prodRepo productsRepo) GetAll() []domain.Product {
 	}
 	return products
 }
+
+func (prodRepo productsRepo) Delete(ctx context.Context, id string) error {
+	return prodRepo.dbClient.Delete([]byte(ProductsSchema), []byte(id))
+}
+
+func (prodRepo productsRepo) Find(ctx context.Context, query domain.ProductQuery) ([]domain.Product, string, error) {
+	after, err := database.DecodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", domain.ErrInvalidCursor
+	}
+	// The matching products are kept as they are decoded, so that each record is decoded once. The
+	// record matched after the last one of the page only tells whether there is a next page.
+	products := make([]domain.Product, 0)
+	pageQuery := database.PageQuery{After: after, Limit: query.Limit, Descending: query.Descending}
+	data, next := prodRepo.dbClient.Page([]byte(ProductsSchema), pageQuery, func(value []byte) bool {
+		product := &domain.Product{}
+		if err := product.UnmarshalJSON(value); err != nil || !query.Matches(*product) {
+			return false
+		}
+		products = append(products, *product)
+		return true
+	})
+	return products[:len(data)], database.EncodeCursor(next), nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+)
+
+const ReservationsSchema = "reservations"
+
+type reservationsRepo struct {
+	dbClient database.Store
+}
+
+func NewReservationsRepo(db database.Store) reservationsRepo {
+	return reservationsRepo{dbClient: db}
+}
+
+// reservationKey keys a reservation by its order and product, so that the reservations of an order
+// are read together.
+func reservationKey(orderID, productID string) []byte {
+	return keyPart(reservationPrefix(orderID), productID)
+}
+
+func reservationPrefix(orderID string) []byte {
+	return keyPart(nil, orderID)
+}
+
+func (rsvRepo reservationsRepo) Store(ctx context.Context, reservation domain.Reservation) error {
+	logger := logging.FromContext(ctx).With("order_id", reservation.OrderID(), "product_id", reservation.ProductID())
+	data, err := reservation.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := rsvRepo.dbClient.Put([]byte(ReservationsSchema), reservationKey(reservation.OrderID(), reservation.ProductID()), data); err != nil {
+		logger.Warn("could not store reservation", "error", err)
+		return err
+	}
+	logger.Debug("stored reservation", "quantity", reservation.Quantity(), "expires_at", reservation.ExpiresAt())
+	return nil
+}
+
+func (rsvRepo reservationsRepo) Find(ctx context.Context, orderID, productID string) domain.Reservation {
+	reservation := &domain.Reservation{}
+	data := rsvRepo.dbClient.Get([]byte(ReservationsSchema), reservationKey(orderID, productID))
+	if data == nil {
+		return *reservation
+	}
+	reservation.UnmarshalJSON(data)
+	return *reservation
+}
+
+func (rsvRepo reservationsRepo) FindByOrder(ctx context.Context, orderID string) []domain.Reservation {
+	data := rsvRepo.dbClient.Scan([]byte(ReservationsSchema), reservationPrefix(orderID))
+	reservations := make([]domain.Reservation, len(data))
+	for idx, val := range data {
+		reservations[idx].UnmarshalJSON(val)
+	}
+	logging.FromContext(ctx).Debug("read reservations", "order_id", orderID, "reservations", len(reservations))
+	return reservations
+}
+
+func (rsvRepo reservationsRepo) FindExpired(ctx context.Context, at time.Time) []domain.Reservation {
+	return rsvRepo.filter(func(reservation domain.Reservation) bool {
+		return reservation.IsExpired(at)
+	})
+}
+
+func (rsvRepo reservationsRepo) Delete(ctx context.Context, orderID, productID string) error {
+	logging.FromContext(ctx).Debug("deleted reservation", "order_id", orderID, "product_id", productID)
+	return rsvRepo.dbClient.Delete([]byte(ReservationsSchema), reservationKey(orderID, productID))
+}
+
+func (rsvRepo reservationsRepo) filter(match func(reservation domain.Reservation) bool) []domain.Reservation {
+	reservations := []domain.Reservation{}
+	for _, val := range rsvRepo.dbClient.GetAll([]byte(ReservationsSchema)) {
+		reservation := &domain.Reservation{}
+		reservation.UnmarshalJSON(val)
+		if match(*reservation) {
+			reservations = append(reservations, *reservation)
+		}
+	}
+	return reservations
+}
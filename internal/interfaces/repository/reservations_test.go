@@ -0,0 +1,40 @@
+package repository_test
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/repository"
+	"testing"
+	"time"
+)
+
+func TestReservationsRepo_FindByOrderOnlyReadsTheReservationsOfTheOrder(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	reservationsRepo := repository.NewReservationsRepo(db)
+	expiresAt := time.Now().UTC().Add(time.Hour)
+	reservations := []domain.Reservation{
+		domain.NewReservation("1", "10", 1, expiresAt),
+		domain.NewReservation("1", "20", 2, expiresAt),
+		domain.NewReservation("12", "10", 3, expiresAt),
+		domain.NewReservation("1\x0010", "10", 4, expiresAt),
+	}
+	for _, reservation := range reservations {
+		if err := reservationsRepo.Store(ctx, reservation); err != nil {
+			t.Fatalf("Got: %v, Want: %v", err, nil)
+		}
+	}
+
+	got := reservationsRepo.FindByOrder(ctx, "1")
+	if len(got) != 2 {
+		t.Fatalf("Got: %v reservations, Want: %v", len(got), 2)
+	}
+	for idx, reservation := range got {
+		if reservation.OrderID() != "1" || reservation.Quantity() != idx+1 {
+			t.Errorf("Got: %v units for order %q, Want: %v units for order %q", reservation.Quantity(), reservation.OrderID(), idx+1, "1")
+		}
+	}
+	if got := reservationsRepo.Find(ctx, "12", "10"); got.Quantity() != 3 {
+		t.Errorf("Got: %v, Want: %v", got.Quantity(), 3)
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+)
+
+type unitOfWork struct {
+	db *database.DB
+}
+
+func NewUnitOfWork(db *database.DB) unitOfWork {
+	return unitOfWork{db: db}
+}
+
+func (uow unitOfWork) Do(ctx context.Context, fn func(repos domain.Repositories) error) error {
+	err := uow.db.Tx(func(tx *database.Tx) error {
+		return fn(domain.Repositories{
+			Orders:       NewOrdersRepo(tx),
+			Products:     NewProductsRepo(tx),
+			Coupons:      NewCouponsRepo(tx),
+			Reservations: NewReservationsRepo(tx),
+			Webhooks:     NewWebhooksRepo(tx),
+			Outbox:       NewOutboxRepo(tx),
+			APIKeys:      NewAPIKeysRepo(tx),
+			Customers:    NewCustomersRepo(tx),
+		})
+	})
+	if err != nil {
+		logging.FromContext(ctx).Debug("rolled back unit of work", "error", err)
+	}
+	return err
+}
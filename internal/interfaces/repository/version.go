@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"encoding/json"
+	"simple-order-service/internal/domain"
+)
+
+// checkStoredVersion compares the version of the entity about to be stored with the version of the
+// value currently stored against its key, so that an entity read before a concurrent write cannot
+// overwrite that write.
+func checkStoredVersion(current []byte, version int) error {
+	stored := struct {
+		Version int `json:"version"`
+	}{}
+	if current != nil {
+		if err := json.Unmarshal(current, &stored); err != nil {
+			return err
+		}
+	}
+	return domain.CheckVersion(version, stored.Version)
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/database"
+	"simple-order-service/pkg/logging"
+)
+
+const WebhooksSchema = "webhooks"
+
+type webhooksRepo struct {
+	dbClient database.Store
+}
+
+func NewWebhooksRepo(db database.Store) webhooksRepo {
+	return webhooksRepo{dbClient: db}
+}
+
+func (hookRepo webhooksRepo) Store(ctx context.Context, webhook domain.Webhook) error {
+	logger := logging.FromContext(ctx).With("webhook_id", webhook.ID())
+	data, err := webhook.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := hookRepo.dbClient.Put([]byte(WebhooksSchema), []byte(webhook.ID()), data); err != nil {
+		logger.Warn("could not store webhook", "error", err)
+		return err
+	}
+	logger.Debug("stored webhook")
+	return nil
+}
+
+func (hookRepo webhooksRepo) FindById(ctx context.Context, id string) domain.Webhook {
+	webhook := &domain.Webhook{}
+	data := hookRepo.dbClient.Get([]byte(WebhooksSchema), []byte(id))
+	logging.FromContext(ctx).Debug("read webhook", "webhook_id", id, "found", data != nil)
+	if data == nil {
+		return *webhook
+	}
+	webhook.UnmarshalJSON(data)
+	return *webhook
+}
+
+func (hookRepo webhooksRepo) GetAll(ctx context.Context) []domain.Webhook {
+	data := hookRepo.dbClient.GetAll([]byte(WebhooksSchema))
+	webhooks := make([]domain.Webhook, len(data))
+	for idx, val := range data {
+		webhook := &domain.Webhook{}
+		webhook.UnmarshalJSON(val)
+		webhooks[idx] = *webhook
+	}
+	return webhooks
+}
+
+func (hookRepo webhooksRepo) Delete(ctx context.Context, id string) error {
+	logging.FromContext(ctx).Debug("deleted webhook", "webhook_id", id)
+	return hookRepo.dbClient.Delete([]byte(WebhooksSchema), []byte(id))
+}
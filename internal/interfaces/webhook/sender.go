@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"simple-order-service/internal/domain"
+	"syscall"
+	"time"
+)
+
+// HTTPSender posts the events of orders to webhooks. A webhook accepts an event by answering with a
+// 2xx status code.
+//
+// Webhooks may not reach loopback, link-local or private addresses, except on the allowed hosts. The
+// address is checked once the host of the webhook is resolved, when the connection is made, so that a
+// name which resolves to such an address is refused too. Redirects are not followed: a webhook which
+// answers with one has not accepted the event.
+type HTTPSender struct {
+	client       *http.Client
+	publicClient *http.Client
+	allowedHosts []string
+}
+
+func NewHTTPSender(timeout time.Duration, allowedHosts []string) *HTTPSender {
+	return &HTTPSender{
+		client:       newClient(timeout, &net.Dialer{Timeout: timeout}),
+		publicClient: newClient(timeout, &net.Dialer{Timeout: timeout, Control: refuseInternalAddresses}),
+		allowedHosts: allowedHosts,
+	}
+}
+
+// newClient returns a client with a transport of its own, which connects with the dialer and does not
+// go through a proxy, so that the address the dialer checks is the one of the webhook.
+func newClient(timeout time.Duration, dialer *net.Dialer) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (sender *HTTPSender) Send(target string, payload []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := sender.clientFor(req.URL).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (sender *HTTPSender) clientFor(target *url.URL) *http.Client {
+	if domain.IsAllowedWebhookHost(target.Hostname(), sender.allowedHosts) {
+		return sender.client
+	}
+	return sender.publicClient
+}
+
+// refuseInternalAddresses is called with the address a connection is about to be made to, once the
+// host has been resolved.
+func refuseInternalAddresses(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || domain.IsInternalAddress(ip) {
+		return fmt.Errorf("%w: %s", domain.ErrInternalWebhookURL, host)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/logging"
+	"strings"
+)
+
+// APIKeyHeader carries the API key of a request. Requests can be authenticated with a bearer token in
+// the Authorization header instead, which is obtained by exchanging an API key at /tokens.
+const APIKeyHeader = "X-API-Key"
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, apiKey string) (domain.Principal, error)
+	IssueToken(principal domain.Principal) (usecases.Token, error)
+	VerifyToken(bearer string) (domain.Principal, error)
+}
+
+// The roles allowed on the routes of the API. Customers place their own orders, operators fulfil
+// orders, and admins manage the catalogue, the coupons and the webhooks.
+var (
+	anyRole       = []domain.Role{domain.RoleCustomer, domain.RoleOperator, domain.RoleAdmin}
+	customerRoles = []domain.Role{domain.RoleCustomer, domain.RoleAdmin}
+	staffRoles    = []domain.Role{domain.RoleOperator, domain.RoleAdmin}
+	adminRoles    = []domain.Role{domain.RoleAdmin}
+)
+
+type AuthMiddleware struct {
+	authenticator Authenticator
+}
+
+func NewAuthMiddleware(authenticator Authenticator) *AuthMiddleware {
+	return &AuthMiddleware{authenticator: authenticator}
+}
+
+// Handler authenticates the requests which carry credentials, and passes the principal they are made
+// by down to the interactors in the context of the request. Requests with invalid credentials are
+// rejected; requests without credentials are left to the routes, which reject them unless the route
+// is public.
+func (middleware *AuthMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var principal domain.Principal
+		var err error
+		if bearer, ok := bearerToken(r); ok {
+			principal, err = middleware.authenticator.VerifyToken(bearer)
+		} else if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+			principal, err = middleware.authenticator.Authenticate(r.Context(), apiKey)
+		} else {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err != nil {
+			logRequestError(r, err)
+			writeUnauthorized(w, usecases.ErrInvalidCredentials.Error())
+			return
+		}
+
+		ctx := usecases.WithPrincipal(r.Context(), principal)
+		ctx = logging.With(ctx, "subject", principal.Subject, "role", string(principal.Role))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Require only lets the requests made by a principal with one of the roles through to the handler.
+func Require(handler http.Handler, roles ...domain.Role) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := usecases.PrincipalFrom(r.Context())
+		if !ok {
+			writeUnauthorized(w, "authentication required. send an api key in the "+APIKeyHeader+" header, or a bearer token")
+			return
+		}
+		for _, role := range roles {
+			if principal.Role == role {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "the " + string(principal.Role) + " role is not allowed to do this",
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write(failureResponse.ToJSON())
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, bearer, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(bearer), true
+}
+
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	failureResponse := serializer.Response{
+		Status:  "error",
+		Message: message,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", `Bearer realm="simple-order-service"`)
+	w.WriteHeader(http.StatusUnauthorized)
+	w.Write(failureResponse.ToJSON())
+}
+
+type IssueTokenHandler struct {
+	authenticator Authenticator
+}
+
+func NewIssueTokenHandler(authenticator Authenticator) IssueTokenHandler {
+	return IssueTokenHandler{authenticator: authenticator}
+}
+
+// ServeHTTP exchanges the API key of the request for a bearer token. Only API keys can be exchanged,
+// so that a token cannot be kept alive by exchanging it for a new one.
+func (handler IssueTokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	apiKey := r.Header.Get(APIKeyHeader)
+	if apiKey == "" {
+		writeUnauthorized(w, "an api key is required in the "+APIKeyHeader+" header")
+		return
+	}
+	principal, err := handler.authenticator.Authenticate(r.Context(), apiKey)
+	if err != nil {
+		logRequestError(r, err)
+		writeUnauthorized(w, usecases.ErrInvalidCredentials.Error())
+		return
+	}
+
+	issued, err := handler.authenticator.IssueToken(principal)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(issued)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(responseJSON)
+}
@@ -0,0 +1,35 @@
+package webservice_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/webservice"
+	"simple-order-service/internal/serializer"
+	"testing"
+)
+
+// revokedAuthenticator refuses every API key as a revoked one.
+type revokedAuthenticator struct {
+	roleAuthenticator
+}
+
+func (revokedAuthenticator) Authenticate(ctx context.Context, apiKey string) (domain.Principal, error) {
+	return domain.Principal{}, errors.New("api key k-1 has been revoked")
+}
+
+func TestIssueTokenHandler_DoesNotTellWhyTheKeyIsRefused(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/auth/token", nil)
+	r.Header.Set(webservice.APIKeyHeader, "sos_k-1_secret")
+	w := httptest.NewRecorder()
+	webservice.NewIssueTokenHandler(revokedAuthenticator{}).ServeHTTP(w, r)
+
+	var response serializer.Response
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if w.Code != http.StatusUnauthorized || response.Message != "invalid credentials" {
+		t.Errorf("Got: %v %q, Want: %v %q", w.Code, response.Message, http.StatusUnauthorized, "invalid credentials")
+	}
+}
@@ -0,0 +1,34 @@
+package webservice
+
+import (
+	"errors"
+	"net/http"
+	"simple-order-service/internal/serializer"
+)
+
+// BodyLimitMiddleware caps the size of the bodies of requests at maxBytes, so that no client can make
+// the server buffer an unbounded body. Reading past the limit fails with an *http.MaxBytesError, which
+// the middlewares reading the body answer with 413.
+func BodyLimitMiddleware(maxBytes int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+func writeBodyTooLarge(w http.ResponseWriter) {
+	failureResponse := serializer.Response{
+		Status:  "error",
+		Message: "request body is too large",
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	w.Write(failureResponse.ToJSON())
+}
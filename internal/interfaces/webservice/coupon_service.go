@@ -0,0 +1,164 @@
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+
+	"github.com/gorilla/mux"
+)
+
+type CouponInteractor interface {
+	Create(ctx context.Context, input usecases.Coupon) (usecases.Coupon, error)
+	GetDetails(ctx context.Context, code string) (usecases.Coupon, error)
+	GetAll(ctx context.Context) []usecases.Coupon
+}
+
+type CreateCouponHandler struct {
+	couponInteractor CouponInteractor
+}
+
+type GetCouponDetailsHandler struct {
+	couponInteractor CouponInteractor
+}
+
+type GetAllCouponsHandler struct {
+	couponInteractor CouponInteractor
+}
+
+func NewCreateCouponHandler(couponInteractor CouponInteractor) CreateCouponHandler {
+	return CreateCouponHandler{couponInteractor: couponInteractor}
+}
+
+func NewGetCouponDetailsHandler(couponInteractor CouponInteractor) GetCouponDetailsHandler {
+	return GetCouponDetailsHandler{couponInteractor: couponInteractor}
+}
+
+func NewGetAllCouponsHandler(couponInteractor CouponInteractor) GetAllCouponsHandler {
+	return GetAllCouponsHandler{couponInteractor: couponInteractor}
+}
+
+// couponErrorStatus maps the errors returned by the coupon interactor to HTTP status codes.
+func couponErrorStatus(err error) int {
+	var couponErr *domain.CouponError
+	switch {
+	case errors.Is(err, usecases.ErrCouponNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrCouponAlreadyExists):
+		return http.StatusConflict
+	case errors.As(err, &couponErr):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (handler CreateCouponHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	decoder := json.NewDecoder(r.Body)
+
+	var req serializer.CreateCouponRequest
+	if err := decoder.Decode(&req); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "unable to parse JSON data",
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	coupon, err := handler.couponInteractor.Create(r.Context(), usecases.Coupon{
+		Code:           req.Code,
+		DiscountType:   req.DiscountType,
+		Value:          req.Value,
+		ValidFrom:      req.ValidFrom,
+		ValidUntil:     req.ValidUntil,
+		MaxRedemptions: req.MaxRedemptions,
+		Stackable:      req.Stackable,
+	})
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(couponErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(coupon)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Header().Set("Location", "/coupons/"+coupon.Code)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(responseJSON)
+}
+
+func (handler GetCouponDetailsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	code := vars["code"]
+
+	coupon, err := handler.couponInteractor.GetDetails(r.Context(), code)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(couponErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(coupon)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Write(responseJSON)
+}
+
+func (handler GetAllCouponsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	coupons := handler.couponInteractor.GetAll(r.Context())
+
+	responseJSON, err := json.Marshal(coupons)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Write(responseJSON)
+}
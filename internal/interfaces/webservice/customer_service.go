@@ -0,0 +1,219 @@
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+
+	"github.com/gorilla/mux"
+)
+
+type CustomerInteractor interface {
+	Create(ctx context.Context, input usecases.Customer) (usecases.Customer, error)
+	GetDetails(ctx context.Context, id string) (usecases.Customer, error)
+	GetAll(ctx context.Context) []usecases.Customer
+}
+
+type CreateCustomerHandler struct {
+	customerInteractor CustomerInteractor
+}
+
+type GetCustomerDetailsHandler struct {
+	customerInteractor CustomerInteractor
+}
+
+type GetAllCustomersHandler struct {
+	customerInteractor CustomerInteractor
+}
+
+type GetCustomerOrdersHandler struct {
+	customerInteractor CustomerInteractor
+	orderInteractor    OrderInteractor
+}
+
+func NewCreateCustomerHandler(customerInteractor CustomerInteractor) CreateCustomerHandler {
+	return CreateCustomerHandler{customerInteractor: customerInteractor}
+}
+
+func NewGetCustomerDetailsHandler(customerInteractor CustomerInteractor) GetCustomerDetailsHandler {
+	return GetCustomerDetailsHandler{customerInteractor: customerInteractor}
+}
+
+func NewGetAllCustomersHandler(customerInteractor CustomerInteractor) GetAllCustomersHandler {
+	return GetAllCustomersHandler{customerInteractor: customerInteractor}
+}
+
+func NewGetCustomerOrdersHandler(customerInteractor CustomerInteractor, orderInteractor OrderInteractor) GetCustomerOrdersHandler {
+	return GetCustomerOrdersHandler{customerInteractor: customerInteractor, orderInteractor: orderInteractor}
+}
+
+// customerErrorStatus maps the errors returned by the customer interactor to HTTP status codes.
+func customerErrorStatus(err error) int {
+	var customerErr *domain.CustomerError
+	switch {
+	case errors.Is(err, usecases.ErrCustomerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrCustomerEmailTaken):
+		return http.StatusConflict
+	case errors.Is(err, usecases.ErrForbidden):
+		return http.StatusForbidden
+	case errors.As(err, &customerErr):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (handler CreateCustomerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	decoder := json.NewDecoder(r.Body)
+
+	var req serializer.CreateCustomerRequest
+	if err := decoder.Decode(&req); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "unable to parse JSON data",
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	customer, err := handler.customerInteractor.Create(r.Context(), usecases.Customer{
+		Name:    req.Name,
+		Email:   req.Email,
+		Contact: req.Contact,
+	})
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(customerErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(customer)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Header().Set("Location", "/customers/"+customer.ID)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(responseJSON)
+}
+
+func (handler GetCustomerDetailsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	customer, err := handler.customerInteractor.GetDetails(r.Context(), customerID)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(customerErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(customer)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Write(responseJSON)
+}
+
+func (handler GetAllCustomersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	customers := handler.customerInteractor.GetAll(r.Context())
+
+	responseJSON, err := json.Marshal(customers)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Write(responseJSON)
+}
+
+// ServeHTTP lists the orders of the customer a page at a time, the same way the orders are listed at
+// /orders. The customer is looked up first, so that an unknown customer is told apart from one
+// without orders.
+func (handler GetCustomerOrdersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	if _, err := handler.customerInteractor.GetDetails(r.Context(), customerID); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(customerErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	query, errs := parseOrderQuery(r.URL.Query())
+	if len(errs) > 0 {
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "invalid query parameters. more details can be found in the 'errors' section",
+			Meta:    &serializer.Meta{Errors: errs},
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+	query.CustomerID = customerID
+
+	orders, nextCursor, err := handler.orderInteractor.List(r.Context(), query)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(listErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	writePage(w, r, orders, nextCursor)
+}
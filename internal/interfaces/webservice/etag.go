@@ -0,0 +1,50 @@
+package webservice
+
+import (
+	"errors"
+	"net/http"
+	"simple-order-service/internal/domain"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidIfMatch = errors.New("invalid If-Match header. it must hold a single ETag returned by the service, or '*'")
+	ErrWeakIfMatch    = errors.New("a weak ETag never matches the If-Match header")
+)
+
+// versionETag turns the version of an order or a product into the strong ETag sent to clients.
+func versionETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion returns the version a conditional request expects the resource to be in, which is
+// domain.AnyVersion for requests without an If-Match header. If-Match compares ETags strongly, so a
+// weak ETag fails the precondition with ErrWeakIfMatch.
+func ifMatchVersion(r *http.Request) (int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return domain.AnyVersion, nil
+	}
+	if strings.HasPrefix(ifMatch, "W/") {
+		return 0, ErrWeakIfMatch
+	}
+	tag, err := strconv.Unquote(ifMatch)
+	if err != nil {
+		return 0, ErrInvalidIfMatch
+	}
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 0 {
+		return 0, ErrInvalidIfMatch
+	}
+	return version, nil
+}
+
+// ifMatchErrorStatus answers a weak ETag with 412, and an If-Match header which cannot be read with
+// 400.
+func ifMatchErrorStatus(err error) int {
+	if errors.Is(err, ErrWeakIfMatch) {
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusBadRequest
+}
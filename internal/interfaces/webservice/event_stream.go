@@ -0,0 +1,109 @@
+package webservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/serializer"
+	"strconv"
+	"time"
+)
+
+// LastEventIDHeader is sent by clients reconnecting to the event stream with the id of the last
+// event they received, so that the stream resumes after it.
+const LastEventIDHeader = "Last-Event-ID"
+
+const (
+	eventStreamBatchSize = 100
+	eventStreamHeartbeat = 15 * time.Second
+)
+
+// EventStreamHandler pushes the changes to orders and to the stock of products as Server-Sent
+// Events. The id of every event is its sequence number in the feed. A client which does not send
+// Last-Event-ID only receives the events which happen after it connected, and one whose last event
+// has since been trimmed from the feed resumes from the oldest event kept. The streams end when the
+// client disconnects or once shutdown is closed.
+type EventStreamHandler struct {
+	feedRepository domain.FeedRepository
+	shutdown       <-chan struct{}
+}
+
+func NewEventStreamHandler(feedRepository domain.FeedRepository, shutdown <-chan struct{}) EventStreamHandler {
+	return EventStreamHandler{feedRepository: feedRepository, shutdown: shutdown}
+}
+
+func (handler EventStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeEventStreamError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	// Watch before reading the last sequence, so that no event committed in between is missed.
+	notifications, stop := handler.feedRepository.Watch()
+	defer stop()
+
+	lastSequence := handler.feedRepository.LastSequence(r.Context())
+	if lastEventID := r.Header.Get(LastEventIDHeader); lastEventID != "" {
+		sequence, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			writeEventStreamError(w, http.StatusBadRequest, "invalid Last-Event-ID header. please provide the id of an event")
+			return
+		}
+		lastSequence = sequence
+	}
+
+	// The stream outlives the write timeout of the server.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		events := handler.feedRepository.Since(r.Context(), lastSequence, eventStreamBatchSize)
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				logRequestError(r, err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data); err != nil {
+				return
+			}
+			lastSequence = event.Sequence
+		}
+		flusher.Flush()
+		if len(events) == eventStreamBatchSize {
+			continue
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-handler.shutdown:
+			return
+		case <-notifications:
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEventStreamError(w http.ResponseWriter, statusCode int, message string) {
+	failureResponse := serializer.Response{
+		Status:  "error",
+		Message: message,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(failureResponse.ToJSON())
+}
@@ -0,0 +1,182 @@
+package webservice_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/webservice"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// feed is a feed held in memory, whose events are numbered from 1.
+type feed struct {
+	mu     sync.Mutex
+	events []domain.FeedEvent
+}
+
+func (feed *feed) append(count int) {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+	for i := 0; i < count; i++ {
+		sequence := uint64(len(feed.events) + 1)
+		feed.events = append(feed.events, domain.FeedEvent{
+			Sequence: sequence,
+			Type:     domain.FeedOrderStatusChanged,
+			At:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			OrderID:  fmt.Sprint(sequence),
+			Status:   domain.OrderPlaced,
+		})
+	}
+}
+
+func (feed *feed) mock() *domain.FeedRepositoryMock {
+	return &domain.FeedRepositoryMock{
+		WatchFunc: func() (<-chan struct{}, func()) {
+			return make(chan struct{}), func() {}
+		},
+		LastSequenceFunc: func(ctx context.Context) uint64 {
+			feed.mu.Lock()
+			defer feed.mu.Unlock()
+			return uint64(len(feed.events))
+		},
+		SinceFunc: func(ctx context.Context, sequence uint64, limit int) []domain.FeedEvent {
+			feed.mu.Lock()
+			defer feed.mu.Unlock()
+			events := feed.events[sequence:]
+			if len(events) > limit {
+				events = events[:limit]
+			}
+			return append([]domain.FeedEvent(nil), events...)
+		},
+	}
+}
+
+// stream connects to the event stream of the feed, with the Last-Event-ID header when lastEventID
+// is not empty, and returns what was written to the stream once the server has shut down and every
+// event of the feed has been written.
+func stream(t *testing.T, feed *feed, lastEventID string) string {
+	t.Helper()
+	shutdown := make(chan struct{})
+	close(shutdown)
+	r := httptest.NewRequest(http.MethodGet, "/events", nil)
+	if lastEventID != "" {
+		r.Header.Set(webservice.LastEventIDHeader, lastEventID)
+	}
+	w := httptest.NewRecorder()
+	webservice.NewEventStreamHandler(feed.mock(), shutdown).ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Got: %v, Want: %v", w.Code, http.StatusOK)
+	}
+	return w.Body.String()
+}
+
+// eventIDs returns the ids of the events of a stream, in the order they were sent.
+func eventIDs(t *testing.T, body string) []uint64 {
+	t.Helper()
+	ids := make([]uint64, 0)
+	for _, frame := range strings.SplitAfter(body, "\n\n") {
+		if frame == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimPrefix(strings.SplitN(frame, "\n", 2)[0], "id: "), 10, 64)
+		if err != nil {
+			t.Fatalf("Got: %q, Want: a frame starting with the id of its event", frame)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestEventStreamHandler_FramesEveryEventWithItsIdTypeAndData(t *testing.T) {
+	feed := &feed{}
+	feed.append(2)
+	body := stream(t, feed, "0")
+
+	frames := strings.SplitAfter(body, "\n\n")
+	if len(frames) != 3 || frames[2] != "" {
+		t.Fatalf("Got: %q, Want: 2 frames, each ended by an empty line", body)
+	}
+	for idx, event := range feed.events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data)
+		if frames[idx] != want {
+			t.Errorf("Got: %q, Want: %q", frames[idx], want)
+		}
+	}
+}
+
+func TestEventStreamHandler_ResumesAfterTheLastEventID(t *testing.T) {
+	feed := &feed{}
+	// More events than are read in a batch.
+	feed.append(150)
+
+	// A client which does not send Last-Event-ID only receives the events which happen after it
+	// connected.
+	if ids := eventIDs(t, stream(t, feed, "")); len(ids) != 0 {
+		t.Errorf("Got: %v, Want: no events", ids)
+	}
+
+	received := eventIDs(t, stream(t, feed, "0"))
+	feed.append(150)
+	// The client reconnects with the id of the last event it received.
+	lastEventID := strconv.FormatUint(received[len(received)-1], 10)
+	received = append(received, eventIDs(t, stream(t, feed, lastEventID))...)
+
+	if len(received) != 300 {
+		t.Fatalf("Got: %v events, Want: %v", len(received), 300)
+	}
+	for idx, id := range received {
+		if id != uint64(idx+1) {
+			t.Fatalf("Got: event %v after %v events, Want: event %v", id, idx, idx+1)
+		}
+	}
+}
+
+func TestEventStreamHandler_EndsStreamOnShutdown(t *testing.T) {
+	feedRepoMock := &domain.FeedRepositoryMock{
+		WatchFunc: func() (<-chan struct{}, func()) {
+			return make(chan struct{}), func() {}
+		},
+		LastSequenceFunc: func(ctx context.Context) uint64 {
+			return 0
+		},
+		SinceFunc: func(ctx context.Context, sequence uint64, limit int) []domain.FeedEvent {
+			return nil
+		},
+	}
+	shutdown := make(chan struct{})
+	handler := webservice.NewEventStreamHandler(feedRepoMock, shutdown)
+
+	done := make(chan struct{})
+	w := httptest.NewRecorder()
+	go func() {
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("the stream must stay open until the server shuts down")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(shutdown)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the stream must end once the server shuts down")
+	}
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Got: %v %v, Want: %v text/event-stream", w.Code, w.Header().Get("Content-Type"), http.StatusOK)
+	}
+}
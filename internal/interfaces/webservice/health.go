@@ -0,0 +1,158 @@
+package webservice
+
+import (
+	"net/http"
+	"simple-order-service/internal/serializer"
+	"sync"
+)
+
+type Phase string
+
+const (
+	// PhaseStarting is the phase of the service while the storage is migrated.
+	PhaseStarting Phase = "starting"
+	PhaseReady    Phase = "ready"
+	// PhaseDraining is the phase of the service once it has been asked to shut down.
+	PhaseDraining Phase = "draining"
+)
+
+// WorkerStatus reports, by name, whether each background worker is running.
+type WorkerStatus interface {
+	Status() map[string]bool
+}
+
+type healthCheck struct {
+	name  string
+	check func() error
+}
+
+// Health tracks the phase of the service and the checks of its dependencies. The service is ready
+// when it is in PhaseReady, every check passes and every worker is running.
+type Health struct {
+	mu      sync.RWMutex
+	phase   Phase
+	checks  []healthCheck
+	workers WorkerStatus
+}
+
+func NewHealth() *Health {
+	return &Health{phase: PhaseStarting}
+}
+
+func (health *Health) SetPhase(phase Phase) {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+	health.phase = phase
+}
+
+func (health *Health) Phase() Phase {
+	health.mu.RLock()
+	defer health.mu.RUnlock()
+	return health.phase
+}
+
+func (health *Health) AddCheck(name string, check func() error) {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+	health.checks = append(health.checks, healthCheck{name: name, check: check})
+}
+
+func (health *Health) SetWorkers(workers WorkerStatus) {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+	health.workers = workers
+}
+
+// Readiness runs the checks and returns the report along with whether the service is ready.
+func (health *Health) Readiness() (serializer.HealthResponse, bool) {
+	health.mu.RLock()
+	phase, checks, workers := health.phase, health.checks, health.workers
+	health.mu.RUnlock()
+
+	ready := phase == PhaseReady
+	report := serializer.HealthResponse{Status: string(phase), Checks: make(map[string]string)}
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			report.Checks[c.name] = err.Error()
+			ready = false
+			continue
+		}
+		report.Checks[c.name] = "ok"
+	}
+	if workers != nil {
+		report.Workers = make(map[string]string)
+		for name, running := range workers.Status() {
+			if !running {
+				report.Workers[name] = "stopped"
+				ready = false
+				continue
+			}
+			report.Workers[name] = "running"
+		}
+	}
+	if phase == PhaseReady && !ready {
+		report.Status = "unavailable"
+	}
+	return report, ready
+}
+
+type LivenessHandler struct{}
+
+type ReadinessHandler struct {
+	health *Health
+}
+
+func NewLivenessHandler() LivenessHandler {
+	return LivenessHandler{}
+}
+
+func NewReadinessHandler(health *Health) ReadinessHandler {
+	return ReadinessHandler{health: health}
+}
+
+// ServeHTTP reports that the process is alive and able to serve requests, whatever the state of its
+// dependencies.
+func (handler LivenessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	response := serializer.HealthResponse{Status: "ok"}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response.ToJSON())
+}
+
+// ServeHTTP reports whether the service can take traffic, with 503 while it is starting, draining or
+// when one of its dependencies fails.
+func (handler ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	report, ready := handler.health.Readiness()
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.Write(report.ToJSON())
+}
+
+// Gate rejects the requests made to the API with 503 until the service has started, so that nothing
+// reads the storage before it is migrated. The health and metrics endpoints are always served.
+func (health *Health) Gate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if health.Phase() == PhaseStarting && !isHealthPath(r.URL.Path) {
+			w.Header().Set("Retry-After", "1")
+			failureResponse := serializer.Response{
+				Status:  "error",
+				Message: "service is starting. please retry later",
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write(failureResponse.ToJSON())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isHealthPath(path string) bool {
+	return path == "/healthz" || path == "/readyz" || path == "/ping" || path == "/metrics"
+}
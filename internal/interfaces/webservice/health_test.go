@@ -0,0 +1,118 @@
+package webservice_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-service/internal/interfaces/webservice"
+	"simple-order-service/internal/serializer"
+	"testing"
+)
+
+type workerStatus map[string]bool
+
+func (status workerStatus) Status() map[string]bool {
+	return status
+}
+
+func readiness(health *webservice.Health) (int, serializer.HealthResponse) {
+	w := httptest.NewRecorder()
+	webservice.NewReadinessHandler(health).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report serializer.HealthResponse
+	json.Unmarshal(w.Body.Bytes(), &report)
+	return w.Code, report
+}
+
+func TestReadinessHandler_FollowsThePhases(t *testing.T) {
+	health := webservice.NewHealth()
+	tests := []struct {
+		phase      webservice.Phase
+		wantCode   int
+		wantStatus string
+	}{
+		{webservice.PhaseStarting, http.StatusServiceUnavailable, "starting"},
+		{webservice.PhaseReady, http.StatusOK, "ready"},
+		{webservice.PhaseDraining, http.StatusServiceUnavailable, "draining"},
+	}
+	for _, tt := range tests {
+		health.SetPhase(tt.phase)
+		if got := health.Phase(); got != tt.phase {
+			t.Errorf("Got: %v, Want: %v", got, tt.phase)
+		}
+		if code, report := readiness(health); code != tt.wantCode || report.Status != tt.wantStatus {
+			t.Errorf("Got: %v %v, Want: %v %v", code, report.Status, tt.wantCode, tt.wantStatus)
+		}
+	}
+}
+
+func TestReadinessHandler_IsUnavailableWhenACheckFails(t *testing.T) {
+	health := webservice.NewHealth()
+	health.SetPhase(webservice.PhaseReady)
+	health.AddCheck("storage", func() error { return nil })
+	health.AddCheck("cache", func() error { return errors.New("cache is down") })
+
+	code, report := readiness(health)
+	if code != http.StatusServiceUnavailable || report.Status != "unavailable" {
+		t.Errorf("Got: %v %v, Want: %v %v", code, report.Status, http.StatusServiceUnavailable, "unavailable")
+	}
+	if report.Checks["storage"] != "ok" || report.Checks["cache"] != "cache is down" {
+		t.Errorf("Got: %v, Want: storage ok and the error of the cache", report.Checks)
+	}
+}
+
+func TestReadinessHandler_IsUnavailableWhenAWorkerIsStopped(t *testing.T) {
+	health := webservice.NewHealth()
+	health.SetPhase(webservice.PhaseReady)
+	health.SetWorkers(workerStatus{"sweeper": true, "dispatcher": false})
+
+	code, report := readiness(health)
+	if code != http.StatusServiceUnavailable || report.Status != "unavailable" {
+		t.Errorf("Got: %v %v, Want: %v %v", code, report.Status, http.StatusServiceUnavailable, "unavailable")
+	}
+	if report.Workers["sweeper"] != "running" || report.Workers["dispatcher"] != "stopped" {
+		t.Errorf("Got: %v, Want: the sweeper running and the dispatcher stopped", report.Workers)
+	}
+
+	health.SetWorkers(workerStatus{"sweeper": true, "dispatcher": true})
+	if code, _ := readiness(health); code != http.StatusOK {
+		t.Errorf("Got: %v, Want: %v", code, http.StatusOK)
+	}
+}
+
+func TestLivenessHandler_IsAliveWhateverThePhase(t *testing.T) {
+	w := httptest.NewRecorder()
+	webservice.NewLivenessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Got: %v, Want: %v", w.Code, http.StatusOK)
+	}
+}
+
+func TestGate_RejectsTheAPIUntilTheServiceHasStarted(t *testing.T) {
+	health := webservice.NewHealth()
+	handler := health.Gate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := serve("/orders")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Got: %v with Retry-After %q, Want: %v with Retry-After %q", w.Code, w.Header().Get("Retry-After"), http.StatusServiceUnavailable, "1")
+	}
+	for _, path := range []string{"/healthz", "/readyz", "/ping", "/metrics"} {
+		if w := serve(path); w.Code != http.StatusNoContent {
+			t.Errorf("Got: %v for %v, Want: %v", w.Code, path, http.StatusNoContent)
+		}
+	}
+
+	for _, phase := range []webservice.Phase{webservice.PhaseReady, webservice.PhaseDraining} {
+		health.SetPhase(phase)
+		if w := serve("/orders"); w.Code != http.StatusNoContent {
+			t.Errorf("Got: %v while %v, Want: %v", w.Code, phase, http.StatusNoContent)
+		}
+	}
+}
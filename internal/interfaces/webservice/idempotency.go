@@ -0,0 +1,236 @@
+package webservice
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/logging"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader lets clients retry a mutating request safely: the first response given to a
+// key is stored and replayed for every retry of the same request with that key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on the responses which are replayed from a previous request.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyMiddleware stores the responses given to mutating requests which carry an idempotency
+// key for ttl, and purges the expired ones every purgeInterval.
+type IdempotencyMiddleware struct {
+	repository    domain.IdempotencyRepository
+	ttl           time.Duration
+	purgeInterval time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+func NewIdempotencyMiddleware(repository domain.IdempotencyRepository, ttl, purgeInterval time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		repository:    repository,
+		ttl:           ttl,
+		purgeInterval: purgeInterval,
+		inFlight:      make(map[string]bool),
+	}
+}
+
+// Handler replays the stored response of a request made again with the same idempotency key. A key
+// reused for a different request is rejected with 422, and a key whose first request is still being
+// handled is rejected with 409 so that the client retries later. Keys are scoped by the principal the
+// request is made by, so that nobody is replayed the response made to somebody else.
+//
+// The response is stored once the handler has committed its changes, in a transaction of its own. A
+// crash in between leaves the changes without a stored response, and a retry is then applied again;
+// the retries which must not be applied twice are to be made conditional with If-Match.
+//
+// Responses marked Cache-Control: no-store, such as the one holding the secret of a new webhook, are
+// stored without their body: a retry is replayed the status and the headers only.
+func (middleware *IdempotencyMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeIdempotencyError(w, http.StatusBadRequest, "idempotency key must be at most 255 characters long")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if isBodyTooLarge(err) {
+			writeBodyTooLarge(w)
+			return
+		}
+		if err != nil {
+			logRequestError(r, err)
+			writeIdempotencyError(w, http.StatusBadRequest, "unable to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(r, body)
+		key = scopedIdempotencyKey(r, key)
+
+		record, inFlight := middleware.begin(r.Context(), key)
+		if inFlight {
+			writeIdempotencyError(w, http.StatusConflict, "a request with the same idempotency key is still being processed")
+			return
+		}
+		if record.Key != "" {
+			if record.Fingerprint != fingerprint {
+				writeIdempotencyError(w, http.StatusUnprocessableEntity, "idempotency key has already been used for a different request")
+				return
+			}
+			replay(w, record)
+			return
+		}
+		defer middleware.end(key)
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Server errors and rate limited requests are not stored, so that the request can be retried
+		// once the error is fixed or the client is allowed to make requests again.
+		if recorder.statusCode >= http.StatusInternalServerError || recorder.statusCode == http.StatusTooManyRequests {
+			return
+		}
+		record = domain.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: fingerprint,
+			StatusCode:  recorder.statusCode,
+			Header:      recorder.Header().Clone(),
+			Body:        recorder.body.Bytes(),
+			ExpiresAt:   time.Now().UTC().Add(middleware.ttl),
+		}
+		if strings.Contains(recorder.Header().Get("Cache-Control"), "no-store") {
+			record.Body = nil
+			delete(record.Header, "Content-Type")
+		}
+		err = middleware.repository.Store(r.Context(), record)
+		if err != nil {
+			logRequestError(r, err)
+		}
+	})
+}
+
+// begin returns the live record stored for the key, or leaves the key marked as in flight when there
+// is none. The key is marked before the record is read, so that the lock is not held while reading
+// from the database and a record stored in the meantime is not missed.
+func (middleware *IdempotencyMiddleware) begin(ctx context.Context, key string) (domain.IdempotencyRecord, bool) {
+	middleware.mu.Lock()
+	if middleware.inFlight[key] {
+		middleware.mu.Unlock()
+		return domain.IdempotencyRecord{}, true
+	}
+	middleware.inFlight[key] = true
+	middleware.mu.Unlock()
+
+	record := middleware.repository.FindByKey(ctx, key)
+	if record.Key != "" && !record.IsExpired(time.Now()) {
+		middleware.end(key)
+		return record, false
+	}
+	return domain.IdempotencyRecord{}, false
+}
+
+func (middleware *IdempotencyMiddleware) end(key string) {
+	middleware.mu.Lock()
+	defer middleware.mu.Unlock()
+	delete(middleware.inFlight, key)
+}
+
+// Run purges the expired idempotency records every purgeInterval until the context is done.
+func (middleware *IdempotencyMiddleware) Run(ctx context.Context) {
+	logger := logging.Default().With("worker", "idempotency_purge")
+	ctx = logging.NewContext(ctx, logger)
+	ticker := time.NewTicker(middleware.purgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case at := <-ticker.C:
+			if _, err := middleware.repository.DeleteExpired(ctx, at.UTC()); err != nil {
+				logger.Error("could not purge expired idempotency keys", "error", err)
+			}
+		}
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// scopedIdempotencyKey is the key the response to the request is stored under: the idempotency key
+// of the request, prefixed with the principal the request is made by. Header values cannot hold a
+// line break, which therefore separates the two.
+func scopedIdempotencyKey(r *http.Request, key string) string {
+	principal, _ := usecases.PrincipalFrom(r.Context())
+	return string(principal.Role) + ":" + principal.Subject + "\n" + key
+}
+
+// requestFingerprint identifies a request by its method, path, query, precondition and body, so that
+// a key reused for a different request can be told apart from a retry.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+"\n")
+	io.WriteString(hash, "If-Match: "+r.Header.Get("If-Match")+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, record domain.IdempotencyRecord) {
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+func writeIdempotencyError(w http.ResponseWriter, statusCode int, message string) {
+	failureResponse := serializer.Response{
+		Status:  "error",
+		Message: message,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(failureResponse.ToJSON())
+}
+
+// responseRecorder passes a response through to the client while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (recorder *responseRecorder) WriteHeader(statusCode int) {
+	if !recorder.wroteHeader {
+		recorder.statusCode = statusCode
+		recorder.wroteHeader = true
+	}
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	recorder.wroteHeader = true
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
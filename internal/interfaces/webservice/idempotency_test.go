@@ -0,0 +1,215 @@
+package webservice_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/webservice"
+	"simple-order-service/internal/usecases"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newIdempotencyRepoMock() *domain.IdempotencyRepositoryMock {
+	var mu sync.Mutex
+	records := make(map[string]domain.IdempotencyRecord)
+	return &domain.IdempotencyRepositoryMock{
+		StoreFunc: func(ctx context.Context, record domain.IdempotencyRecord) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records[record.Key] = record
+			return nil
+		},
+		FindByKeyFunc: func(ctx context.Context, key string) domain.IdempotencyRecord {
+			mu.Lock()
+			defer mu.Unlock()
+			return records[key]
+		},
+	}
+}
+
+func idempotentRequest(method, target, key, body string, principal domain.Principal) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set(webservice.IdempotencyKeyHeader, key)
+	return r.WithContext(usecases.WithPrincipal(r.Context(), principal))
+}
+
+var customerPrincipal = domain.Principal{Subject: "c-1", Role: domain.RoleCustomer}
+
+func TestIdempotencyMiddleware_ReplaysResponseOfRetry(t *testing.T) {
+	calls := 0
+	handler := webservice.NewIdempotencyMiddleware(newIdempotencyRepoMock(), time.Hour, time.Hour).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls += 1
+		w.Header().Set("Location", "/orders/1")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id": "1"}`)
+	}))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, idempotentRequest(http.MethodPost, "/orders", "key-1", `{}`, customerPrincipal))
+		if w.Code != http.StatusCreated || w.Body.String() != `{"id": "1"}` || w.Header().Get("Location") != "/orders/1" {
+			t.Errorf("Got: %v %q, Want: %v %q", w.Code, w.Body.String(), http.StatusCreated, `{"id": "1"}`)
+		}
+		if replayed := w.Header().Get(webservice.IdempotentReplayedHeader) == "true"; replayed != (i == 1) {
+			t.Errorf("Got: replayed %v, Want: %v", replayed, i == 1)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Got: %v calls, Want: 1", calls)
+	}
+}
+
+func TestIdempotencyMiddleware_RejectsKeyReusedForDifferentRequest(t *testing.T) {
+	handler := webservice.NewIdempotencyMiddleware(newIdempotencyRepoMock(), time.Hour, time.Hour).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPut, "/orders/1", "key-1", `{"order_status": "placed"}`, customerPrincipal))
+
+	reused := []*http.Request{
+		idempotentRequest(http.MethodPut, "/orders/1", "key-1", `{"order_status": "cancelled"}`, customerPrincipal),
+		idempotentRequest(http.MethodPut, "/orders/1?dry_run=true", "key-1", `{"order_status": "placed"}`, customerPrincipal),
+	}
+	conditional := idempotentRequest(http.MethodPut, "/orders/1", "key-1", `{"order_status": "placed"}`, customerPrincipal)
+	conditional.Header.Set("If-Match", `"2"`)
+	reused = append(reused, conditional)
+
+	for _, r := range reused {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: Got: %v, Want: %v", r.URL, w.Code, http.StatusUnprocessableEntity)
+		}
+	}
+}
+
+func TestIdempotencyMiddleware_DoesNotStoreServerErrors(t *testing.T) {
+	calls := 0
+	handler := webservice.NewIdempotencyMiddleware(newIdempotencyRepoMock(), time.Hour, time.Hour).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls += 1
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	want := []int{http.StatusInternalServerError, http.StatusOK, http.StatusOK}
+	for _, code := range want {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, idempotentRequest(http.MethodPost, "/orders", "key-1", `{}`, customerPrincipal))
+		if w.Code != code {
+			t.Errorf("Got: %v, Want: %v", w.Code, code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Got: %v calls, Want: 2", calls)
+	}
+}
+
+func TestIdempotencyMiddleware_RejectsRequestWhileFirstIsInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := webservice.NewIdempotencyMiddleware(newIdempotencyRepoMock(), time.Hour, time.Hour).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(first, idempotentRequest(http.MethodPost, "/orders", "key-1", `{}`, customerPrincipal))
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest(http.MethodPost, "/orders", "key-1", `{}`, customerPrincipal))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Got: %v, Want: %v", w.Code, http.StatusConflict)
+	}
+
+	close(release)
+	<-done
+	if first.Code != http.StatusCreated {
+		t.Errorf("Got: %v, Want: %v", first.Code, http.StatusCreated)
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest(http.MethodPost, "/orders", "key-1", `{}`, customerPrincipal))
+	if w.Code != http.StatusCreated || w.Header().Get(webservice.IdempotentReplayedHeader) != "true" {
+		t.Errorf("Got: %v, Want: a replayed %v", w.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotencyMiddleware_ScopesKeysByPrincipal(t *testing.T) {
+	calls := 0
+	handler := webservice.NewIdempotencyMiddleware(newIdempotencyRepoMock(), time.Hour, time.Hour).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls += 1
+		principal, _ := usecases.PrincipalFrom(r.Context())
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, principal.Subject)
+	}))
+
+	other := domain.Principal{Subject: "c-2", Role: domain.RoleCustomer}
+	for _, principal := range []domain.Principal{customerPrincipal, other} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, idempotentRequest(http.MethodPost, "/orders", "key-1", `{}`, principal))
+		if w.Body.String() != principal.Subject || w.Header().Get(webservice.IdempotentReplayedHeader) != "" {
+			t.Errorf("Got: %q, Want: a response made to %q", w.Body.String(), principal.Subject)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Got: %v calls, Want: 2", calls)
+	}
+}
+
+func TestIdempotencyMiddleware_DoesNotStoreBodyMarkedNoStore(t *testing.T) {
+	repoMock := newIdempotencyRepoMock()
+	handler := webservice.NewIdempotencyMiddleware(repoMock, time.Hour, time.Hour).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Location", "/webhooks/1")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"secret": "s3cr3t"}`)
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "/webhooks", "key-1", `{}`, customerPrincipal))
+	}
+	if len(repoMock.StoreCalls()) != 1 {
+		t.Fatalf("Got: %v records, Want: 1", len(repoMock.StoreCalls()))
+	}
+	record := repoMock.StoreCalls()[0].Record
+	if len(record.Body) != 0 || record.StatusCode != http.StatusCreated || http.Header(record.Header).Get("Location") != "/webhooks/1" {
+		t.Errorf("Got: %v %q %v, Want: the status and headers without the body", record.StatusCode, record.Body, record.Header)
+	}
+}
+
+func TestIdempotencyMiddleware_RejectsBodiesOverTheLimit(t *testing.T) {
+	repoMock := newIdempotencyRepoMock()
+	calls := 0
+	idempotency := webservice.NewIdempotencyMiddleware(repoMock, time.Hour, time.Hour).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls += 1
+		w.WriteHeader(http.StatusCreated)
+	}))
+	handler := webservice.BodyLimitMiddleware(8)(idempotency)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest(http.MethodPost, "/orders", "key-1", `{"customer_id": "1"}`, customerPrincipal))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Got: %v, Want: %v", w.Code, http.StatusRequestEntityTooLarge)
+	}
+	if calls != 0 || len(repoMock.StoreCalls()) != 0 {
+		t.Errorf("Got: %v calls and %v stored responses, Want: none", calls, len(repoMock.StoreCalls()))
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest(http.MethodPost, "/orders", "key-2", `{}`, customerPrincipal))
+	if w.Code != http.StatusCreated {
+		t.Errorf("Got: %v, Want: %v", w.Code, http.StatusCreated)
+	}
+}
@@ -0,0 +1,84 @@
+package webservice
+
+import (
+	"net/http"
+	"simple-order-service/pkg/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	httpRequests = metrics.Default.NewCounterVec("http_requests_total",
+		"HTTP requests handled, by method, route and status code.", "method", "route", "code")
+	httpRequestDuration = metrics.Default.NewHistogramVec("http_request_duration_seconds",
+		"Latency of the HTTP requests, by method and route.", metrics.DefaultBuckets, "method", "route")
+)
+
+// MetricsMiddleware counts the requests made to every route and observes their latency. Requests are
+// labelled with the path template of their route rather than with their path, so that the number of
+// series does not grow with the number of orders and products.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		httpRequests.Inc(r.Method, route, strconv.Itoa(recorder.statusCode))
+		httpRequestDuration.Observe(time.Since(start).Seconds(), r.Method, route)
+	})
+}
+
+type MetricsHandler struct {
+	registry *metrics.Registry
+}
+
+func NewMetricsHandler(registry *metrics.Registry) MetricsHandler {
+	return MetricsHandler{registry: registry}
+}
+
+func (handler MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := handler.registry.Write(w); err != nil {
+		logRequestError(r, err)
+	}
+}
+
+// statusRecorder keeps the status code of a response. It can be flushed, and unwrapped by
+// http.ResponseController, so that event streams still work through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+func (recorder *statusRecorder) WriteHeader(statusCode int) {
+	if !recorder.wroteHeader {
+		recorder.statusCode = statusCode
+		recorder.wroteHeader = true
+	}
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *statusRecorder) Write(data []byte) (int, error) {
+	recorder.wroteHeader = true
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (recorder *statusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
@@ -0,0 +1,968 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/openapi"
+)
+
+// APIVersion is the version of the API described by the OpenAPI document.
+const APIVersion = "1.0.0"
+
+const jsonContentType = "application/json"
+
+// NewAPIDocument describes every route set up by SetupRoutes, with the quantities and page sizes
+// allowed by the limits.
+func NewAPIDocument(limits domain.Limits) *openapi.Document {
+	return &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title: "Simple order service",
+			Description: "Customers place orders for the products of the catalogue, which operators then fulfil. " +
+				"Requests are authenticated with an API key in the " + APIKeyHeader + " header, or with a bearer token " +
+				"obtained by exchanging an API key at /tokens. The roles allowed to call an operation are listed in its x-roles.",
+			Version: APIVersion,
+		},
+		Paths: map[string]*openapi.PathItem{
+			"/ping":         {Get: pingOperation()},
+			"/healthz":      {Get: livenessOperation()},
+			"/readyz":       {Get: readinessOperation()},
+			"/metrics":      {Get: metricsOperation()},
+			"/openapi.json": {Get: openAPIOperation()},
+			"/tokens":       {Post: issueTokenOperation()},
+			"/events":       {Get: eventStreamOperation()},
+			"/orders": {
+				Get:  listOrdersOperation(),
+				Post: createOrderOperation(),
+			},
+			"/orders/{id}": {
+				Get: getOrderOperation(),
+				Put: updateOrderOperation(),
+			},
+			"/orders/{id}/products": {
+				Get:  getOrderedProductsOperation(),
+				Post: addProductToOrderOperation(),
+			},
+			"/orders/{id}/history":        {Get: getOrderHistoryOperation()},
+			"/orders/{id}/coupons":        {Post: applyCouponOperation()},
+			"/orders/{id}/coupons/{code}": {Delete: removeCouponOperation()},
+			"/products": {
+				Get:  listProductsOperation(),
+				Post: createProductOperation(),
+			},
+			"/products/{id}": {
+				Get:    getProductOperation(),
+				Put:    replaceProductOperation(),
+				Patch:  updateProductOperation(),
+				Delete: deleteProductOperation(),
+			},
+			"/customers": {
+				Get:  listCustomersOperation(),
+				Post: createCustomerOperation(),
+			},
+			"/customers/{id}":           {Get: getCustomerOperation()},
+			"/customers/{id}/orders":    {Get: listCustomerOrdersOperation()},
+			"/v2/orders":                {Get: pageEnvelopeOperation(listOrdersOperation(), openapi.SchemaRef("Order"))},
+			"/v2/products":              {Get: pageEnvelopeOperation(listProductsOperation(), openapi.SchemaRef("Product"))},
+			"/v2/customers/{id}/orders": {Get: pageEnvelopeOperation(listCustomerOrdersOperation(), openapi.SchemaRef("Order"))},
+			"/coupons": {
+				Get:  listCouponsOperation(),
+				Post: createCouponOperation(),
+			},
+			"/coupons/{code}": {Get: getCouponOperation()},
+			"/webhooks": {
+				Get:  listWebhooksOperation(),
+				Post: createWebhookOperation(),
+			},
+			"/webhooks/{id}": {
+				Get:    getWebhookOperation(),
+				Put:    replaceWebhookOperation(),
+				Delete: deleteWebhookOperation(),
+			},
+			"/webhooks/{id}/dead_letters": {Get: listDeadLettersOperation()},
+		},
+		Components: openapi.Components{
+			Schemas:    apiSchemas(limits),
+			Parameters: apiParameters(limits),
+			Responses:  apiResponses(),
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				"apiKey": {Type: "apiKey", In: "header", Name: APIKeyHeader, Description: "An API key created with the auth:keys:create command."},
+				"bearer": {Type: "http", Scheme: "bearer", Description: "A token issued at /tokens in exchange for an API key."},
+			},
+		},
+	}
+}
+
+// OpenAPIHandler serves the OpenAPI document of the API.
+type OpenAPIHandler struct {
+	document []byte
+}
+
+func NewOpenAPIHandler(document *openapi.Document) OpenAPIHandler {
+	data, _ := json.Marshal(document)
+	return OpenAPIHandler{document: data}
+}
+
+func (handler OpenAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.Write(handler.document)
+}
+
+func pingOperation() *openapi.Operation {
+	return &openapi.Operation{
+		OperationID: "ping",
+		Summary:     "Check that the service answers",
+		Tags:        []string{"operations"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The service answers.", objectSchema(nil, map[string]*openapi.Schema{"status": stringSchema("")})),
+		},
+	}
+}
+
+func livenessOperation() *openapi.Operation {
+	return &openapi.Operation{
+		OperationID: "checkLiveness",
+		Summary:     "Check that the process is alive",
+		Tags:        []string{"operations"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The process is alive.", openapi.SchemaRef("Health")),
+		},
+	}
+}
+
+func readinessOperation() *openapi.Operation {
+	return &openapi.Operation{
+		OperationID: "checkReadiness",
+		Summary:     "Check that the service can take traffic",
+		Tags:        []string{"operations"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The service is ready.", openapi.SchemaRef("Health")),
+			"503": jsonResponse("The service is starting, draining, or one of its dependencies fails.", openapi.SchemaRef("Health")),
+		},
+	}
+}
+
+func metricsOperation() *openapi.Operation {
+	return &openapi.Operation{
+		OperationID: "getMetrics",
+		Summary:     "Get the metrics of the service in the Prometheus text format",
+		Tags:        []string{"operations"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The metrics.", Content: map[string]*openapi.MediaType{"text/plain": {Schema: stringSchema("")}}},
+		},
+	}
+}
+
+func openAPIOperation() *openapi.Operation {
+	return &openapi.Operation{
+		OperationID: "getOpenAPIDocument",
+		Summary:     "Get this document",
+		Tags:        []string{"operations"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The OpenAPI document of the API.", objectSchema(nil, nil)),
+		},
+	}
+}
+
+func issueTokenOperation() *openapi.Operation {
+	return &openapi.Operation{
+		OperationID: "issueToken",
+		Summary:     "Exchange an API key for a bearer token",
+		Description: "Only API keys can be exchanged, so a token cannot be kept alive by exchanging it for a new one.",
+		Tags:        []string{"auth"},
+		Security:    []map[string][]string{{"apiKey": {}}},
+		Responses: map[string]*openapi.Response{
+			"201": jsonResponse("The token.", openapi.SchemaRef("Token")),
+			"401": openapi.ResponseRef("Unauthorized"),
+		},
+	}
+}
+
+func eventStreamOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "streamEvents",
+		Summary:     "Stream the changes to orders and to the stock of products as Server-Sent Events",
+		Description: "The id of every event is its sequence number. Send the id of the last event received in " +
+			"the " + LastEventIDHeader + " header to resume the stream after it.",
+		Tags: []string{"events"},
+		Parameters: []*openapi.Parameter{
+			{Name: LastEventIDHeader, In: "header", Description: "The id of the last event received.", Schema: stringSchema("")},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "A stream of events, whose data is a FeedEvent.", Content: map[string]*openapi.MediaType{
+				"text/event-stream": {Schema: openapi.SchemaRef("FeedEvent")},
+			}},
+			"400": openapi.ResponseRef("BadRequest"),
+		},
+	}, staffRoles)
+}
+
+func listOrdersOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "listOrders",
+		Summary:     "List the orders a page at a time",
+		Tags:        []string{"orders"},
+		Parameters: append(pageParameters(),
+			openapi.ParameterRef("orderStatus"),
+			&openapi.Parameter{Name: "customer_id", In: "query", Description: "Only list the orders of the customer.", Schema: stringSchema("")},
+		),
+		Responses: map[string]*openapi.Response{
+			"200": pageResponse("A page of orders.", openapi.SchemaRef("Order")),
+			"400": openapi.ResponseRef("BadRequest"),
+		},
+	}, staffRoles)
+}
+
+func createOrderOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "createOrder",
+		Summary:     "Create an empty order under an id generated by the service",
+		Description: "Customers create orders for themselves. Staff may create an order for a customer, or one without a customer.",
+		Tags:        []string{"orders"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("CreateOrderRequest"), false),
+		Responses: map[string]*openapi.Response{
+			"201": createdResponse("The order.", openapi.SchemaRef("Order")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+			"429": openapi.ResponseRef("TooManyRequests"),
+		},
+	}, anyRole)
+}
+
+func getOrderOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "getOrder",
+		Summary:     "Get an order",
+		Tags:        []string{"orders"},
+		Parameters: []*openapi.Parameter{
+			openapi.ParameterRef("id"),
+			{Name: "as_of", In: "query", Description: "Get the order as it was at this time.", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": versionedResponse("The order.", openapi.SchemaRef("Order")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+		},
+	}, anyRole)
+}
+
+func updateOrderOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "updateOrder",
+		Summary:     "Change the status or the dispatch date of an order",
+		Description: "The status is changed before the dispatch date. The response lists the updates which failed in meta.errors. Customers may only place or cancel their own open orders, and may not change the dispatch date.",
+		Tags:        []string{"orders"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id"), openapi.ParameterRef("ifMatch"), openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("UpdateOrderRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The outcome of the updates.", openapi.SchemaRef("Response")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"412": openapi.ResponseRef("PreconditionFailed"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+			"429": openapi.ResponseRef("TooManyRequests"),
+		},
+	}, anyRole)
+}
+
+func getOrderedProductsOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "getOrderedProducts",
+		Summary:     "List the products of an order",
+		Tags:        []string{"orders"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The products of the order.", arraySchema(openapi.SchemaRef("OrderedProduct"))),
+			"404": openapi.ResponseRef("NotFound"),
+		},
+	}, anyRole)
+}
+
+func addProductToOrderOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "addProductToOrder",
+		Summary:     "Add a product to an open order",
+		Description: "The added units are reserved for the order until the reservation expires or the order is placed. Adding a product does not place the order; place it with PUT /orders/{id}.",
+		Tags:        []string{"orders"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id"), openapi.ParameterRef("ifMatch"), openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("AddProductToOrderRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The product was added.", openapi.SchemaRef("Response")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+			"412": openapi.ResponseRef("PreconditionFailed"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+			"429": openapi.ResponseRef("TooManyRequests"),
+		},
+	}, customerRoles)
+}
+
+func getOrderHistoryOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "getOrderHistory",
+		Summary:     "List the status changes of an order",
+		Tags:        []string{"orders"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The status changes, oldest first.", arraySchema(openapi.SchemaRef("StatusTransition"))),
+			"404": openapi.ResponseRef("NotFound"),
+		},
+	}, anyRole)
+}
+
+func applyCouponOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "applyCoupon",
+		Summary:     "Apply a coupon to an open order",
+		Tags:        []string{"orders"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id"), openapi.ParameterRef("ifMatch"), openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("ApplyCouponRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The coupon was applied.", openapi.SchemaRef("Response")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+			"412": openapi.ResponseRef("PreconditionFailed"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+			"429": openapi.ResponseRef("TooManyRequests"),
+		},
+	}, customerRoles)
+}
+
+func removeCouponOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "removeCoupon",
+		Summary:     "Remove a coupon from an open order",
+		Tags:        []string{"orders"},
+		Parameters: []*openapi.Parameter{
+			openapi.ParameterRef("id"),
+			{Name: "code", In: "path", Required: true, Description: "The code of the coupon.", Schema: stringSchema("")},
+			openapi.ParameterRef("ifMatch"),
+			openapi.ParameterRef("idempotencyKey"),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The coupon was removed.", openapi.SchemaRef("Response")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+			"412": openapi.ResponseRef("PreconditionFailed"),
+			"429": openapi.ResponseRef("TooManyRequests"),
+		},
+	}, customerRoles)
+}
+
+func listProductsOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "listProducts",
+		Summary:     "List the products of the catalogue a page at a time",
+		Tags:        []string{"products"},
+		Parameters: append(pageParameters(),
+			&openapi.Parameter{Name: "category", In: "query", Description: "Only list the products of the category.", Schema: enumSchema(productCategories()...)},
+			&openapi.Parameter{Name: "min_price", In: "query", Description: "Only list the products which cost at least this much.", Schema: &openapi.Schema{Type: "number", Minimum: floatPtr(0)}},
+		),
+		Responses: map[string]*openapi.Response{
+			"200": pageResponse("A page of products.", openapi.SchemaRef("Product")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"429": openapi.ResponseRef("TooManyRequests"),
+		},
+	}, anyRole)
+}
+
+func createProductOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "createProduct",
+		Summary:     "Add a product to the catalogue",
+		Tags:        []string{"products"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("CreateProductRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"201": createdResponse("The product.", openapi.SchemaRef("Product")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"409": openapi.ResponseRef("Conflict"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+		},
+	}, adminRoles)
+}
+
+func getProductOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "getProduct",
+		Summary:     "Get a product of the catalogue",
+		Tags:        []string{"products"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id")},
+		Responses: map[string]*openapi.Response{
+			"200": versionedResponse("The product.", openapi.SchemaRef("Product")),
+			"404": openapi.ResponseRef("NotFound"),
+			"429": openapi.ResponseRef("TooManyRequests"),
+		},
+	}, anyRole)
+}
+
+func replaceProductOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "replaceProduct",
+		Summary:     "Replace a product of the catalogue",
+		Tags:        []string{"products"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id"), openapi.ParameterRef("ifMatch"), openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("ReplaceProductRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"200": versionedResponse("The product.", openapi.SchemaRef("Product")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+			"412": openapi.ResponseRef("PreconditionFailed"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+		},
+	}, adminRoles)
+}
+
+func updateProductOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "updateProduct",
+		Summary:     "Change some of the fields of a product of the catalogue",
+		Tags:        []string{"products"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id"), openapi.ParameterRef("ifMatch"), openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("UpdateProductRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"200": versionedResponse("The product.", openapi.SchemaRef("Product")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+			"412": openapi.ResponseRef("PreconditionFailed"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+		},
+	}, adminRoles)
+}
+
+func deleteProductOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "deleteProduct",
+		Summary:     "Remove a product from the catalogue",
+		Tags:        []string{"products"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id"), openapi.ParameterRef("ifMatch"), openapi.ParameterRef("idempotencyKey")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The product was removed.", openapi.SchemaRef("Response")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+			"412": openapi.ResponseRef("PreconditionFailed"),
+		},
+	}, adminRoles)
+}
+
+func listCustomersOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "listCustomers",
+		Summary:     "List the customers",
+		Tags:        []string{"customers"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The customers.", arraySchema(openapi.SchemaRef("Customer"))),
+		},
+	}, staffRoles)
+}
+
+func createCustomerOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "createCustomer",
+		Summary:     "Register a customer",
+		Tags:        []string{"customers"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("CreateCustomerRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"201": createdResponse("The customer.", openapi.SchemaRef("Customer")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"409": openapi.ResponseRef("Conflict"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+		},
+	}, staffRoles)
+}
+
+func getCustomerOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "getCustomer",
+		Summary:     "Get a customer",
+		Description: "Customers may only get their own account.",
+		Tags:        []string{"customers"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The customer.", openapi.SchemaRef("Customer")),
+			"404": openapi.ResponseRef("NotFound"),
+		},
+	}, anyRole)
+}
+
+func listCustomerOrdersOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "listCustomerOrders",
+		Summary:     "List the orders of a customer a page at a time",
+		Description: "Customers may only list their own orders.",
+		Tags:        []string{"customers"},
+		Parameters:  append([]*openapi.Parameter{openapi.ParameterRef("id")}, append(pageParameters(), openapi.ParameterRef("orderStatus"))...),
+		Responses: map[string]*openapi.Response{
+			"200": pageResponse("A page of orders.", openapi.SchemaRef("Order")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+		},
+	}, anyRole)
+}
+
+func listCouponsOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "listCoupons",
+		Summary:     "List the coupons",
+		Tags:        []string{"coupons"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The coupons.", arraySchema(openapi.SchemaRef("Coupon"))),
+		},
+	}, adminRoles)
+}
+
+func createCouponOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "createCoupon",
+		Summary:     "Create a coupon",
+		Tags:        []string{"coupons"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("CreateCouponRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"201": createdResponse("The coupon.", openapi.SchemaRef("Coupon")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"409": openapi.ResponseRef("Conflict"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+		},
+	}, adminRoles)
+}
+
+func getCouponOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "getCoupon",
+		Summary:     "Get a coupon",
+		Tags:        []string{"coupons"},
+		Parameters: []*openapi.Parameter{
+			{Name: "code", In: "path", Required: true, Description: "The code of the coupon.", Schema: stringSchema("")},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The coupon.", openapi.SchemaRef("Coupon")),
+			"404": openapi.ResponseRef("NotFound"),
+		},
+	}, adminRoles)
+}
+
+func listWebhooksOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "listWebhooks",
+		Summary:     "List the webhooks",
+		Tags:        []string{"webhooks"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The webhooks, without their secrets.", arraySchema(openapi.SchemaRef("Webhook"))),
+		},
+	}, adminRoles)
+}
+
+func createWebhookOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "createWebhook",
+		Summary:     "Subscribe a URL to the events of orders",
+		Description: "A secret is generated when none is given. The secret is only returned when the webhook is created.",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("WebhookRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"201": createdResponse("The webhook.", openapi.SchemaRef("Webhook")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+		},
+	}, adminRoles)
+}
+
+func getWebhookOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "getWebhook",
+		Summary:     "Get a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The webhook, without its secret.", openapi.SchemaRef("Webhook")),
+			"404": openapi.ResponseRef("NotFound"),
+		},
+	}, adminRoles)
+}
+
+func replaceWebhookOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "replaceWebhook",
+		Summary:     "Replace a webhook",
+		Description: "The secret of the webhook is kept when none is given.",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id"), openapi.ParameterRef("idempotencyKey")},
+		RequestBody: jsonRequestBody(openapi.SchemaRef("WebhookRequest"), true),
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The webhook, without its secret.", openapi.SchemaRef("Webhook")),
+			"400": openapi.ResponseRef("BadRequest"),
+			"404": openapi.ResponseRef("NotFound"),
+			"413": openapi.ResponseRef("PayloadTooLarge"),
+		},
+	}, adminRoles)
+}
+
+func deleteWebhookOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "deleteWebhook",
+		Summary:     "Delete a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id"), openapi.ParameterRef("idempotencyKey")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The webhook was deleted.", openapi.SchemaRef("Response")),
+			"404": openapi.ResponseRef("NotFound"),
+		},
+	}, adminRoles)
+}
+
+func listDeadLettersOperation() *openapi.Operation {
+	return secured(&openapi.Operation{
+		OperationID: "listDeadLetters",
+		Summary:     "List the events which could not be delivered to a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*openapi.Parameter{openapi.ParameterRef("id")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The dead letters of the webhook.", arraySchema(openapi.SchemaRef("DeadLetter"))),
+			"404": openapi.ResponseRef("NotFound"),
+		},
+	}, adminRoles)
+}
+
+func apiSchemas(limits domain.Limits) map[string]*openapi.Schema {
+	return map[string]*openapi.Schema{
+		"Response": objectSchema(nil, map[string]*openapi.Schema{
+			"status":  stringSchema("success, partial success, failure or error."),
+			"message": stringSchema(""),
+			"meta":    openapi.SchemaRef("Meta"),
+		}),
+		"Meta": objectSchema(nil, map[string]*openapi.Schema{
+			"errors": arraySchema(openapi.SchemaRef("ErrorInfo")),
+		}),
+		"ErrorInfo": objectSchema([]string{"detail"}, map[string]*openapi.Schema{
+			"field":  stringSchema("The parameter or the field of the body the error is about, such as products[0].quantity."),
+			"detail": stringSchema(""),
+		}),
+		"Health": objectSchema([]string{"status"}, map[string]*openapi.Schema{
+			"status":  stringSchema(""),
+			"checks":  &openapi.Schema{Type: "object", Description: "The outcome of every check of a dependency."},
+			"workers": &openapi.Schema{Type: "object", Description: "The state of every background worker."},
+		}),
+		"Token": objectSchema([]string{"token", "token_type", "expires_at"}, map[string]*openapi.Schema{
+			"token":      stringSchema("Sent in the Authorization header as Bearer <token>."),
+			"token_type": enumSchema("Bearer"),
+			"expires_at": dateTimeSchema(),
+		}),
+		"FeedEvent": objectSchema([]string{"sequence", "type", "at"}, map[string]*openapi.Schema{
+			"sequence":        integerSchema(),
+			"type":            enumSchema(string(domain.FeedOrderStatusChanged), string(domain.FeedOrderDispatchDateSet), string(domain.FeedProductStockChanged)),
+			"at":              dateTimeSchema(),
+			"order_id":        stringSchema(""),
+			"status":          enumSchema(orderStatuses()...),
+			"previous_status": enumSchema(orderStatuses()...),
+			"dispatch_date":   &openapi.Schema{Type: "string", Format: "date"},
+			"product_id":      stringSchema(""),
+			"stock": objectSchema([]string{"available", "reserved"}, map[string]*openapi.Schema{
+				"available": integerSchema(),
+				"reserved":  integerSchema(),
+			}),
+		}),
+		"Order": objectSchema([]string{"id", "total_quantity", "products", "pricing", "version"}, map[string]*openapi.Schema{
+			"id":             stringSchema(""),
+			"total_quantity": integerSchema(),
+			"products":       arraySchema(openapi.SchemaRef("OrderedProduct")),
+			"dispatch_date":  &openapi.Schema{Type: "string", Format: "date"},
+			"status":         enumSchema(orderStatuses()...),
+			"value":          numberSchema(),
+			"pricing":        openapi.SchemaRef("Pricing"),
+			"coupons":        arraySchema(stringSchema("")),
+			"customer_id":    stringSchema("The customer who owns the order, which is missing for orders created by staff."),
+			"version":        integerSchema(),
+		}),
+		"OrderedProduct": objectSchema([]string{"id", "name", "category", "price", "quantity", "line_total"}, map[string]*openapi.Schema{
+			"id":         stringSchema(""),
+			"name":       stringSchema(""),
+			"category":   enumSchema(productCategories()...),
+			"price":      numberSchema(),
+			"quantity":   integerSchema(),
+			"line_total": numberSchema(),
+		}),
+		"Pricing": objectSchema([]string{"subtotal", "adjustments", "total"}, map[string]*openapi.Schema{
+			"subtotal": numberSchema(),
+			"adjustments": arraySchema(objectSchema([]string{"rule", "amount"}, map[string]*openapi.Schema{
+				"rule":   stringSchema("The pricing rule or the coupon which adjusts the price."),
+				"amount": numberSchema(),
+			})),
+			"total": numberSchema(),
+		}),
+		"StatusTransition": objectSchema([]string{"to", "at", "actor"}, map[string]*openapi.Schema{
+			"from":  enumSchema(orderStatuses()...),
+			"to":    enumSchema(orderStatuses()...),
+			"at":    dateTimeSchema(),
+			"actor": stringSchema(""),
+		}),
+		"Product": objectSchema([]string{"id", "name", "category", "price", "available", "reserved"}, map[string]*openapi.Schema{
+			"id":        stringSchema(""),
+			"name":      stringSchema(""),
+			"category":  enumSchema(productCategories()...),
+			"price":     numberSchema(),
+			"sku":       integerSchema(),
+			"available": integerSchema(),
+			"reserved":  integerSchema(),
+			"version":   integerSchema(),
+		}),
+		"Customer": objectSchema([]string{"id", "name", "email", "created_at"}, map[string]*openapi.Schema{
+			"id":         stringSchema(""),
+			"name":       stringSchema(""),
+			"email":      &openapi.Schema{Type: "string", Format: "email"},
+			"contact":    stringSchema(""),
+			"created_at": dateTimeSchema(),
+		}),
+		"Coupon": objectSchema([]string{"code", "discount_type", "value", "valid_from", "valid_until", "max_redemptions", "redemptions", "stackable"}, map[string]*openapi.Schema{
+			"code":            stringSchema(""),
+			"discount_type":   enumSchema(string(domain.PercentDiscount), string(domain.FixedDiscount)),
+			"value":           numberSchema(),
+			"valid_from":      dateTimeSchema(),
+			"valid_until":     dateTimeSchema(),
+			"max_redemptions": integerSchema(),
+			"redemptions":     integerSchema(),
+			"stackable":       &openapi.Schema{Type: "boolean"},
+		}),
+		"Webhook": objectSchema([]string{"id", "url", "created_at"}, map[string]*openapi.Schema{
+			"id":          stringSchema(""),
+			"url":         &openapi.Schema{Type: "string", Format: "uri"},
+			"secret":      stringSchema("Only returned when the webhook is created."),
+			"event_types": arraySchema(enumSchema(webhookEventTypes()...)),
+			"created_at":  dateTimeSchema(),
+		}),
+		"DeadLetter": objectSchema([]string{"id", "order_id", "event_type", "sequence", "at", "attempts", "last_error"}, map[string]*openapi.Schema{
+			"id":         stringSchema(""),
+			"order_id":   stringSchema(""),
+			"event_type": enumSchema(webhookEventTypes()...),
+			"sequence":   integerSchema(),
+			"at":         dateTimeSchema(),
+			"attempts":   integerSchema(),
+			"last_error": stringSchema(""),
+		}),
+
+		"CreateOrderRequest": objectSchema(nil, map[string]*openapi.Schema{
+			"customer_id": nonEmptyStringSchema("The customer the order is created for. Only staff may set it."),
+		}),
+		"AddProductToOrderRequest": objectSchema([]string{"product_id"}, map[string]*openapi.Schema{
+			"product_id": nonEmptyStringSchema(""),
+			"quantity":   &openapi.Schema{Type: "integer", Description: "Defaults to 1.", Minimum: floatPtr(1), Maximum: floatPtr(float64(limits.MaxQuantityPerProduct))},
+		}),
+		"UpdateOrderRequest": objectSchema(nil, map[string]*openapi.Schema{
+			"order_status":  enumSchema(orderStatuses()...),
+			"dispatch_date": &openapi.Schema{Type: "string", Format: "date", Description: "Only set on dispatched orders."},
+		}),
+		"ApplyCouponRequest": objectSchema([]string{"code"}, map[string]*openapi.Schema{
+			"code": nonEmptyStringSchema(""),
+		}),
+		"CreateProductRequest":  objectSchema([]string{"id", "name", "category", "price"}, productRequestProperties(true)),
+		"ReplaceProductRequest": objectSchema([]string{"name", "category", "price"}, productRequestProperties(false)),
+		"UpdateProductRequest":  nullableProperties(objectSchema(nil, productRequestProperties(false))),
+		"CreateCustomerRequest": objectSchema([]string{"name", "email"}, map[string]*openapi.Schema{
+			"name":    nonEmptyStringSchema(""),
+			"email":   &openapi.Schema{Type: "string", Format: "email"},
+			"contact": stringSchema("How else the customer can be reached."),
+		}),
+		"CreateCouponRequest": objectSchema([]string{"code", "discount_type", "value", "valid_from", "valid_until"}, map[string]*openapi.Schema{
+			"code":            nonEmptyStringSchema(""),
+			"discount_type":   enumSchema(string(domain.PercentDiscount), string(domain.FixedDiscount)),
+			"value":           &openapi.Schema{Type: "number", Description: "A rate of at most 1 for percent discounts.", Minimum: floatPtr(0), ExclusiveMinimum: true},
+			"valid_from":      dateTimeSchema(),
+			"valid_until":     dateTimeSchema(),
+			"max_redemptions": &openapi.Schema{Type: "integer", Description: "0 for no limit.", Minimum: floatPtr(0)},
+			"stackable":       &openapi.Schema{Type: "boolean"},
+		}),
+		"WebhookRequest": objectSchema([]string{"url"}, map[string]*openapi.Schema{
+			"url":         &openapi.Schema{Type: "string", Format: "uri"},
+			"secret":      stringSchema("The secret the events are signed with."),
+			"event_types": &openapi.Schema{Type: "array", Description: "Every event is delivered when none is given.", Items: enumSchema(webhookEventTypes()...)},
+		}),
+	}
+}
+
+func productRequestProperties(withID bool) map[string]*openapi.Schema {
+	properties := map[string]*openapi.Schema{
+		"name":     nonEmptyStringSchema(""),
+		"category": enumSchema(productCategories()...),
+		"price":    &openapi.Schema{Type: "number", Minimum: floatPtr(0), ExclusiveMinimum: true},
+		"sku":      &openapi.Schema{Type: "integer", Description: "The units in stock.", Minimum: floatPtr(0)},
+	}
+	if withID {
+		properties["id"] = nonEmptyStringSchema("")
+	}
+	return properties
+}
+
+func apiParameters(limits domain.Limits) map[string]*openapi.Parameter {
+	return map[string]*openapi.Parameter{
+		"id": {Name: "id", In: "path", Required: true, Schema: nonEmptyStringSchema("")},
+		"limit": {Name: "limit", In: "query", Description: "The number of results in the page.",
+			Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(float64(limits.MaxPageLimit))}},
+		"cursor": {Name: "cursor", In: "query", Description: "The X-Next-Cursor header of the previous page.", Schema: stringSchema("")},
+		"sort": {Name: "sort", In: "query", Description: "Sort by id, in descending order with -id. Results can only be sorted by id; " +
+			"the ids of orders are generated in the order the orders are created.", Schema: enumSchema("id", "-id")},
+		"orderStatus": {Name: "status", In: "query", Description: "Only list the orders in the status.",
+			Schema: enumSchema(orderStatuses()...)},
+		"ifMatch": {Name: "If-Match", In: "header", Description: "Only change the resource if it is in the version of this ETag.",
+			Schema: stringSchema("")},
+		"idempotencyKey": {Name: IdempotencyKeyHeader, In: "header", Description: "Retries of the request with the same key get the response of the first request.",
+			Schema: &openapi.Schema{Type: "string", MaxLength: intPtr(maxIdempotencyKeyLength)}},
+	}
+}
+
+func apiResponses() map[string]*openapi.Response {
+	rateLimitHeaders := map[string]*openapi.Header{
+		"Retry-After":           {Description: "Seconds to wait before retrying.", Schema: integerSchema()},
+		"X-RateLimit-Limit":     {Description: "Requests the client may make at once.", Schema: integerSchema()},
+		"X-RateLimit-Remaining": {Description: "Requests the client has left.", Schema: integerSchema()},
+		"X-RateLimit-Reset":     {Description: "Seconds until the client may make all of its requests again.", Schema: integerSchema()},
+	}
+	errorResponse := func(description string) *openapi.Response {
+		return jsonResponse(description, openapi.SchemaRef("Response"))
+	}
+	tooManyRequests := errorResponse("The client made too many requests.")
+	tooManyRequests.Headers = rateLimitHeaders
+	return map[string]*openapi.Response{
+		"BadRequest":         errorResponse("The request is invalid. The invalid parameters and fields are listed in meta.errors."),
+		"Unauthorized":       errorResponse("The request has no credentials, or invalid ones."),
+		"Forbidden":          errorResponse("The role of the principal is not allowed to do this."),
+		"NotFound":           errorResponse("The resource does not exist."),
+		"Conflict":           errorResponse("The resource already exists."),
+		"PreconditionFailed": errorResponse("The resource is not in the version of the If-Match header."),
+		"PayloadTooLarge":    errorResponse("The body of the request is larger than the server accepts."),
+		"TooManyRequests":    tooManyRequests,
+	}
+}
+
+// secured lets the roles call the operation, with an API key or a bearer token.
+func secured(op *openapi.Operation, roles []domain.Role) *openapi.Operation {
+	op.Security = []map[string][]string{{"apiKey": {}}, {"bearer": {}}}
+	op.Roles = make([]string, len(roles))
+	for idx, role := range roles {
+		op.Roles[idx] = string(role)
+	}
+	op.Responses["401"] = openapi.ResponseRef("Unauthorized")
+	op.Responses["403"] = openapi.ResponseRef("Forbidden")
+	return op
+}
+
+func pageParameters() []*openapi.Parameter {
+	return []*openapi.Parameter{openapi.ParameterRef("limit"), openapi.ParameterRef("cursor"), openapi.ParameterRef("sort")}
+}
+
+func jsonRequestBody(schema *openapi.Schema, required bool) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: required, Content: map[string]*openapi.MediaType{jsonContentType: {Schema: schema}}}
+}
+
+func jsonResponse(description string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{Description: description, Content: map[string]*openapi.MediaType{jsonContentType: {Schema: schema}}}
+}
+
+func createdResponse(description string, schema *openapi.Schema) *openapi.Response {
+	response := jsonResponse(description, schema)
+	response.Headers = map[string]*openapi.Header{"Location": {Description: "The path of the created resource.", Schema: stringSchema("")}}
+	return response
+}
+
+func pageResponse(description string, item *openapi.Schema) *openapi.Response {
+	response := jsonResponse(description, arraySchema(item))
+	response.Headers = map[string]*openapi.Header{
+		NextCursorHeader: {Description: "The cursor of the next page, which is missing on the last page.", Schema: stringSchema("")},
+		"Link":           {Description: "The link to the next page, with rel=\"next\", which is missing on the last page.", Schema: stringSchema("")},
+	}
+	return response
+}
+
+// pageEnvelopeOperation turns the operation of a list into the one of its /v2 route, which answers
+// with the items of the page in data and the cursor of the next page in next_cursor.
+func pageEnvelopeOperation(op *openapi.Operation, item *openapi.Schema) *openapi.Operation {
+	op.OperationID += "V2"
+	page := op.Responses["200"]
+	page.Content[jsonContentType].Schema = objectSchema([]string{"data"}, map[string]*openapi.Schema{
+		"data":        arraySchema(item),
+		"next_cursor": stringSchema("The cursor of the next page, which is missing on the last page."),
+	})
+	return op
+}
+
+func versionedResponse(description string, schema *openapi.Schema) *openapi.Response {
+	response := jsonResponse(description, schema)
+	response.Headers = map[string]*openapi.Header{"ETag": {Description: "The version of the resource, for If-Match.", Schema: stringSchema("")}}
+	return response
+}
+
+func objectSchema(required []string, properties map[string]*openapi.Schema) *openapi.Schema {
+	return &openapi.Schema{Type: "object", Required: required, Properties: properties}
+}
+
+// nullableProperties lets the properties of the schema be null, as in partial updates where null
+// leaves a field as it is.
+func nullableProperties(schema *openapi.Schema) *openapi.Schema {
+	for _, property := range schema.Properties {
+		property.Nullable = true
+	}
+	return schema
+}
+
+func arraySchema(items *openapi.Schema) *openapi.Schema {
+	return &openapi.Schema{Type: "array", Items: items}
+}
+
+func stringSchema(description string) *openapi.Schema {
+	return &openapi.Schema{Type: "string", Description: description}
+}
+
+func nonEmptyStringSchema(description string) *openapi.Schema {
+	return &openapi.Schema{Type: "string", Description: description, MinLength: intPtr(1)}
+}
+
+func enumSchema(values ...string) *openapi.Schema {
+	return &openapi.Schema{Type: "string", Enum: values}
+}
+
+func dateTimeSchema() *openapi.Schema {
+	return &openapi.Schema{Type: "string", Format: "date-time"}
+}
+
+func integerSchema() *openapi.Schema {
+	return &openapi.Schema{Type: "integer"}
+}
+
+func numberSchema() *openapi.Schema {
+	return &openapi.Schema{Type: "number"}
+}
+
+func orderStatuses() []string {
+	statuses := make([]string, 0)
+	for _, status := range domain.OrderStatuses() {
+		statuses = append(statuses, string(status))
+	}
+	return statuses
+}
+
+func productCategories() []string {
+	categories := make([]string, 0)
+	for _, category := range domain.ProductCategories() {
+		categories = append(categories, string(category))
+	}
+	return categories
+}
+
+func webhookEventTypes() []string {
+	eventTypes := make([]string, 0)
+	for _, eventType := range domain.WebhookEventTypes() {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+	return eventTypes
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,151 @@
+package webservice_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/webservice"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+var roles = []domain.Role{domain.RoleCustomer, domain.RoleOperator, domain.RoleAdmin}
+
+// roleAuthenticator authenticates the API keys which are the name of a role as a principal with that
+// role.
+type roleAuthenticator struct{}
+
+func (roleAuthenticator) Authenticate(ctx context.Context, apiKey string) (domain.Principal, error) {
+	if !domain.Role(apiKey).IsValid() {
+		return domain.Principal{}, usecases.ErrInvalidCredentials
+	}
+	return domain.Principal{Subject: "s-1", Role: domain.Role(apiKey)}, nil
+}
+
+func (roleAuthenticator) IssueToken(principal domain.Principal) (usecases.Token, error) {
+	return usecases.Token{}, nil
+}
+
+func (roleAuthenticator) VerifyToken(bearer string) (domain.Principal, error) {
+	return domain.Principal{}, usecases.ErrInvalidCredentials
+}
+
+func newRouter() *mux.Router {
+	health := webservice.NewHealth()
+	health.SetPhase(webservice.PhaseReady)
+	return webservice.SetupRoutes(nil, nil, nil, nil, nil, nil, roleAuthenticator{}, health, nil, webservice.RateLimits{}, domain.DefaultLimits(), 1<<20, make(chan struct{}))
+}
+
+// allowsRole reports whether the handler of a route lets a principal with the role through. The
+// interactors of the router are missing, so a handler which is reached may well panic.
+func allowsRole(route *mux.Route, method, path string, role domain.Role) (allowed bool) {
+	defer func() {
+		if recover() != nil {
+			allowed = true
+		}
+	}()
+	r := httptest.NewRequest(method, path, strings.NewReader(""))
+	r = r.WithContext(usecases.WithPrincipal(r.Context(), domain.Principal{Subject: "s-1", Role: role}))
+	r = mux.SetURLVars(r, map[string]string{"id": "1", "code": "SAVE10"})
+	w := httptest.NewRecorder()
+	route.GetHandler().ServeHTTP(w, r)
+	return w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "role is not allowed")
+}
+
+func TestSetupRoutes_DocumentsEveryRouteWithTheRolesItRequires(t *testing.T) {
+	document := webservice.NewAPIDocument(domain.DefaultLimits())
+	err := newRouter().Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			op := document.Operation(path, method)
+			if op == nil {
+				t.Errorf("%s %s has no operation", method, path)
+				continue
+			}
+
+			// An operation without x-roles may be called with any role, or without credentials.
+			documented := op.Roles
+			if len(documented) == 0 {
+				documented = make([]string, len(roles))
+				for idx, role := range roles {
+					documented[idx] = string(role)
+				}
+			}
+			required := make([]string, 0)
+			for _, role := range roles {
+				if allowsRole(route, method, path, role) {
+					required = append(required, string(role))
+				}
+			}
+			sort.Strings(documented)
+			sort.Strings(required)
+			if strings.Join(documented, ",") != strings.Join(required, ",") {
+				t.Errorf("%s %s: Got: %v, Want: %v", method, path, documented, required)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidationMiddleware_ListsEveryInvalidField(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/orders/1/products?unknown=1", strings.NewReader(`{"product_id": 123, "quantity": 0, "note": "gift"}`))
+	r.Header.Set(webservice.APIKeyHeader, string(domain.RoleCustomer))
+	w := httptest.NewRecorder()
+	newRouter().ServeHTTP(w, r)
+
+	var response serializer.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || w.Code != http.StatusBadRequest || response.Meta == nil {
+		t.Fatalf("Got: %v %s, Want: %v with meta.errors", w.Code, w.Body.String(), http.StatusBadRequest)
+	}
+	fields := make([]string, len(response.Meta.Errors))
+	for idx, errorInfo := range response.Meta.Errors {
+		fields[idx] = errorInfo.Field
+		if errorInfo.Detail == "" {
+			t.Errorf("Got: no detail for %s, Want: what is wrong with it", errorInfo.Field)
+		}
+	}
+	sort.Strings(fields)
+	if got, want := strings.Join(fields, ","), "product_id,quantity"; got != want {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestValidationMiddleware_LetsRequestsWhichAreNotAllowedThrough(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{}`))
+	r.Header.Set(webservice.APIKeyHeader, string(domain.RoleCustomer))
+	w := httptest.NewRecorder()
+	newRouter().ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Got: %v, Want: %v", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestValidationMiddleware_RejectsBodiesOverTheLimit(t *testing.T) {
+	body := `{"product_id": "` + strings.Repeat("1", 1<<20) + `", "quantity": 1}`
+	r := httptest.NewRequest(http.MethodPost, "/orders/1/products", strings.NewReader(body))
+	r.Header.Set(webservice.APIKeyHeader, string(domain.RoleCustomer))
+	w := httptest.NewRecorder()
+	newRouter().ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Got: %v, Want: %v", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
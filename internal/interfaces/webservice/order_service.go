@@ -1,24 +1,43 @@
 package webservice
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"io"
 	"net/http"
 	"simple-order-service/internal/domain"
 	"simple-order-service/internal/serializer"
 	"simple-order-service/internal/usecases"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 type OrderInteractor interface {
-	Products(orderId string) ([]usecases.Product, error)
-	Add(orderId, productId string) error
-	GetDetails(orderId string) (usecases.Order, error)
-	GetAll() []usecases.Order
-	UpdateDispatchDate(orderId, date string) error
-	UpdateOrderStatus(orderId string, status domain.OrderStatus) error
+	Create(ctx context.Context, customerID string) (usecases.Order, error)
+	Products(ctx context.Context, orderId string) ([]usecases.Product, error)
+	Add(ctx context.Context, orderId, productId string, quantity int, expectedVersion int) error
+	GetDetails(ctx context.Context, orderId string) (usecases.Order, error)
+	GetDetailsAsOf(ctx context.Context, orderId string, at time.Time) (usecases.Order, error)
+	GetAll(ctx context.Context) []usecases.Order
+	List(ctx context.Context, query domain.OrderQuery) ([]usecases.Order, string, error)
+	History(ctx context.Context, orderId string) ([]usecases.StatusTransition, error)
+	UpdateDispatchDate(ctx context.Context, orderId, date string, expectedVersion int) error
+	UpdateOrderStatus(ctx context.Context, orderId string, status domain.OrderStatus, actor string, expectedVersion int) (int, error)
+	ApplyCoupon(ctx context.Context, orderId, code string, expectedVersion int) error
+	RemoveCoupon(ctx context.Context, orderId, code string, expectedVersion int) error
+}
+
+// ActorHeader identifies who is performing a change to an order when the request is not
+// authenticated. It is recorded in the order's status history.
+const ActorHeader = "X-Actor"
+
+const anonymousActor = "anonymous"
+
+type CreateOrderHandler struct {
+	orderInteractor OrderInteractor
 }
 
 type UpdateOrderHandler struct {
@@ -41,6 +60,22 @@ type AddProductToOrderHandler struct {
 	orderInteractor OrderInteractor
 }
 
+type GetOrderHistoryHandler struct {
+	orderInteractor OrderInteractor
+}
+
+type ApplyCouponToOrderHandler struct {
+	orderInteractor OrderInteractor
+}
+
+type RemoveCouponFromOrderHandler struct {
+	orderInteractor OrderInteractor
+}
+
+func NewCreateOrderHandler(orderInteractor OrderInteractor) CreateOrderHandler {
+	return CreateOrderHandler{orderInteractor: orderInteractor}
+}
+
 func NewUpdateOrderHandler(orderInteractor OrderInteractor) UpdateOrderHandler {
 	return UpdateOrderHandler{orderInteractor: orderInteractor}
 }
@@ -61,27 +96,143 @@ func NewAddProductToOrderHandler(orderInteractor OrderInteractor) AddProductToOr
 	return AddProductToOrderHandler{orderInteractor: orderInteractor}
 }
 
+func NewGetOrderHistoryHandler(orderInteractor OrderInteractor) GetOrderHistoryHandler {
+	return GetOrderHistoryHandler{orderInteractor: orderInteractor}
+}
+
+func NewApplyCouponToOrderHandler(orderInteractor OrderInteractor) ApplyCouponToOrderHandler {
+	return ApplyCouponToOrderHandler{orderInteractor: orderInteractor}
+}
+
+func NewRemoveCouponFromOrderHandler(orderInteractor OrderInteractor) RemoveCouponFromOrderHandler {
+	return RemoveCouponFromOrderHandler{orderInteractor: orderInteractor}
+}
+
+// orderErrorStatus maps the errors returned by the order interactor to HTTP status codes. Violated
+// business rules are reported as bad requests.
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrOrderNotFound), errors.Is(err, usecases.ErrProductNotFound), errors.Is(err, usecases.ErrCouponNotFound),
+		errors.Is(err, usecases.ErrCustomerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusBadRequest
+}
+
+// createOrderErrorStatus answers the errors of creating an order: the caller can only fix the
+// customer the order is for, as the id of the order is generated by the service.
+func createOrderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrCustomerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrForbidden):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// requestActor is the subject of the principal the request is made by, or the actor named by the
+// request when it is not authenticated.
+func requestActor(r *http.Request) string {
+	if principal, ok := usecases.PrincipalFrom(r.Context()); ok {
+		return principal.Subject
+	}
+	actor := strings.TrimSpace(r.Header.Get(ActorHeader))
+	if actor == "" {
+		return anonymousActor
+	}
+	return actor
+}
+
+// ServeHTTP creates an empty order under an id generated by the service. The body of the request is
+// optional.
+func (handler CreateOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	var req serializer.CreateOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "unable to parse JSON data",
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	order, err := handler.orderInteractor.Create(r.Context(), req.CustomerID)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(createOrderErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(order)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Header().Set("Location", "/orders/"+order.ID)
+	w.Header().Set("ETag", versionETag(order.Version))
+	w.WriteHeader(http.StatusCreated)
+	w.Write(responseJSON)
+}
+
 func (handler GetOrderDetailsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 
 	vars := mux.Vars(r)
 	orderID := vars["id"]
 
-	orderDetails, err := handler.orderInteractor.GetDetails(orderID)
+	// as_of asks for the order as it was at an earlier time, given in RFC 3339 format.
+	var orderDetails usecases.Order
+	var err error
+	if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+		at, parseErr := time.Parse(time.RFC3339, asOf)
+		if parseErr != nil {
+			failureResponse := serializer.Response{
+				Status:  "error",
+				Message: "invalid as_of. it must be a time in RFC 3339 format, e.g. 2006-01-02T15:04:05Z",
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(failureResponse.ToJSON())
+			return
+		}
+		orderDetails, err = handler.orderInteractor.GetDetailsAsOf(r.Context(), orderID, at)
+	} else {
+		orderDetails, err = handler.orderInteractor.GetDetails(r.Context(), orderID)
+	}
 	if err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: err.Error(),
 		}
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(orderErrorStatus(err))
 		w.Write(failureResponse.ToJSON())
 		return
 	}
 
 	responseJSON, err := json.Marshal(orderDetails)
 	if err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: err.Error(),
@@ -91,6 +242,7 @@ func (handler GetOrderDetailsHandler) ServeHTTP(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(orderDetails.Version))
 	w.Write(responseJSON)
 }
 
@@ -100,21 +252,21 @@ func (handler GetAllOrderedProductsHandler) ServeHTTP(w http.ResponseWriter, r *
 	vars := mux.Vars(r)
 	orderID := vars["id"]
 
-	orders, err := handler.orderInteractor.Products(orderID)
+	orders, err := handler.orderInteractor.Products(r.Context(), orderID)
 	if err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: err.Error(),
 		}
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(orderErrorStatus(err))
 		w.Write(failureResponse.ToJSON())
 		return
 	}
 
 	responseJSON, err := json.Marshal(orders)
 	if err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: err.Error(),
@@ -138,7 +290,7 @@ func (handler UpdateOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 	var req serializer.UpdateOrderRequest
 
 	if err := decoder.Decode(&req); err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: "unable to parse JSON data",
@@ -149,16 +301,35 @@ func (handler UpdateOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(ifMatchErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
 	res := serializer.Response{}
 	res.Meta = &serializer.Meta{}
 	res.Meta.Errors = []serializer.ErrorInfo{}
 
+	// The If-Match precondition applies to the first update of the request. Once it has succeeded the
+	// next update expects the order to be in the version written by the first one, so that a change
+	// made by somebody else in between fails the precondition too.
 	var errorInfo serializer.ErrorInfo
 	errCount := 0
 	validUpdates := 0
-	if len(strings.TrimSpace(req.DispatchDate)) > 0 {
-		if err := handler.orderInteractor.UpdateDispatchDate(orderID, req.DispatchDate); err != nil {
-			log.Println(err.Error())
+	if len(strings.TrimSpace(req.OrderStatus)) > 0 {
+		version, err := handler.orderInteractor.UpdateOrderStatus(r.Context(), orderID, domain.OrderStatus(req.OrderStatus), requestActor(r), expectedVersion)
+		if err != nil {
+			logRequestError(r, err)
+			if errors.Is(err, domain.ErrVersionConflict) {
+				writePreconditionFailed(w, err)
+				return
+			}
 			errorInfo = serializer.ErrorInfo{
 				Detail: err.Error(),
 			}
@@ -166,12 +337,19 @@ func (handler UpdateOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 			errCount += 1
 		} else {
 			validUpdates += 1
+			if expectedVersion != domain.AnyVersion {
+				expectedVersion = version
+			}
 		}
 	}
 
-	if len(strings.TrimSpace(req.OrderStatus)) > 0 {
-		if err := handler.orderInteractor.UpdateOrderStatus(orderID, domain.OrderStatus(req.OrderStatus)); err != nil {
-			log.Println(err.Error())
+	if len(strings.TrimSpace(req.DispatchDate)) > 0 {
+		if err := handler.orderInteractor.UpdateDispatchDate(r.Context(), orderID, req.DispatchDate, expectedVersion); err != nil {
+			logRequestError(r, err)
+			if errors.Is(err, domain.ErrVersionConflict) {
+				writePreconditionFailed(w, err)
+				return
+			}
 			errorInfo = serializer.ErrorInfo{
 				Detail: err.Error(),
 			}
@@ -223,7 +401,7 @@ func (handler AddProductToOrderHandler) ServeHTTP(w http.ResponseWriter, r *http
 
 	var req serializer.AddProductToOrderRequest
 	if err := decoder.Decode(&req); err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: "unable to parse JSON data",
@@ -234,13 +412,28 @@ func (handler AddProductToOrderHandler) ServeHTTP(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err := handler.orderInteractor.Add(orderID, req.ProductID); err != nil {
-		log.Println(err.Error())
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: err.Error(),
 		}
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(ifMatchErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	if err := handler.orderInteractor.Add(r.Context(), orderID, req.ProductID, req.Quantity, expectedVersion); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(orderErrorStatus(err))
 		w.Write(failureResponse.ToJSON())
 		return
 	}
@@ -257,12 +450,54 @@ func (handler AddProductToOrderHandler) ServeHTTP(w http.ResponseWriter, r *http
 func (handler GetAllOrdersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 
-	orders := handler.orderInteractor.GetAll()
+	query, errs := parseOrderQuery(r.URL.Query())
+	if len(errs) > 0 {
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "invalid query parameters. more details can be found in the 'errors' section",
+			Meta:    &serializer.Meta{Errors: errs},
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
 
-	responseJSON, err := json.Marshal(orders)
+	orders, nextCursor, err := handler.orderInteractor.List(r.Context(), query)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(listErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	writePage(w, r, orders, nextCursor)
+}
 
+func (handler GetOrderHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	orderID := vars["id"]
+
+	history, err := handler.orderInteractor.History(r.Context(), orderID)
 	if err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(orderErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(history)
+	if err != nil {
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: err.Error(),
@@ -274,3 +509,101 @@ func (handler GetAllOrdersHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 
 	w.Write(responseJSON)
 }
+
+func (handler ApplyCouponToOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	orderID := vars["id"]
+
+	decoder := json.NewDecoder(r.Body)
+
+	var req serializer.ApplyCouponRequest
+	if err := decoder.Decode(&req); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "unable to parse JSON data",
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(ifMatchErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	if err := handler.orderInteractor.ApplyCoupon(r.Context(), orderID, req.Code, expectedVersion); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(orderErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	successResponse := serializer.Response{
+		Status:  "success",
+		Message: "coupon applied to order",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(successResponse.ToJSON())
+}
+
+func (handler RemoveCouponFromOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	orderID := vars["id"]
+	code := vars["code"]
+
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(ifMatchErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	if err := handler.orderInteractor.RemoveCoupon(r.Context(), orderID, code, expectedVersion); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(orderErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	successResponse := serializer.Response{
+		Status:  "success",
+		Message: "coupon removed from order",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(successResponse.ToJSON())
+}
+
+func writePreconditionFailed(w http.ResponseWriter, err error) {
+	failureResponse := serializer.Response{
+		Status:  "error",
+		Message: err.Error(),
+	}
+	w.WriteHeader(http.StatusPreconditionFailed)
+	w.Write(failureResponse.ToJSON())
+}
@@ -0,0 +1,195 @@
+package webservice_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/webservice"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/metrics"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// newOrderInteractor keeps the order in memory. Every store of the order moves it to the next
+// version, and onStore is called with the stored order.
+func newOrderInteractor(order *domain.Order, onStore func(stored *domain.Order)) (*usecases.OrderInteractor, *domain.OrderRepositoryMock) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return *order
+		},
+		StoreFunc: func(ctx context.Context, stored domain.Order) error {
+			if err := domain.CheckVersion(order.Version(), stored.Version()); err != nil {
+				return err
+			}
+			stored.IncrementVersion()
+			*order = stored
+			if onStore != nil {
+				onStore(order)
+			}
+			return nil
+		},
+	}
+	unitOfWorkMock := &domain.UnitOfWorkMock{
+		DoFunc: func(ctx context.Context, fn func(repos domain.Repositories) error) error {
+			return fn(domain.Repositories{Orders: orderRepoMock})
+		},
+	}
+	return usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry())), orderRepoMock
+}
+
+func placedOrder() domain.Order {
+	order := domain.NewOrder("1")
+	order.Add(domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium))
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+	return order
+}
+
+func updateOrderRequest(ifMatch string) *http.Request {
+	body := `{"order_status": "dispatched", "dispatch_date": "` + time.Now().Add(48*time.Hour).Format("2006-01-02") + `"}`
+	r := httptest.NewRequest(http.MethodPut, "/orders/1", strings.NewReader(body))
+	r.Header.Set("If-Match", ifMatch)
+	return mux.SetURLVars(r, map[string]string{"id": "1"})
+}
+
+func TestUpdateOrderHandler_AppliesBothUpdatesOfConditionalRequest(t *testing.T) {
+	order := placedOrder()
+	orderInteractor, _ := newOrderInteractor(&order, nil)
+
+	w := httptest.NewRecorder()
+	webservice.NewUpdateOrderHandler(orderInteractor).ServeHTTP(w, updateOrderRequest(`"0"`))
+
+	var response serializer.Response
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if w.Code != http.StatusOK || response.Status != "success" {
+		t.Fatalf("Got: %v %s, Want: %v success", w.Code, w.Body.String(), http.StatusOK)
+	}
+	if order.GetDispatchDate() == "" || order.Version() != 2 {
+		t.Errorf("Got: dispatch date %q in version %d, Want: the dispatch date in version 2", order.GetDispatchDate(), order.Version())
+	}
+}
+
+func TestUpdateOrderHandler_SecondUpdateFailsPreconditionWhenOrderChangesInBetween(t *testing.T) {
+	order := placedOrder()
+	// Somebody else changes the order right after the status has been changed.
+	orderInteractor, orderRepoMock := newOrderInteractor(&order, func(stored *domain.Order) {
+		if stored.Version() == 1 {
+			stored.IncrementVersion()
+		}
+	})
+
+	w := httptest.NewRecorder()
+	webservice.NewUpdateOrderHandler(orderInteractor).ServeHTTP(w, updateOrderRequest(`"0"`))
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Got: %v, Want: %v", w.Code, http.StatusPreconditionFailed)
+	}
+	if len(orderRepoMock.StoreCalls()) != 1 {
+		t.Errorf("Got: %d stores, Want: only the status change stored", len(orderRepoMock.StoreCalls()))
+	}
+}
+
+func TestRemoveCouponFromOrderHandler_ChecksIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    int
+	}{
+		{name: "stale version", ifMatch: `"3"`, want: http.StatusPreconditionFailed},
+		{name: "weak etag", ifMatch: `W/"0"`, want: http.StatusPreconditionFailed},
+		{name: "unreadable", ifMatch: `0`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := placedOrder()
+			orderInteractor, orderRepoMock := newOrderInteractor(&order, nil)
+
+			r := httptest.NewRequest(http.MethodDelete, "/orders/1/coupons/SAVE10", nil)
+			r.Header.Set("If-Match", tt.ifMatch)
+			r = mux.SetURLVars(r, map[string]string{"id": "1", "code": "SAVE10"})
+			w := httptest.NewRecorder()
+			webservice.NewRemoveCouponFromOrderHandler(orderInteractor).ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("Got: %v, Want: %v", w.Code, tt.want)
+			}
+			if len(orderRepoMock.StoreCalls()) != 0 {
+				t.Error("order must not be stored when the precondition fails")
+			}
+		})
+	}
+}
+
+func TestCreateOrderHandler_AnswersWithTheLocationAndETagOfTheOrder(t *testing.T) {
+	var order domain.Order
+	orderInteractor, _ := newOrderInteractor(&order, nil)
+
+	w := httptest.NewRecorder()
+	webservice.NewCreateOrderHandler(orderInteractor).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	var created usecases.Order
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if w.Code != http.StatusCreated || created.ID == "" || created.ID != order.ID() {
+		t.Fatalf("Got: %v %s, Want: %v with the stored order", w.Code, w.Body.String(), http.StatusCreated)
+	}
+	if got := w.Header().Get("Location"); got != "/orders/"+created.ID {
+		t.Errorf("Got: %v, Want: %v", got, "/orders/"+created.ID)
+	}
+	if got := w.Header().Get("ETag"); got != `"1"` {
+		t.Errorf("Got: %v, Want: %v", got, `"1"`)
+	}
+}
+
+func TestCreateOrderHandler_AnswersStorageFailuresWith500(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		StoreFunc: func(ctx context.Context, order domain.Order) error {
+			return errors.New("disk is full")
+		},
+	}
+	unitOfWorkMock := &domain.UnitOfWorkMock{
+		DoFunc: func(ctx context.Context, fn func(repos domain.Repositories) error) error {
+			return fn(domain.Repositories{Orders: orderRepoMock})
+		},
+	}
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+
+	w := httptest.NewRecorder()
+	webservice.NewCreateOrderHandler(orderInteractor).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Location") != "" {
+		t.Errorf("Got: %v with Location %q, Want: %v without one", w.Code, w.Header().Get("Location"), http.StatusInternalServerError)
+	}
+}
+
+func TestAddProductToOrderHandler_AnswersUnknownProductsWith404(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return domain.NewOrder(id)
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return domain.Product{}
+		},
+	}
+	unitOfWorkMock := &domain.UnitOfWorkMock{
+		DoFunc: func(ctx context.Context, fn func(repos domain.Repositories) error) error {
+			return fn(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock})
+		},
+	}
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+
+	r := httptest.NewRequest(http.MethodPost, "/orders/1/products", strings.NewReader(`{"product_id": "42"}`))
+	r = mux.SetURLVars(r, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	webservice.NewAddProductToOrderHandler(orderInteractor).ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Got: %v %s, Want: %v", w.Code, w.Body.String(), http.StatusNotFound)
+	}
+}
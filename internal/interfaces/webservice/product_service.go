@@ -1,9 +1,11 @@
 package webservice
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
+	"simple-order-service/internal/domain"
 	"simple-order-service/internal/serializer"
 	"simple-order-service/internal/usecases"
 
@@ -11,8 +13,13 @@ import (
 )
 
 type ProductInteractor interface {
-	GetDetails(productId string) (usecases.Product, error)
-	GetAll() []usecases.Product
+	GetDetails(ctx context.Context, productId string) (usecases.Product, error)
+	GetAll(ctx context.Context) []usecases.Product
+	List(ctx context.Context, query domain.ProductQuery) ([]usecases.Product, string, error)
+	Create(ctx context.Context, input usecases.Product) (usecases.Product, error)
+	Replace(ctx context.Context, productId string, input usecases.Product, expectedVersion int) (usecases.Product, error)
+	Update(ctx context.Context, productId string, update usecases.ProductUpdate, expectedVersion int) (usecases.Product, error)
+	Delete(ctx context.Context, productId string, expectedVersion int) error
 }
 
 type GetProductDetailsHandler struct {
@@ -23,6 +30,22 @@ type GetAllProductsHandler struct {
 	productInteractor ProductInteractor
 }
 
+type CreateProductHandler struct {
+	productInteractor ProductInteractor
+}
+
+type ReplaceProductHandler struct {
+	productInteractor ProductInteractor
+}
+
+type UpdateProductHandler struct {
+	productInteractor ProductInteractor
+}
+
+type DeleteProductHandler struct {
+	productInteractor ProductInteractor
+}
+
 func NewGetProductDetailsHandler(productInteractor ProductInteractor) GetProductDetailsHandler {
 	return GetProductDetailsHandler{productInteractor: productInteractor}
 }
@@ -30,15 +53,47 @@ func NewGetAllProductsHandler(productInteractor ProductInteractor) GetAllProduct
 	return GetAllProductsHandler{productInteractor: productInteractor}
 }
 
+func NewCreateProductHandler(productInteractor ProductInteractor) CreateProductHandler {
+	return CreateProductHandler{productInteractor: productInteractor}
+}
+
+func NewReplaceProductHandler(productInteractor ProductInteractor) ReplaceProductHandler {
+	return ReplaceProductHandler{productInteractor: productInteractor}
+}
+
+func NewUpdateProductHandler(productInteractor ProductInteractor) UpdateProductHandler {
+	return UpdateProductHandler{productInteractor: productInteractor}
+}
+
+func NewDeleteProductHandler(productInteractor ProductInteractor) DeleteProductHandler {
+	return DeleteProductHandler{productInteractor: productInteractor}
+}
+
+// productErrorStatus maps the errors returned by the product interactor to HTTP status codes.
+func productErrorStatus(err error) int {
+	var productErr *domain.ProductError
+	switch {
+	case errors.Is(err, usecases.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrProductAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.As(err, &productErr):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 func (handler GetProductDetailsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 
 	vars := mux.Vars(r)
 	productID := vars["id"]
 
-	productDetails, err := handler.productInteractor.GetDetails(productID)
+	productDetails, err := handler.productInteractor.GetDetails(r.Context(), productID)
 	if err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: err.Error(),
@@ -50,7 +105,7 @@ func (handler GetProductDetailsHandler) ServeHTTP(w http.ResponseWriter, r *http
 
 	responseJSON, err := json.Marshal(productDetails)
 	if err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: err.Error(),
@@ -60,17 +115,78 @@ func (handler GetProductDetailsHandler) ServeHTTP(w http.ResponseWriter, r *http
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(productDetails.Version))
 	w.Write(responseJSON)
 }
 
 func (handler GetAllProductsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 
-	products := handler.productInteractor.GetAll()
+	query, errs := parseProductQuery(r.URL.Query())
+	if len(errs) > 0 {
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "invalid query parameters. more details can be found in the 'errors' section",
+			Meta:    &serializer.Meta{Errors: errs},
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
 
-	responseJSON, err := json.Marshal(products)
+	products, nextCursor, err := handler.productInteractor.List(r.Context(), query)
 	if err != nil {
-		log.Println(err.Error())
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(listErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	writePage(w, r, products, nextCursor)
+}
+
+func (handler CreateProductHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	decoder := json.NewDecoder(r.Body)
+
+	var req serializer.CreateProductRequest
+	if err := decoder.Decode(&req); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "unable to parse JSON data",
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	product, err := handler.productInteractor.Create(r.Context(), usecases.Product{
+		ID:       req.ID,
+		Name:     req.Name,
+		Category: req.Category,
+		Price:    req.Price,
+		SKU:      req.SKU,
+	})
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(productErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(product)
+	if err != nil {
+		logRequestError(r, err)
 		failureResponse := serializer.Response{
 			Status:  "error",
 			Message: err.Error(),
@@ -80,5 +196,173 @@ func (handler GetAllProductsHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(product.Version))
+	w.Header().Set("Location", "/products/"+product.ID)
+	w.WriteHeader(http.StatusCreated)
 	w.Write(responseJSON)
 }
+
+func (handler ReplaceProductHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	productID := vars["id"]
+
+	decoder := json.NewDecoder(r.Body)
+
+	var req serializer.ReplaceProductRequest
+	if err := decoder.Decode(&req); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "unable to parse JSON data",
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(ifMatchErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	product, err := handler.productInteractor.Replace(r.Context(), productID, usecases.Product{
+		Name:     req.Name,
+		Category: req.Category,
+		Price:    req.Price,
+		SKU:      req.SKU,
+	}, expectedVersion)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(productErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(product)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(product.Version))
+	w.Write(responseJSON)
+}
+
+func (handler UpdateProductHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	productID := vars["id"]
+
+	decoder := json.NewDecoder(r.Body)
+
+	var req serializer.UpdateProductRequest
+	if err := decoder.Decode(&req); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "unable to parse JSON data",
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(ifMatchErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	product, err := handler.productInteractor.Update(r.Context(), productID, usecases.ProductUpdate{
+		Name:     req.Name,
+		Category: req.Category,
+		Price:    req.Price,
+		SKU:      req.SKU,
+	}, expectedVersion)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(productErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(product)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(product.Version))
+	w.Write(responseJSON)
+}
+
+func (handler DeleteProductHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	productID := vars["id"]
+
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(ifMatchErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	if err := handler.productInteractor.Delete(r.Context(), productID, expectedVersion); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(productErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	successResponse := serializer.Response{
+		Status:  "success",
+		Message: "product deleted",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(successResponse.ToJSON())
+}
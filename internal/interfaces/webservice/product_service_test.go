@@ -0,0 +1,114 @@
+package webservice_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/webservice"
+	"simple-order-service/internal/usecases"
+	"testing"
+)
+
+func TestGetAllProductsHandler_GivesNextCursorInHeaders(t *testing.T) {
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindFunc: func(ctx context.Context, query domain.ProductQuery) ([]domain.Product, string, error) {
+			return []domain.Product{domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)}, "next", nil
+		},
+	}
+	handler := webservice.NewGetAllProductsHandler(usecases.NewProductInteractor(productRepoMock, &domain.UnitOfWorkMock{}, domain.DefaultLimits()))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products?limit=1&category=premium", nil))
+
+	var products []usecases.Product
+	if err := json.Unmarshal(w.Body.Bytes(), &products); err != nil || len(products) != 1 {
+		t.Fatalf("Got: %s, Want: an array of 1 product", w.Body.String())
+	}
+	if got := w.Header().Get(webservice.NextCursorHeader); got != "next" {
+		t.Errorf("Got: %v, Want: %v", got, "next")
+	}
+	if got, want := w.Header().Get("Link"), `</products?category=premium&cursor=next&limit=1>; rel="next"`; got != want {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestGetAllProductsHandler_LastPageHasNoNextCursor(t *testing.T) {
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindFunc: func(ctx context.Context, query domain.ProductQuery) ([]domain.Product, string, error) {
+			return []domain.Product{}, "", nil
+		},
+	}
+	handler := webservice.NewGetAllProductsHandler(usecases.NewProductInteractor(productRepoMock, &domain.UnitOfWorkMock{}, domain.DefaultLimits()))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+	if w.Body.String() != "[]" {
+		t.Errorf("Got: %v, Want: %v", w.Body.String(), "[]")
+	}
+	if w.Header().Get(webservice.NextCursorHeader) != "" || w.Header().Get("Link") != "" {
+		t.Errorf("Got: %v, Want: no next page", w.Header())
+	}
+}
+
+func TestGetAllProductsHandler_GivesNextCursorInTheBodyBehindPageEnvelope(t *testing.T) {
+	for _, nextCursor := range []string{"next", ""} {
+		productRepoMock := &domain.ProductRepositoryMock{
+			FindFunc: func(ctx context.Context, query domain.ProductQuery) ([]domain.Product, string, error) {
+				return []domain.Product{domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)}, nextCursor, nil
+			},
+		}
+		handler := webservice.PageEnvelope(webservice.NewGetAllProductsHandler(usecases.NewProductInteractor(productRepoMock, &domain.UnitOfWorkMock{}, domain.DefaultLimits())))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/products?limit=1", nil))
+
+		var page struct {
+			Data       []usecases.Product `json:"data"`
+			NextCursor *string            `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || len(page.Data) != 1 {
+			t.Fatalf("Got: %s, Want: a page of 1 product", w.Body.String())
+		}
+		if nextCursor == "" && page.NextCursor != nil {
+			t.Errorf("Got: %v, Want: no next cursor on the last page", *page.NextCursor)
+		}
+		if nextCursor != "" && (page.NextCursor == nil || *page.NextCursor != nextCursor) {
+			t.Errorf("Got: %s, Want: next_cursor %v", w.Body.String(), nextCursor)
+		}
+		if got := w.Header().Get(webservice.NextCursorHeader); got != nextCursor {
+			t.Errorf("Got: %v, Want: %v", got, nextCursor)
+		}
+	}
+}
+
+func TestGetAllProductsHandler_AnswersErrorsOfTheQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		findErr error
+		want    int
+	}{
+		{name: "invalid cursor", findErr: domain.ErrInvalidCursor, want: http.StatusBadRequest},
+		{name: "read failure", findErr: errors.New("database is closed"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productRepoMock := &domain.ProductRepositoryMock{
+				FindFunc: func(ctx context.Context, query domain.ProductQuery) ([]domain.Product, string, error) {
+					return nil, "", tt.findErr
+				},
+			}
+			handler := webservice.NewGetAllProductsHandler(usecases.NewProductInteractor(productRepoMock, &domain.UnitOfWorkMock{}, domain.DefaultLimits()))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products?cursor=abc", nil))
+
+			if w.Code != tt.want {
+				t.Errorf("Got: %v, Want: %v", w.Code, tt.want)
+			}
+		})
+	}
+}
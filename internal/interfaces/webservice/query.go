@@ -0,0 +1,133 @@
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+	"strconv"
+)
+
+// NextCursorHeader holds the cursor of the next page of a list, and is missing on the last page.
+const NextCursorHeader = "X-Next-Cursor"
+
+// parsePage reads the limit, cursor and sort query parameters shared by the list endpoints. A page
+// without a limit is given the default one by the interactors, which also check the limit is not above
+// the maximum. Results can only be sorted by id, since pages are read in key order; the ids of orders
+// are generated in the order the orders are created, so that orders sorted by id are sorted by
+// creation time too.
+func parsePage(values url.Values) (domain.Page, []serializer.ErrorInfo) {
+	page := domain.Page{Cursor: values.Get("cursor")}
+	errs := make([]serializer.ErrorInfo, 0)
+
+	if limit := values.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			errs = append(errs, serializer.ErrorInfo{Field: "limit", Detail: "must be a number of at least 1"})
+		} else {
+			page.Limit = parsed
+		}
+	}
+
+	switch values.Get("sort") {
+	case "", "id":
+	case "-id":
+		page.Descending = true
+	default:
+		errs = append(errs, serializer.ErrorInfo{Field: "sort", Detail: "must be one of 'id' and '-id'. results can only be sorted by id"})
+	}
+
+	return page, errs
+}
+
+func parseOrderQuery(values url.Values) (domain.OrderQuery, []serializer.ErrorInfo) {
+	page, errs := parsePage(values)
+	query := domain.OrderQuery{Page: page}
+
+	if status := values.Get("status"); status != "" {
+		query.Status = domain.OrderStatus(status)
+		if !query.Status.IsValid() {
+			errs = append(errs, serializer.ErrorInfo{Field: "status", Detail: domain.ErrInvalidOrderStatus.Error()})
+		}
+	}
+	query.CustomerID = values.Get("customer_id")
+	return query, errs
+}
+
+func parseProductQuery(values url.Values) (domain.ProductQuery, []serializer.ErrorInfo) {
+	page, errs := parsePage(values)
+	query := domain.ProductQuery{Page: page}
+
+	if category := values.Get("category"); category != "" {
+		query.Category = domain.ProductCategory(category)
+		if !query.Category.IsValid() {
+			errs = append(errs, serializer.ErrorInfo{Field: "category", Detail: domain.ErrInvalidProductCategory.Error()})
+		}
+	}
+
+	if minPrice := values.Get("min_price"); minPrice != "" {
+		parsed, err := strconv.ParseFloat(minPrice, 64)
+		if err != nil || parsed < 0 {
+			errs = append(errs, serializer.ErrorInfo{Field: "min_price", Detail: "must be a number which is not negative"})
+		} else {
+			query.MinPrice = parsed
+		}
+	}
+	return query, errs
+}
+
+type pageEnvelopeKey struct{}
+
+// PageEnvelope makes the list handlers it wraps answer with a serializer.Page, which holds the items
+// of the page and the cursor of the next page, rather than with a bare array of the items. It serves
+// the /v2 list routes, whose bodies are of that form; the other list routes keep their array bodies.
+func PageEnvelope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), pageEnvelopeKey{}, true)))
+	})
+}
+
+// writePage writes the items of a page as a JSON array, or in a serializer.Page behind PageEnvelope.
+// The cursor of the next page, when there is one, is given in the X-Next-Cursor header and in the
+// next link of the Link header either way.
+func writePage(w http.ResponseWriter, r *http.Request, items interface{}, nextCursor string) {
+	var body interface{} = items
+	if enveloped, _ := r.Context().Value(pageEnvelopeKey{}).(bool); enveloped {
+		body = serializer.Page{Data: items, NextCursor: nextCursor}
+	}
+	responseJSON, err := json.Marshal(body)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	if nextCursor != "" {
+		values := r.URL.Query()
+		values.Set("cursor", nextCursor)
+		next := url.URL{Path: r.URL.Path, RawQuery: values.Encode()}
+		w.Header().Set(NextCursorHeader, nextCursor)
+		w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+	}
+	w.Write(responseJSON)
+}
+
+// listErrorStatus answers an invalid query with 400, and a page which could not be read with 500.
+func listErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidQuery):
+		return http.StatusBadRequest
+	case errors.Is(err, usecases.ErrForbidden):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,99 @@
+package webservice
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/metrics"
+	"simple-order-service/pkg/ratelimit"
+	"strconv"
+	"time"
+)
+
+var rateLimitedRequests = metrics.Default.NewCounterVec("http_rate_limited_requests_total",
+	"Requests rejected because the client went over its rate limit, by group of routes.", "group")
+
+// RateLimits holds the rate limits of the groups of routes: reading the catalogue, and changing
+// orders.
+type RateLimits struct {
+	CatalogueReads *RateLimitMiddleware
+	OrderWrites    *RateLimitMiddleware
+}
+
+// RateLimitMiddleware holds every client to a rate limit on a group of routes. Clients are told
+// apart by the principal their API key or token was issued to, or by their IP address when they are
+// not authenticated.
+type RateLimitMiddleware struct {
+	group   string
+	limiter *ratelimit.Limiter
+}
+
+// NewRateLimitMiddleware lets a client make requestsPerMinute requests a minute on average, and up to
+// burst requests at once. The limits of at most maxClients clients are kept; requests are not limited
+// when requestsPerMinute is 0.
+func NewRateLimitMiddleware(group string, requestsPerMinute, burst, maxClients int) *RateLimitMiddleware {
+	middleware := &RateLimitMiddleware{group: group}
+	if requestsPerMinute > 0 {
+		middleware.limiter = ratelimit.NewLimiter(float64(requestsPerMinute)/60, burst, maxClients)
+	}
+	return middleware
+}
+
+// Limiter returns the limiter the clients are held to, which is nil when requests are not limited. It
+// is shared with the gRPC server, so that the calls of a client count against the same limits.
+func (middleware *RateLimitMiddleware) Limiter() *ratelimit.Limiter {
+	if middleware == nil {
+		return nil
+	}
+	return middleware.limiter
+}
+
+// Handler rejects the requests of a client which went over its limit with 429, telling it when to
+// retry in the Retry-After header. The X-RateLimit headers of every response tell the client how many
+// requests it may make, how many it has left and in how many seconds it will have all of them again.
+func (middleware *RateLimitMiddleware) Handler(next http.Handler) http.Handler {
+	if middleware == nil || middleware.limiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decision := middleware.limiter.Allow(rateLimitKey(r), time.Now())
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+		if decision.Allowed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rateLimitedRequests.Inc(middleware.group)
+		retryAfter := ceilSeconds(decision.RetryAfter)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "too many requests. retry in " + strconv.Itoa(retryAfter) + " seconds",
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write(failureResponse.ToJSON())
+	})
+}
+
+// rateLimitKey identifies the client of a request. The address of the client is the one of the
+// connection, since the service does not know which proxies in front of it can be trusted to set
+// X-Forwarded-For.
+func rateLimitKey(r *http.Request) string {
+	if principal, ok := usecases.PrincipalFrom(r.Context()); ok {
+		return ratelimit.PrincipalKey(string(principal.Role), principal.Subject)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
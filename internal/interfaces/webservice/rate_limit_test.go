@@ -0,0 +1,109 @@
+package webservice_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/webservice"
+	"simple-order-service/internal/usecases"
+	"testing"
+)
+
+// newRateLimitedHandler lets each client make one request a minute, and two at once.
+func newRateLimitedHandler() http.Handler {
+	middleware := webservice.NewRateLimitMiddleware("test", 1, 2, 100)
+	return middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func rateLimitedRequest(remoteAddr string, principal *domain.Principal) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/products", nil)
+	r.RemoteAddr = remoteAddr
+	if principal != nil {
+		r = r.WithContext(usecases.WithPrincipal(r.Context(), *principal))
+	}
+	return r
+}
+
+func TestRateLimitMiddleware_RejectsClientsOverTheirLimit(t *testing.T) {
+	handler := newRateLimitedHandler()
+
+	for _, wantRemaining := range []string{"1", "0"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, rateLimitedRequest("10.0.0.1:1234", nil))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Got: %v, Want: %v", w.Code, http.StatusNoContent)
+		}
+		if w.Header().Get("X-RateLimit-Limit") != "2" || w.Header().Get("X-RateLimit-Remaining") != wantRemaining {
+			t.Errorf("Got: limit %q and %q remaining, Want: limit %q and %q remaining",
+				w.Header().Get("X-RateLimit-Limit"), w.Header().Get("X-RateLimit-Remaining"), "2", wantRemaining)
+		}
+		if w.Header().Get("X-RateLimit-Reset") == "" || w.Header().Get("Retry-After") != "" {
+			t.Errorf("Got: reset %q and Retry-After %q, Want: a reset and no Retry-After",
+				w.Header().Get("X-RateLimit-Reset"), w.Header().Get("Retry-After"))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, rateLimitedRequest("10.0.0.1:1234", nil))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("Got: %v with Retry-After %q, Want: %v with Retry-After %q", w.Code, w.Header().Get("Retry-After"), http.StatusTooManyRequests, "60")
+	}
+	if w.Header().Get("X-RateLimit-Remaining") != "0" || w.Header().Get("X-RateLimit-Reset") != "120" {
+		t.Errorf("Got: %q remaining, reset in %q, Want: %q remaining, reset in %q",
+			w.Header().Get("X-RateLimit-Remaining"), w.Header().Get("X-RateLimit-Reset"), "0", "120")
+	}
+}
+
+func TestRateLimitMiddleware_KeysClientsByPrincipalOrElseByAddress(t *testing.T) {
+	handler := newRateLimitedHandler()
+	alice := &domain.Principal{Subject: "alice", Role: domain.RoleCustomer}
+	bob := &domain.Principal{Subject: "bob", Role: domain.RoleCustomer}
+	exhaust := func(r func() *http.Request) {
+		for i := 0; i < 2; i++ {
+			handler.ServeHTTP(httptest.NewRecorder(), r())
+		}
+	}
+	serve := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Alice uses up her limit from one address and is still held to it from another.
+	exhaust(func() *http.Request { return rateLimitedRequest("10.0.0.1:1234", alice) })
+	if got := serve(rateLimitedRequest("10.0.0.2:1234", alice)); got != http.StatusTooManyRequests {
+		t.Errorf("Got: %v, Want: %v", got, http.StatusTooManyRequests)
+	}
+	// Bob and the anonymous clients of the same address have limits of their own.
+	if got := serve(rateLimitedRequest("10.0.0.1:1234", bob)); got != http.StatusNoContent {
+		t.Errorf("Got: %v, Want: %v", got, http.StatusNoContent)
+	}
+	if got := serve(rateLimitedRequest("10.0.0.1:1234", nil)); got != http.StatusNoContent {
+		t.Errorf("Got: %v, Want: %v", got, http.StatusNoContent)
+	}
+
+	// Anonymous clients are told apart by address, whichever port they connect from.
+	exhaust(func() *http.Request { return rateLimitedRequest("10.0.0.3:1234", nil) })
+	if got := serve(rateLimitedRequest("10.0.0.3:5678", nil)); got != http.StatusTooManyRequests {
+		t.Errorf("Got: %v, Want: %v", got, http.StatusTooManyRequests)
+	}
+	if got := serve(rateLimitedRequest("10.0.0.4:1234", nil)); got != http.StatusNoContent {
+		t.Errorf("Got: %v, Want: %v", got, http.StatusNoContent)
+	}
+}
+
+func TestRateLimitMiddleware_DoesNotLimitWithoutARate(t *testing.T) {
+	middleware := webservice.NewRateLimitMiddleware("test", 0, 0, 100)
+	handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for i := 0; i < 5; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, rateLimitedRequest("10.0.0.1:1234", nil))
+		if w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatalf("Got: %v with limit %q, Want: %v without one", w.Code, w.Header().Get("X-RateLimit-Limit"), http.StatusNoContent)
+		}
+	}
+}
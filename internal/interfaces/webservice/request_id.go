@@ -0,0 +1,71 @@
+package webservice
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"simple-order-service/pkg/logging"
+	"time"
+)
+
+// RequestIDHeader carries the id of a request. An id sent by the client, or by a proxy in front of the
+// service, is kept so that a request can be followed across services; one is generated otherwise. The
+// id is sent back in the response either way.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware gives every request an id, and a logger carrying the id, the method and the path
+// of the request. The logger is passed down to the interactors and repositories in the context of the
+// request, so that every line logged while handling the request can be traced back to it. A line is
+// logged once the request has been handled, with its status code and duration.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		logger := logging.FromContext(r.Context()).With("request_id", requestID, "method", r.Method, "path", r.URL.Path)
+		r = r.WithContext(logging.NewContext(r.Context(), logger))
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// The probes are polled every few seconds, and would drown the other requests at info level.
+		log := logger.Info
+		if isHealthPath(r.URL.Path) {
+			log = logger.Debug
+		}
+		log("request handled", "status", recorder.statusCode, "duration_ms", time.Since(start).Milliseconds())
+	})
+}
+
+// isValidRequestID accepts the ids which can be written to the logs and echoed in a header as they
+// are: printable ASCII without spaces, of a bounded length.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, char := range requestID {
+		if char <= ' ' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
+
+// logRequestError logs an error met while handling a request with the logger of the request.
+func logRequestError(r *http.Request, err error) {
+	logging.FromContext(r.Context()).Warn("request failed", "error", err)
+}
@@ -1,15 +1,33 @@
 package webservice
 
 import (
+	"context"
 	"io"
-	"log"
 	"net/http"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/logging"
+	"simple-order-service/pkg/metrics"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
-func SetupRoutes(orderInteractor OrderInteractor, productInteractor ProductInteractor) *mux.Router {
+// SetupRoutes registers the routes of the API. The probes, the metrics, the OpenAPI document and the
+// exchange of API keys for tokens are public; every other route requires a principal with one of the
+// roles allowed on it. Requests are validated against the OpenAPI document before they are handled.
+// The lists are served under /v2 too, with the cursor of the next page in their bodies.
+// Request bodies larger than maxBodyBytes are rejected with 413. The event streams end once shutdown
+// is closed, since they never complete on their own.
+func SetupRoutes(orderInteractor OrderInteractor, productInteractor ProductInteractor, couponInteractor CouponInteractor, customerInteractor CustomerInteractor, webhookInteractor WebhookInteractor, feedRepository domain.FeedRepository, authenticator Authenticator, health *Health, idempotency *IdempotencyMiddleware, rateLimits RateLimits, limits domain.Limits, maxBodyBytes int64, shutdown <-chan struct{}) *mux.Router {
+	document := NewAPIDocument(limits)
 	router := mux.NewRouter()
+	router.Use(RequestIDMiddleware)
+	router.Use(MetricsMiddleware)
+	router.Use(BodyLimitMiddleware(maxBodyBytes))
+	router.Use(NewAuthMiddleware(authenticator).Handler)
+	router.Use(health.Gate)
+	router.Use(NewValidationMiddleware(document).Handler)
+	router.Use(idempotency.Handler)
 
 	router.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -17,19 +35,73 @@ func SetupRoutes(orderInteractor OrderInteractor, productInteractor ProductInter
 		str := `{"status": "OK"}`
 		io.WriteString(w, str)
 	}).Methods(http.MethodGet)
-	router.Handle("/orders", NewGetAllOrdersHandler(orderInteractor)).Methods(http.MethodGet)
-	router.Handle("/orders/{id}", NewGetOrderDetailsHandler(orderInteractor)).Methods(http.MethodGet)
-	router.Handle("/orders/{id}", NewUpdateOrderHandler(orderInteractor)).Methods(http.MethodPut)
-	router.Handle("/orders/{id}/products", NewAddProductToOrderHandler(orderInteractor)).Methods(http.MethodPost)
-	router.Handle("/orders/{id}/products", NewGetAllOrderedProductsHandler(orderInteractor)).Methods(http.MethodGet)
-	router.Handle("/products", NewGetAllProductsHandler(productInteractor)).Methods(http.MethodGet)
-	router.Handle("/products/{id}", NewGetProductDetailsHandler(productInteractor)).Methods(http.MethodGet)
+	router.Handle("/healthz", NewLivenessHandler()).Methods(http.MethodGet)
+	router.Handle("/readyz", NewReadinessHandler(health)).Methods(http.MethodGet)
+	router.Handle("/metrics", NewMetricsHandler(metrics.Default)).Methods(http.MethodGet)
+	router.Handle("/openapi.json", NewOpenAPIHandler(document)).Methods(http.MethodGet)
+	router.Handle("/tokens", NewIssueTokenHandler(authenticator)).Methods(http.MethodPost)
+	router.Handle("/events", Require(NewEventStreamHandler(feedRepository, shutdown), staffRoles...)).Methods(http.MethodGet)
+	router.Handle("/orders", Require(NewGetAllOrdersHandler(orderInteractor), staffRoles...)).Methods(http.MethodGet)
+	router.Handle("/v2/orders", Require(PageEnvelope(NewGetAllOrdersHandler(orderInteractor)), staffRoles...)).Methods(http.MethodGet)
+	router.Handle("/orders", rateLimits.OrderWrites.Handler(Require(NewCreateOrderHandler(orderInteractor), anyRole...))).Methods(http.MethodPost)
+	router.Handle("/orders/{id}", Require(NewGetOrderDetailsHandler(orderInteractor), anyRole...)).Methods(http.MethodGet)
+	router.Handle("/orders/{id}", rateLimits.OrderWrites.Handler(Require(NewUpdateOrderHandler(orderInteractor), anyRole...))).Methods(http.MethodPut)
+	router.Handle("/orders/{id}/products", rateLimits.OrderWrites.Handler(Require(NewAddProductToOrderHandler(orderInteractor), customerRoles...))).Methods(http.MethodPost)
+	router.Handle("/orders/{id}/products", Require(NewGetAllOrderedProductsHandler(orderInteractor), anyRole...)).Methods(http.MethodGet)
+	router.Handle("/orders/{id}/history", Require(NewGetOrderHistoryHandler(orderInteractor), anyRole...)).Methods(http.MethodGet)
+	router.Handle("/orders/{id}/coupons", rateLimits.OrderWrites.Handler(Require(NewApplyCouponToOrderHandler(orderInteractor), customerRoles...))).Methods(http.MethodPost)
+	router.Handle("/orders/{id}/coupons/{code}", rateLimits.OrderWrites.Handler(Require(NewRemoveCouponFromOrderHandler(orderInteractor), customerRoles...))).Methods(http.MethodDelete)
+	router.Handle("/products", rateLimits.CatalogueReads.Handler(Require(NewGetAllProductsHandler(productInteractor), anyRole...))).Methods(http.MethodGet)
+	router.Handle("/v2/products", rateLimits.CatalogueReads.Handler(Require(PageEnvelope(NewGetAllProductsHandler(productInteractor)), anyRole...))).Methods(http.MethodGet)
+	router.Handle("/products", Require(NewCreateProductHandler(productInteractor), adminRoles...)).Methods(http.MethodPost)
+	router.Handle("/products/{id}", rateLimits.CatalogueReads.Handler(Require(NewGetProductDetailsHandler(productInteractor), anyRole...))).Methods(http.MethodGet)
+	router.Handle("/products/{id}", Require(NewReplaceProductHandler(productInteractor), adminRoles...)).Methods(http.MethodPut)
+	router.Handle("/products/{id}", Require(NewUpdateProductHandler(productInteractor), adminRoles...)).Methods(http.MethodPatch)
+	router.Handle("/products/{id}", Require(NewDeleteProductHandler(productInteractor), adminRoles...)).Methods(http.MethodDelete)
+	router.Handle("/coupons", Require(NewGetAllCouponsHandler(couponInteractor), adminRoles...)).Methods(http.MethodGet)
+	router.Handle("/coupons", Require(NewCreateCouponHandler(couponInteractor), adminRoles...)).Methods(http.MethodPost)
+	router.Handle("/coupons/{code}", Require(NewGetCouponDetailsHandler(couponInteractor), adminRoles...)).Methods(http.MethodGet)
+	router.Handle("/customers", Require(NewGetAllCustomersHandler(customerInteractor), staffRoles...)).Methods(http.MethodGet)
+	router.Handle("/customers", Require(NewCreateCustomerHandler(customerInteractor), staffRoles...)).Methods(http.MethodPost)
+	router.Handle("/customers/{id}", Require(NewGetCustomerDetailsHandler(customerInteractor), anyRole...)).Methods(http.MethodGet)
+	router.Handle("/customers/{id}/orders", Require(NewGetCustomerOrdersHandler(customerInteractor, orderInteractor), anyRole...)).Methods(http.MethodGet)
+	router.Handle("/v2/customers/{id}/orders", Require(PageEnvelope(NewGetCustomerOrdersHandler(customerInteractor, orderInteractor)), anyRole...)).Methods(http.MethodGet)
+	router.Handle("/webhooks", Require(NewGetAllWebhooksHandler(webhookInteractor), adminRoles...)).Methods(http.MethodGet)
+	router.Handle("/webhooks", Require(NewCreateWebhookHandler(webhookInteractor), adminRoles...)).Methods(http.MethodPost)
+	router.Handle("/webhooks/{id}", Require(NewGetWebhookDetailsHandler(webhookInteractor), adminRoles...)).Methods(http.MethodGet)
+	router.Handle("/webhooks/{id}", Require(NewReplaceWebhookHandler(webhookInteractor), adminRoles...)).Methods(http.MethodPut)
+	router.Handle("/webhooks/{id}", Require(NewDeleteWebhookHandler(webhookInteractor), adminRoles...)).Methods(http.MethodDelete)
+	router.Handle("/webhooks/{id}/dead_letters", Require(NewGetWebhookDeadLettersHandler(webhookInteractor), adminRoles...)).Methods(http.MethodGet)
 	return router
 }
 
-func StartServer(router *mux.Router) error {
-	log.Printf("Starting web server on port: %v\n", "8080")
-	if err := http.ListenAndServe(":"+"8080", router); err != nil {
+// StartServer serves the router until the context is done, then stops accepting connections and
+// waits up to shutdownTimeout for the requests in flight to complete.
+func StartServer(ctx context.Context, router *mux.Router, addr string, readTimeout, writeTimeout, shutdownTimeout time.Duration) error {
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      router,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		logging.Default().Info("starting web server", "addr", addr)
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	logging.Default().Info("shutting down web server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
 		return err
 	}
 	return nil
@@ -0,0 +1,155 @@
+package webservice
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/openapi"
+
+	"github.com/gorilla/mux"
+)
+
+// ValidationMiddleware rejects the requests whose parameters or body do not match the operation
+// described for their route in the OpenAPI document.
+type ValidationMiddleware struct {
+	document *openapi.Document
+}
+
+func NewValidationMiddleware(document *openapi.Document) *ValidationMiddleware {
+	return &ValidationMiddleware{document: document}
+}
+
+// Handler answers 400 with every invalid parameter and field of the body listed in meta.errors, or
+// 413 when the body is over the limit set by BodyLimitMiddleware.
+// Requests which are not allowed to call the operation are let through, so that they are rejected
+// with 401 or 403 rather than told what is wrong with them.
+func (middleware *ValidationMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		op := middleware.document.Operation(path, r.Method)
+		if op == nil || !allowed(r, op) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		errs := middleware.validateParameters(r, op)
+		if op.RequestBody != nil {
+			body, err := io.ReadAll(r.Body)
+			if isBodyTooLarge(err) {
+				writeBodyTooLarge(w)
+				return
+			}
+			if err != nil {
+				logRequestError(r, err)
+				errs = append(errs, openapi.FieldError{Field: "body", Reason: "could not be read"})
+			} else {
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				errs = append(errs, middleware.validateBody(op.RequestBody, body)...)
+			}
+		}
+		if len(errs) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		errorInfos := make([]serializer.ErrorInfo, len(errs))
+		for idx, err := range errs {
+			errorInfos[idx] = serializer.ErrorInfo{Field: err.Field, Detail: err.Reason}
+		}
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "invalid request. more details can be found in the 'errors' section",
+			Meta:    &serializer.Meta{Errors: errorInfos},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+	})
+}
+
+// validateParameters validates the path, query and header parameters of the operation. Parameters
+// the operation does not describe are ignored.
+func (middleware *ValidationMiddleware) validateParameters(r *http.Request, op *openapi.Operation) []openapi.FieldError {
+	errs := make([]openapi.FieldError, 0)
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+	for _, parameter := range op.Parameters {
+		parameter = middleware.document.Parameter(parameter)
+		if parameter == nil {
+			continue
+		}
+
+		var raw string
+		var present bool
+		switch parameter.In {
+		case "path":
+			raw, present = vars[parameter.Name]
+		case "query":
+			raw, present = query.Get(parameter.Name), query.Has(parameter.Name)
+		case "header":
+			raw = r.Header.Get(parameter.Name)
+			present = raw != ""
+		}
+		if !present {
+			if parameter.Required {
+				errs = append(errs, openapi.FieldError{Field: parameter.Name, Reason: "is required"})
+			}
+			continue
+		}
+		errs = append(errs, middleware.document.ValidateParameter(parameter, raw)...)
+	}
+	return errs
+}
+
+func (middleware *ValidationMiddleware) validateBody(requestBody *openapi.RequestBody, body []byte) []openapi.FieldError {
+	if len(bytes.TrimSpace(body)) == 0 {
+		if requestBody.Required {
+			return []openapi.FieldError{{Field: "body", Reason: "is required"}}
+		}
+		return nil
+	}
+	mediaType, ok := requestBody.Content[jsonContentType]
+	if !ok {
+		return nil
+	}
+	value, err := openapi.DecodeJSON(body)
+	if err != nil {
+		return []openapi.FieldError{{Field: "body", Reason: "must be valid JSON"}}
+	}
+	errs := middleware.document.ValidateValue(mediaType.Schema, "", value)
+	for idx := range errs {
+		if errs[idx].Field == "" {
+			errs[idx].Field = "body"
+		}
+	}
+	return errs
+}
+
+// allowed tells whether the principal of the request may call the operation. Anyone may call the
+// operations which are not secured.
+func allowed(r *http.Request, op *openapi.Operation) bool {
+	if len(op.Roles) == 0 {
+		return true
+	}
+	principal, ok := usecases.PrincipalFrom(r.Context())
+	if !ok {
+		return false
+	}
+	for _, role := range op.Roles {
+		if string(principal.Role) == role {
+			return true
+		}
+	}
+	return false
+}
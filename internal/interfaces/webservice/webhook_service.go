@@ -0,0 +1,298 @@
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/serializer"
+	"simple-order-service/internal/usecases"
+
+	"github.com/gorilla/mux"
+)
+
+type WebhookInteractor interface {
+	Create(ctx context.Context, input usecases.Webhook) (usecases.Webhook, error)
+	GetDetails(ctx context.Context, id string) (usecases.Webhook, error)
+	GetAll(ctx context.Context) []usecases.Webhook
+	Replace(ctx context.Context, id string, input usecases.Webhook) (usecases.Webhook, error)
+	Delete(ctx context.Context, id string) error
+	DeadLetters(ctx context.Context, id string) ([]usecases.DeadLetter, error)
+}
+
+type CreateWebhookHandler struct {
+	webhookInteractor WebhookInteractor
+}
+
+type GetWebhookDetailsHandler struct {
+	webhookInteractor WebhookInteractor
+}
+
+type GetAllWebhooksHandler struct {
+	webhookInteractor WebhookInteractor
+}
+
+type ReplaceWebhookHandler struct {
+	webhookInteractor WebhookInteractor
+}
+
+type DeleteWebhookHandler struct {
+	webhookInteractor WebhookInteractor
+}
+
+type GetWebhookDeadLettersHandler struct {
+	webhookInteractor WebhookInteractor
+}
+
+func NewCreateWebhookHandler(webhookInteractor WebhookInteractor) CreateWebhookHandler {
+	return CreateWebhookHandler{webhookInteractor: webhookInteractor}
+}
+
+func NewGetWebhookDetailsHandler(webhookInteractor WebhookInteractor) GetWebhookDetailsHandler {
+	return GetWebhookDetailsHandler{webhookInteractor: webhookInteractor}
+}
+
+func NewGetAllWebhooksHandler(webhookInteractor WebhookInteractor) GetAllWebhooksHandler {
+	return GetAllWebhooksHandler{webhookInteractor: webhookInteractor}
+}
+
+func NewReplaceWebhookHandler(webhookInteractor WebhookInteractor) ReplaceWebhookHandler {
+	return ReplaceWebhookHandler{webhookInteractor: webhookInteractor}
+}
+
+func NewDeleteWebhookHandler(webhookInteractor WebhookInteractor) DeleteWebhookHandler {
+	return DeleteWebhookHandler{webhookInteractor: webhookInteractor}
+}
+
+func NewGetWebhookDeadLettersHandler(webhookInteractor WebhookInteractor) GetWebhookDeadLettersHandler {
+	return GetWebhookDeadLettersHandler{webhookInteractor: webhookInteractor}
+}
+
+// webhookErrorStatus maps the errors returned by the webhook interactor to HTTP status codes.
+func webhookErrorStatus(err error) int {
+	var webhookErr *domain.WebhookError
+	switch {
+	case errors.Is(err, usecases.ErrWebhookNotFound):
+		return http.StatusNotFound
+	case errors.As(err, &webhookErr):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (handler CreateWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	decoder := json.NewDecoder(r.Body)
+
+	var req serializer.WebhookRequest
+	if err := decoder.Decode(&req); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "unable to parse JSON data",
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	webhook, err := handler.webhookInteractor.Create(r.Context(), usecases.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(webhookErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(webhook)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	// The response holds the secret of the webhook, which must not be kept by caches or replayed from
+	// the stored responses of idempotent requests.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Location", "/webhooks/"+webhook.ID)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(responseJSON)
+}
+
+func (handler GetWebhookDetailsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	webhookID := vars["id"]
+
+	webhook, err := handler.webhookInteractor.GetDetails(r.Context(), webhookID)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(webhookErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(webhook)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Write(responseJSON)
+}
+
+func (handler GetAllWebhooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	webhooks := handler.webhookInteractor.GetAll(r.Context())
+
+	responseJSON, err := json.Marshal(webhooks)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Write(responseJSON)
+}
+
+func (handler ReplaceWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	webhookID := vars["id"]
+
+	decoder := json.NewDecoder(r.Body)
+
+	var req serializer.WebhookRequest
+	if err := decoder.Decode(&req); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: "unable to parse JSON data",
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	webhook, err := handler.webhookInteractor.Replace(r.Context(), webhookID, usecases.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(webhookErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(webhook)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Write(responseJSON)
+}
+
+func (handler DeleteWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	webhookID := vars["id"]
+
+	if err := handler.webhookInteractor.Delete(r.Context(), webhookID); err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(webhookErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	successResponse := serializer.Response{
+		Status:  "success",
+		Message: "webhook deleted",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(successResponse.ToJSON())
+}
+
+func (handler GetWebhookDeadLettersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	webhookID := vars["id"]
+
+	deadLetters, err := handler.webhookInteractor.DeadLetters(r.Context(), webhookID)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(webhookErrorStatus(err))
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	responseJSON, err := json.Marshal(deadLetters)
+	if err != nil {
+		logRequestError(r, err)
+		failureResponse := serializer.Response{
+			Status:  "error",
+			Message: err.Error(),
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(failureResponse.ToJSON())
+		return
+	}
+
+	w.Write(responseJSON)
+}
@@ -0,0 +1,135 @@
+package webservice_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/interfaces/webservice"
+	"simple-order-service/internal/usecases"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newWebhookInteractor() (*usecases.WebhookInteractor, map[string]domain.Webhook) {
+	webhooks := make(map[string]domain.Webhook)
+	webhookRepoMock := &domain.WebhookRepositoryMock{
+		StoreFunc: func(ctx context.Context, webhook domain.Webhook) error {
+			webhooks[webhook.ID()] = webhook
+			return nil
+		},
+		FindByIdFunc: func(ctx context.Context, id string) domain.Webhook {
+			return webhooks[id]
+		},
+		DeleteFunc: func(ctx context.Context, id string) error {
+			delete(webhooks, id)
+			return nil
+		},
+	}
+	outboxRepoMock := &domain.OutboxRepositoryMock{
+		DeadLettersFunc: func(ctx context.Context, webhookID string) []domain.WebhookDelivery {
+			return []domain.WebhookDelivery{}
+		},
+	}
+	unitOfWorkMock := &domain.UnitOfWorkMock{
+		DoFunc: func(ctx context.Context, fn func(repos domain.Repositories) error) error {
+			return fn(domain.Repositories{Webhooks: webhookRepoMock})
+		},
+	}
+	return usecases.NewWebhookInteractor(webhookRepoMock, outboxRepoMock, unitOfWorkMock, nil), webhooks
+}
+
+func webhookRequest(method, target, body, id string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if id != "" {
+		r = mux.SetURLVars(r, map[string]string{"id": id})
+	}
+	return r
+}
+
+func TestCreateWebhookHandler_ShowsSecretOnceWithoutCaching(t *testing.T) {
+	webhookInteractor, _ := newWebhookInteractor()
+
+	w := httptest.NewRecorder()
+	webservice.NewCreateWebhookHandler(webhookInteractor).ServeHTTP(w, webhookRequest(http.MethodPost, "/webhooks", `{"url": "https://example.com/hooks", "event_types": ["status_changed"]}`, ""))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Got: %v, Want: %v", w.Code, http.StatusCreated)
+	}
+	var created usecases.Webhook
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.Secret == "" {
+		t.Fatalf("Got: %v, %v, Want: the webhook along with its secret", w.Body.String(), err)
+	}
+	if w.Header().Get("Location") != "/webhooks/"+created.ID || w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Got: %v, Want: the location of the webhook, and no caching", w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	webservice.NewGetWebhookDetailsHandler(webhookInteractor).ServeHTTP(w, webhookRequest(http.MethodGet, "/webhooks/"+created.ID, "", created.ID))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), created.Secret) {
+		t.Errorf("Got: %v %v, Want: %v without the secret", w.Code, w.Body.String(), http.StatusOK)
+	}
+}
+
+func TestCreateWebhookHandler_RejectsInvalidWebhooks(t *testing.T) {
+	webhookInteractor, webhooks := newWebhookInteractor()
+	bodies := []string{
+		`{"url": "https://example.com/hooks"`,
+		`{"url": "/hooks"}`,
+		`{"url": "http://169.254.169.254/latest/meta-data"}`,
+		`{"url": "https://example.com/hooks", "event_types": ["order_deleted"]}`,
+	}
+	for _, body := range bodies {
+		w := httptest.NewRecorder()
+		webservice.NewCreateWebhookHandler(webhookInteractor).ServeHTTP(w, webhookRequest(http.MethodPost, "/webhooks", body, ""))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: Got: %v, Want: %v", body, w.Code, http.StatusBadRequest)
+		}
+	}
+	if len(webhooks) != 0 {
+		t.Errorf("Got: %v webhooks, Want: 0", len(webhooks))
+	}
+}
+
+func TestWebhookHandlers_AnswerNotFoundForUnknownWebhook(t *testing.T) {
+	webhookInteractor, _ := newWebhookInteractor()
+	handlers := map[string]http.Handler{
+		http.MethodGet:    webservice.NewGetWebhookDetailsHandler(webhookInteractor),
+		http.MethodPut:    webservice.NewReplaceWebhookHandler(webhookInteractor),
+		http.MethodDelete: webservice.NewDeleteWebhookHandler(webhookInteractor),
+		"dead letters":    webservice.NewGetWebhookDeadLettersHandler(webhookInteractor),
+	}
+	for name, handler := range handlers {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, webhookRequest(http.MethodPut, "/webhooks/unknown", `{"url": "https://example.com/hooks"}`, "unknown"))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: Got: %v, Want: %v", name, w.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestReplaceWebhookHandler_KeepsSecretWhenNoneIsGiven(t *testing.T) {
+	webhookInteractor, webhooks := newWebhookInteractor()
+	created, err := webhookInteractor.Create(context.Background(), usecases.Webhook{URL: "https://example.com/hooks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	webservice.NewReplaceWebhookHandler(webhookInteractor).ServeHTTP(w, webhookRequest(http.MethodPut, "/webhooks/"+created.ID, `{"url": "https://example.org/hooks"}`, created.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Got: %v, Want: %v", w.Code, http.StatusOK)
+	}
+	replaced := webhooks[created.ID]
+	if replaced.URL() != "https://example.org/hooks" || replaced.Secret() != created.Secret {
+		t.Errorf("Got: %v %v, Want: the new url and the secret the webhook was created with", replaced.URL(), replaced.Secret())
+	}
+
+	w = httptest.NewRecorder()
+	webservice.NewDeleteWebhookHandler(webhookInteractor).ServeHTTP(w, webhookRequest(http.MethodDelete, "/webhooks/"+created.ID, "", created.ID))
+	if w.Code != http.StatusOK || len(webhooks) != 0 {
+		t.Errorf("Got: %v, %v webhooks, Want: %v, 0 webhooks", w.Code, len(webhooks), http.StatusOK)
+	}
+}
@@ -0,0 +1,17 @@
+package serializer
+
+import "time"
+
+type CreateCouponRequest struct {
+	Code           string    `json:"code"`
+	DiscountType   string    `json:"discount_type"`
+	Value          float64   `json:"value"`
+	ValidFrom      time.Time `json:"valid_from"`
+	ValidUntil     time.Time `json:"valid_until"`
+	MaxRedemptions int       `json:"max_redemptions,omitempty"`
+	Stackable      bool      `json:"stackable,omitempty"`
+}
+
+type ApplyCouponRequest struct {
+	Code string `json:"code"`
+}
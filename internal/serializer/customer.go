@@ -0,0 +1,7 @@
+package serializer
+
+type CreateCustomerRequest struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Contact string `json:"contact,omitempty"`
+}
@@ -0,0 +1,14 @@
+package serializer
+
+import "encoding/json"
+
+type HealthResponse struct {
+	Status  string            `json:"status"`
+	Checks  map[string]string `json:"checks,omitempty"`
+	Workers map[string]string `json:"workers,omitempty"`
+}
+
+func (resp *HealthResponse) ToJSON() []byte {
+	jsonBytes, _ := json.Marshal(resp)
+	return jsonBytes
+}
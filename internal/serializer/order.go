@@ -1,7 +1,14 @@
 package serializer
 
+// CreateOrderRequest may name the customer an order is created for. Only staff may create orders for
+// customers; the orders of customers are created for themselves.
+type CreateOrderRequest struct {
+	CustomerID string `json:"customer_id,omitempty"`
+}
+
 type AddProductToOrderRequest struct {
 	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity,omitempty"`
 }
 
 type UpdateOrderRequest struct {
@@ -0,0 +1,23 @@
+package serializer
+
+type CreateProductRequest struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Price    float64 `json:"price"`
+	SKU      int     `json:"sku"`
+}
+
+type ReplaceProductRequest struct {
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Price    float64 `json:"price"`
+	SKU      int     `json:"sku"`
+}
+
+type UpdateProductRequest struct {
+	Name     *string  `json:"name,omitempty"`
+	Category *string  `json:"category,omitempty"`
+	Price    *float64 `json:"price,omitempty"`
+	SKU      *int     `json:"sku,omitempty"`
+}
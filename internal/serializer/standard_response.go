@@ -3,17 +3,27 @@ package serializer
 import "encoding/json"
 
 type Response struct {
-	Status  string `json:"status,omitempty"`
-	Message string `json:"message,omitempty"`
-	Code    string `json:"code,omitempty"`
-	Meta    *Meta  `json:"meta,omitempty"`
+	Status  string      `json:"status,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Code    string      `json:"code,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	Meta    *Meta       `json:"meta,omitempty"`
+}
+
+// Page is a page of a list with the cursor of the next page, which is missing on the last page.
+type Page struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
 
 type Meta struct {
 	Errors []ErrorInfo `json:"errors,omitempty"`
 }
 
+// ErrorInfo tells what is wrong with a request. Field names the parameter or the field of the body
+// the error is about, when there is one.
 type ErrorInfo struct {
+	Field  string `json:"field,omitempty"`
 	Detail string `json:"detail,omitempty"`
 }
 
@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/token"
+	"time"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAPIKeyNotFound     = errors.New("api key does not exist")
+	ErrForbidden          = errors.New("not allowed to access this resource")
+)
+
+// APIKey holds the key itself only when it is created, since only a hash of its secret is stored.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Subject   string    `json:"subject"`
+	Role      string    `json:"role"`
+	Key       string    `json:"key,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Token struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AuthInteractor manages the API keys of principals, and the bearer tokens which are exchanged for
+// them.
+type AuthInteractor struct {
+	apiKeyRepository domain.APIKeyRepository
+	unitOfWork       domain.UnitOfWork
+	signer           token.Signer
+	tokenTTL         time.Duration
+}
+
+func NewAuthInteractor(apiKeyRepo domain.APIKeyRepository, unitOfWork domain.UnitOfWork, signer token.Signer, tokenTTL time.Duration) *AuthInteractor {
+	return &AuthInteractor{apiKeyRepository: apiKeyRepo, unitOfWork: unitOfWork, signer: signer, tokenTTL: tokenTTL}
+}
+
+// CreateAPIKey creates a key for the subject. The subject of a customer key is the id of the customer,
+// which must exist.
+func (interactor *AuthInteractor) CreateAPIKey(ctx context.Context, subject, role string) (APIKey, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return APIKey{}, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return APIKey{}, err
+	}
+	key := domain.NewAPIKey(id, subject, domain.Role(role), secret, time.Now().UTC())
+	if err := key.Validate(); err != nil {
+		return APIKey{}, err
+	}
+
+	err = interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		if key.Role() == domain.RoleCustomer {
+			if customer := repos.Customers.FindById(ctx, subject); customer.ID() == "" {
+				return fmt.Errorf("%w: %s", ErrCustomerNotFound, subject)
+			}
+		}
+		return repos.APIKeys.Store(ctx, key)
+	})
+	if err != nil {
+		return APIKey{}, err
+	}
+	created := toAPIKey(key)
+	created.Key = domain.FormatAPIKey(id, secret)
+	return created, nil
+}
+
+func (interactor *AuthInteractor) APIKeys(ctx context.Context) []APIKey {
+	keysFromDb := interactor.apiKeyRepository.GetAll(ctx)
+	keys := make([]APIKey, len(keysFromDb))
+	for idx, key := range keysFromDb {
+		keys[idx] = toAPIKey(key)
+	}
+	return keys
+}
+
+func (interactor *AuthInteractor) RevokeAPIKey(ctx context.Context, id string) error {
+	return interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		existing := repos.APIKeys.FindById(ctx, id)
+		if existing.ID() == "" {
+			return ErrAPIKeyNotFound
+		}
+		return repos.APIKeys.Delete(ctx, id)
+	})
+}
+
+// Authenticate returns the principal the API key was created for.
+func (interactor *AuthInteractor) Authenticate(ctx context.Context, apiKey string) (domain.Principal, error) {
+	id, secret, ok := domain.ParseAPIKey(apiKey)
+	if !ok {
+		return domain.Principal{}, ErrInvalidCredentials
+	}
+	key := interactor.apiKeyRepository.FindById(ctx, id)
+	if key.ID() == "" || !key.Matches(secret) {
+		return domain.Principal{}, ErrInvalidCredentials
+	}
+	return key.Principal(), nil
+}
+
+// IssueToken signs a bearer token for the principal, which is valid for the token TTL. Tokens cannot
+// be revoked, so revoking an API key only stops it from being exchanged for new tokens.
+func (interactor *AuthInteractor) IssueToken(principal domain.Principal) (Token, error) {
+	issuedAt := time.Now().UTC()
+	expiresAt := issuedAt.Add(interactor.tokenTTL)
+	signed, err := interactor.signer.Sign(token.Claims{
+		Subject:   principal.Subject,
+		Role:      string(principal.Role),
+		IssuedAt:  issuedAt.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{Token: signed, TokenType: "Bearer", ExpiresAt: expiresAt.Truncate(time.Second)}, nil
+}
+
+// VerifyToken returns the principal a bearer token was issued to.
+func (interactor *AuthInteractor) VerifyToken(bearer string) (domain.Principal, error) {
+	claims, err := interactor.signer.Verify(bearer, time.Now())
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: %s", ErrInvalidCredentials, err)
+	}
+	principal := domain.Principal{Subject: claims.Subject, Role: domain.Role(claims.Role)}
+	if principal.Subject == "" || !principal.Role.IsValid() {
+		return domain.Principal{}, ErrInvalidCredentials
+	}
+	return principal, nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal a request is made by. The interactors check
+// what the principal of the context is allowed to do; a context without a principal is one of the
+// service itself, which is allowed to do anything.
+func WithPrincipal(ctx context.Context, principal domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFrom(ctx context.Context) (domain.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(domain.Principal)
+	return principal, ok
+}
+
+func canView(ctx context.Context, order domain.Order) error {
+	if principal, ok := PrincipalFrom(ctx); ok && !principal.CanView(order) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func canChange(ctx context.Context, order domain.Order) error {
+	if principal, ok := PrincipalFrom(ctx); ok && !principal.CanChange(order) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func canChangeStatus(ctx context.Context, order domain.Order, status domain.OrderStatus) error {
+	if principal, ok := PrincipalFrom(ctx); ok && !principal.CanChangeStatus(order, status) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func canChangeDispatchDate(ctx context.Context, order domain.Order) error {
+	if principal, ok := PrincipalFrom(ctx); ok && !principal.CanChangeDispatchDate(order) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// customerOf returns the id of the customer a request is made by, which is empty for staff.
+func customerOf(ctx context.Context) string {
+	if principal, ok := PrincipalFrom(ctx); ok && principal.Role == domain.RoleCustomer {
+		return principal.Subject
+	}
+	return ""
+}
+
+func toAPIKey(key domain.APIKey) APIKey {
+	return APIKey{
+		ID:        key.ID(),
+		Subject:   key.Subject(),
+		Role:      string(key.Role()),
+		CreatedAt: key.CreatedAt(),
+	}
+}
@@ -0,0 +1,142 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/metrics"
+	"simple-order-service/pkg/token"
+	"testing"
+	"time"
+)
+
+func TestAuthenticate_ReturnsPrincipalOfKey(t *testing.T) {
+	keys := make(map[string]domain.APIKey)
+	apiKeyRepoMock := &domain.APIKeyRepositoryMock{
+		StoreFunc: func(ctx context.Context, key domain.APIKey) error {
+			keys[key.ID()] = key
+			return nil
+		},
+		FindByIdFunc: func(ctx context.Context, id string) domain.APIKey {
+			return keys[id]
+		},
+	}
+	customerRepoMock := &domain.CustomerRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Customer {
+			if id != "c-1" {
+				return domain.Customer{}
+			}
+			return domain.NewCustomer("c-1", "Jane Doe", "jane@example.com", "", time.Now())
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{APIKeys: apiKeyRepoMock, Customers: customerRepoMock})
+	authInteractor := usecases.NewAuthInteractor(apiKeyRepoMock, unitOfWorkMock, token.NewSigner([]byte("a secret of at least thirty-two bytes")), time.Hour)
+
+	if _, err := authInteractor.CreateAPIKey(context.Background(), "c-2", "customer"); !errors.Is(err, usecases.ErrCustomerNotFound) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrCustomerNotFound)
+	}
+	created, err := authInteractor.CreateAPIKey(context.Background(), "c-1", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal, err := authInteractor.Authenticate(context.Background(), created.Key)
+	want := domain.Principal{Subject: "c-1", Role: domain.RoleCustomer}
+	if err != nil || principal != want {
+		t.Errorf("Got: %v, %v, Want: %v, nil", principal, err, want)
+	}
+	if _, err := authInteractor.Authenticate(context.Background(), created.Key+"0"); !errors.Is(err, usecases.ErrInvalidCredentials) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrInvalidCredentials)
+	}
+
+	issued, err := authInteractor.IssueToken(principal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := authInteractor.VerifyToken(issued.Token); err != nil || got != want {
+		t.Errorf("Got: %v, %v, Want: %v, nil", got, err, want)
+	}
+}
+
+func TestAdd_CustomerCannotAddToOrderOfAnotherCustomer(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return domain.NewCustomerOrder("1", "c-2")
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock})
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+
+	ctx := usecases.WithPrincipal(context.Background(), domain.Principal{Subject: "c-1", Role: domain.RoleCustomer})
+	if err := orderInteractor.Add(ctx, "1", "123", 1, domain.AnyVersion); !errors.Is(err, usecases.ErrForbidden) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrForbidden)
+	}
+	if _, err := orderInteractor.GetDetails(ctx, "1"); !errors.Is(err, usecases.ErrForbidden) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrForbidden)
+	}
+	if len(orderRepoMock.StoreCalls()) != 0 {
+		t.Error("order must not be stored when the customer does not own it")
+	}
+}
+
+func TestUpdateOrderStatus_CustomerPlacesOnlyTheirOwnOrder(t *testing.T) {
+	orders := map[string]domain.Order{
+		"1": domain.NewCustomerOrder("1", "c-1"),
+		"2": domain.NewCustomerOrder("2", "c-2"),
+	}
+	product := domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)
+	for id, order := range orders {
+		order.Add(product)
+		orders[id] = order
+	}
+
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return orders[id]
+		},
+		StoreFunc: func(ctx context.Context, order domain.Order) error {
+			return nil
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return product
+		},
+		StoreFunc: func(ctx context.Context, product domain.Product) error {
+			return nil
+		},
+	}
+	reservationRepoMock := &domain.ReservationRepositoryMock{
+		FindFunc: func(ctx context.Context, orderID, productID string) domain.Reservation {
+			return domain.Reservation{}
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock, Reservations: reservationRepoMock})
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+
+	ctx := usecases.WithPrincipal(context.Background(), domain.Principal{Subject: "c-1", Role: domain.RoleCustomer})
+	if _, err := orderInteractor.UpdateOrderStatus(ctx, "2", domain.OrderPlaced, "c-1", domain.AnyVersion); !errors.Is(err, usecases.ErrForbidden) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrForbidden)
+	}
+	if len(orderRepoMock.StoreCalls()) != 0 {
+		t.Fatal("order must not be stored when the customer does not own it")
+	}
+	if _, err := orderInteractor.UpdateOrderStatus(ctx, "1", domain.OrderCompleted, "c-1", domain.AnyVersion); !errors.Is(err, usecases.ErrForbidden) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrForbidden)
+	}
+	if err := orderInteractor.UpdateDispatchDate(ctx, "1", time.Now().Add(48*time.Hour).Format("2006-01-02"), domain.AnyVersion); !errors.Is(err, usecases.ErrForbidden) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrForbidden)
+	}
+
+	if _, err := orderInteractor.UpdateOrderStatus(ctx, "1", domain.OrderPlaced, "c-1", domain.AnyVersion); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	if len(orderRepoMock.StoreCalls()) != 1 || orderRepoMock.StoreCalls()[0].Order.GetOrderStatus() != domain.OrderPlaced {
+		t.Errorf("Got: %v store calls, Want: the order stored once as placed", len(orderRepoMock.StoreCalls()))
+	}
+}
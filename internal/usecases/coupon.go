@@ -0,0 +1,84 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simple-order-service/internal/domain"
+	"time"
+)
+
+var (
+	ErrCouponNotFound      = errors.New("coupon does not exist")
+	ErrCouponAlreadyExists = errors.New("coupon already exists")
+)
+
+type Coupon struct {
+	Code           string    `json:"code"`
+	DiscountType   string    `json:"discount_type"`
+	Value          float64   `json:"value"`
+	ValidFrom      time.Time `json:"valid_from"`
+	ValidUntil     time.Time `json:"valid_until"`
+	MaxRedemptions int       `json:"max_redemptions"`
+	Redemptions    int       `json:"redemptions"`
+	Stackable      bool      `json:"stackable"`
+}
+
+type CouponInteractor struct {
+	couponRepository domain.CouponRepository
+	unitOfWork       domain.UnitOfWork
+}
+
+func NewCouponInteractor(couponRepo domain.CouponRepository, unitOfWork domain.UnitOfWork) *CouponInteractor {
+	return &CouponInteractor{couponRepository: couponRepo, unitOfWork: unitOfWork}
+}
+
+func (interactor *CouponInteractor) Create(ctx context.Context, input Coupon) (Coupon, error) {
+	coupon := domain.NewCoupon(input.Code, domain.DiscountType(input.DiscountType), input.Value,
+		input.ValidFrom, input.ValidUntil, input.MaxRedemptions, input.Stackable)
+	if err := coupon.Validate(); err != nil {
+		return Coupon{}, err
+	}
+
+	err := interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		existing := repos.Coupons.FindByCode(ctx, coupon.Code())
+		if existing.Code() != "" {
+			return fmt.Errorf("%w: %s", ErrCouponAlreadyExists, coupon.Code())
+		}
+		return repos.Coupons.Store(ctx, coupon)
+	})
+	if err != nil {
+		return Coupon{}, err
+	}
+	return toCoupon(coupon), nil
+}
+
+func (interactor *CouponInteractor) GetDetails(ctx context.Context, code string) (Coupon, error) {
+	coupon := interactor.couponRepository.FindByCode(ctx, code)
+	if coupon.Code() == "" {
+		return Coupon{}, ErrCouponNotFound
+	}
+	return toCoupon(coupon), nil
+}
+
+func (interactor *CouponInteractor) GetAll(ctx context.Context) []Coupon {
+	couponsFromDb := interactor.couponRepository.GetAll(ctx)
+	coupons := make([]Coupon, len(couponsFromDb))
+	for idx, coupon := range couponsFromDb {
+		coupons[idx] = toCoupon(coupon)
+	}
+	return coupons
+}
+
+func toCoupon(coupon domain.Coupon) Coupon {
+	return Coupon{
+		Code:           coupon.Code(),
+		DiscountType:   string(coupon.DiscountType()),
+		Value:          coupon.Value(),
+		ValidFrom:      coupon.ValidFrom(),
+		ValidUntil:     coupon.ValidUntil(),
+		MaxRedemptions: coupon.MaxRedemptions(),
+		Redemptions:    coupon.Redemptions(),
+		Stackable:      coupon.IsStackable(),
+	}
+}
@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simple-order-service/internal/domain"
+	"time"
+)
+
+var (
+	ErrCustomerNotFound   = errors.New("customer does not exist")
+	ErrCustomerEmailTaken = errors.New("a customer with this email already exists")
+)
+
+type Customer struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Contact   string    `json:"contact,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CustomerInteractor struct {
+	customerRepository domain.CustomerRepository
+	unitOfWork         domain.UnitOfWork
+}
+
+func NewCustomerInteractor(customerRepo domain.CustomerRepository, unitOfWork domain.UnitOfWork) *CustomerInteractor {
+	return &CustomerInteractor{customerRepository: customerRepo, unitOfWork: unitOfWork}
+}
+
+// Create registers a customer under a generated id. The email of a customer must not be used by
+// another customer.
+func (interactor *CustomerInteractor) Create(ctx context.Context, input Customer) (Customer, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return Customer{}, err
+	}
+	customer := domain.NewCustomer(id, input.Name, input.Email, input.Contact, time.Now().UTC())
+	if err := customer.Validate(); err != nil {
+		return Customer{}, err
+	}
+
+	err = interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		existing := repos.Customers.FindByEmail(ctx, customer.Email())
+		if existing.ID() != "" {
+			return fmt.Errorf("%w: %s", ErrCustomerEmailTaken, customer.Email())
+		}
+		return repos.Customers.Store(ctx, customer)
+	})
+	if err != nil {
+		return Customer{}, err
+	}
+	return toCustomer(customer), nil
+}
+
+// GetDetails returns the customer. Customers may only read their own details.
+func (interactor *CustomerInteractor) GetDetails(ctx context.Context, id string) (Customer, error) {
+	if customerID := customerOf(ctx); customerID != "" && customerID != id {
+		return Customer{}, ErrForbidden
+	}
+	customer := interactor.customerRepository.FindById(ctx, id)
+	if customer.ID() == "" {
+		return Customer{}, ErrCustomerNotFound
+	}
+	return toCustomer(customer), nil
+}
+
+func (interactor *CustomerInteractor) GetAll(ctx context.Context) []Customer {
+	customersFromDb := interactor.customerRepository.GetAll(ctx)
+	customers := make([]Customer, len(customersFromDb))
+	for idx, customer := range customersFromDb {
+		customers[idx] = toCustomer(customer)
+	}
+	return customers
+}
+
+func toCustomer(customer domain.Customer) Customer {
+	return Customer{
+		ID:        customer.ID(),
+		Name:      customer.Name(),
+		Email:     customer.Email(),
+		Contact:   customer.Contact(),
+		CreatedAt: customer.CreatedAt(),
+	}
+}
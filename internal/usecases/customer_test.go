@@ -0,0 +1,76 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/metrics"
+	"testing"
+	"time"
+)
+
+func TestCreateCustomer_RejectsTakenEmail(t *testing.T) {
+	customers := make(map[string]domain.Customer)
+	customerRepoMock := &domain.CustomerRepositoryMock{
+		StoreFunc: func(ctx context.Context, customer domain.Customer) error {
+			customers[customer.ID()] = customer
+			return nil
+		},
+		FindByEmailFunc: func(ctx context.Context, email string) domain.Customer {
+			for _, customer := range customers {
+				if customer.Email() == email {
+					return customer
+				}
+			}
+			return domain.Customer{}
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Customers: customerRepoMock})
+	customerInteractor := usecases.NewCustomerInteractor(customerRepoMock, unitOfWorkMock)
+
+	created, err := customerInteractor.Create(context.Background(), usecases.Customer{Name: "Jane Doe", Email: "jane@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" {
+		t.Error("customer must be created with an id")
+	}
+	if _, err := customerInteractor.Create(context.Background(), usecases.Customer{Name: "Jane", Email: "JANE@example.com"}); !errors.Is(err, usecases.ErrCustomerEmailTaken) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrCustomerEmailTaken)
+	}
+	if len(customerRepoMock.StoreCalls()) != 1 {
+		t.Errorf("Got: %v, Want: %v", len(customerRepoMock.StoreCalls()), 1)
+	}
+}
+
+func TestCustomer_CanOnlyReadOwnAccountAndOrders(t *testing.T) {
+	customerRepoMock := &domain.CustomerRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Customer {
+			return domain.NewCustomer(id, "Jane Doe", id+"@example.com", "", time.Now())
+		},
+	}
+	customerInteractor := usecases.NewCustomerInteractor(customerRepoMock, newUnitOfWorkMock(domain.Repositories{}))
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindFunc: func(ctx context.Context, query domain.OrderQuery) ([]domain.Order, string, error) {
+			return []domain.Order{domain.NewCustomerOrder("1", query.CustomerID)}, "", nil
+		},
+	}
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, newUnitOfWorkMock(domain.Repositories{}), domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+
+	ctx := usecases.WithPrincipal(context.Background(), domain.Principal{Subject: "c-1", Role: domain.RoleCustomer})
+	if _, err := customerInteractor.GetDetails(ctx, "c-1"); err != nil {
+		t.Errorf("Got: %v, Want: %v", err, nil)
+	}
+	if _, err := customerInteractor.GetDetails(ctx, "c-2"); !errors.Is(err, usecases.ErrForbidden) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrForbidden)
+	}
+	query := domain.OrderQuery{Page: domain.Page{Limit: domain.DefaultPageLimit}}
+	if _, _, err := orderInteractor.List(ctx, query); !errors.Is(err, usecases.ErrForbidden) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrForbidden)
+	}
+	query.CustomerID = "c-1"
+	if orders, _, err := orderInteractor.List(ctx, query); err != nil || len(orders) != 1 || orders[0].CustomerID != "c-1" {
+		t.Errorf("Got: %v, %v, Want: the order of c-1, nil", orders, err)
+	}
+}
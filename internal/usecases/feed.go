@@ -0,0 +1,50 @@
+package usecases
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/logging"
+	"time"
+)
+
+// FeedTrimmer removes the events of the feed which are older than retention, so that the feed does
+// not grow without bound. Clients reconnecting with the id of a removed event resume from the oldest
+// event which is kept.
+type FeedTrimmer struct {
+	feedRepository domain.FeedRepository
+	retention      time.Duration
+	interval       time.Duration
+}
+
+func NewFeedTrimmer(feedRepository domain.FeedRepository, retention, interval time.Duration) *FeedTrimmer {
+	return &FeedTrimmer{feedRepository: feedRepository, retention: retention, interval: interval}
+}
+
+// Trim removes the events which are older than the retention at the given time, and returns how many
+// were removed.
+func (trimmer *FeedTrimmer) Trim(ctx context.Context, at time.Time) (int, error) {
+	return trimmer.feedRepository.DeleteBefore(ctx, at.Add(-trimmer.retention))
+}
+
+// Run trims the feed every interval until the context is done.
+func (trimmer *FeedTrimmer) Run(ctx context.Context) {
+	logger := logging.Default().With("worker", "feed_trimmer")
+	ctx = logging.NewContext(ctx, logger)
+	ticker := time.NewTicker(trimmer.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case at := <-ticker.C:
+			deleted, err := trimmer.Trim(ctx, at.UTC())
+			if err != nil {
+				logger.Error("could not trim the feed", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				logger.Info("trimmed the feed", "deleted", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package usecases_test
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+	"testing"
+	"time"
+)
+
+func TestTrim_DeletesTheEventsOlderThanTheRetention(t *testing.T) {
+	now := time.Now()
+	feedRepoMock := &domain.FeedRepositoryMock{
+		DeleteBeforeFunc: func(ctx context.Context, before time.Time) (int, error) {
+			return 3, nil
+		},
+	}
+
+	trimmer := usecases.NewFeedTrimmer(feedRepoMock, 24*time.Hour, time.Hour)
+	deleted, err := trimmer.Trim(context.Background(), now)
+	if err != nil || deleted != 3 {
+		t.Fatalf("Got: %v, %v, Want: 3, %v", deleted, err, nil)
+	}
+	calls := feedRepoMock.DeleteBeforeCalls()
+	if len(calls) != 1 || !calls[0].Before.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("Got: %v, Want: events before %v deleted", calls, now.Add(-24*time.Hour))
+	}
+}
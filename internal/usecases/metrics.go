@@ -0,0 +1,75 @@
+package usecases
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/metrics"
+	"time"
+)
+
+// Metrics counts the changes made to orders by the interactors.
+type Metrics struct {
+	orderStatusChanges *metrics.CounterVec
+	discountsApplied   *metrics.CounterVec
+	discountAmount     *metrics.CounterVec
+}
+
+// NewMetrics registers the counters of the interactors in the registry, which they may only be
+// registered in once.
+func NewMetrics(registry *metrics.Registry) *Metrics {
+	return &Metrics{
+		orderStatusChanges: registry.NewCounterVec("shop_order_status_changes_total",
+			"Orders moved to a status, by status.", "status"),
+		discountsApplied: registry.NewCounterVec("shop_discounts_applied_total",
+			"Discounts applied to placed orders, by source (pricing_rule or coupon).", "source"),
+		discountAmount: registry.NewCounterVec("shop_discount_amount_total",
+			"Amount taken off placed orders by discounts, by source (pricing_rule or coupon).", "source"),
+	}
+}
+
+// RegisterBusinessGauges registers the gauges computed from the stored orders and products in the
+// registry, which they may only be registered in once. Computing them reads every order and product,
+// so they are computed again at most once every ttl, however often the metrics are scraped.
+func RegisterBusinessGauges(registry *metrics.Registry, orderRepo domain.OrderRepository, productRepo domain.ProductRepository, ttl time.Duration) {
+	registry.NewGaugeFunc("shop_orders", "Orders by status.", []string{"status"}, metrics.Cached(ttl, func() []metrics.Sample {
+		counts := make(map[domain.OrderStatus]int)
+		for _, order := range orderRepo.GetAll(context.Background()) {
+			counts[order.GetOrderStatus()] += 1
+		}
+		samples := make([]metrics.Sample, 0, len(domain.OrderStatuses()))
+		for _, status := range domain.OrderStatuses() {
+			samples = append(samples, metrics.Sample{LabelValues: []string{string(status)}, Value: float64(counts[status])})
+		}
+		return samples
+	}))
+	registry.NewGaugeFunc("shop_products_out_of_stock", "Products of the catalogue with no stock available.", nil, metrics.Cached(ttl, func() []metrics.Sample {
+		outOfStock := 0
+		for _, product := range productRepo.GetAll(context.Background()) {
+			if !product.IsAvailable() {
+				outOfStock += 1
+			}
+		}
+		return []metrics.Sample{{Value: float64(outOfStock)}}
+	}))
+}
+
+// recordDiscounts counts the discounts of the price of a placed order. When only is given, only the
+// discounts of the coupons it selects are counted.
+func (m *Metrics) recordDiscounts(order domain.Order, breakdown domain.PriceBreakdown, only func(applied domain.AppliedCoupon) bool) {
+	coupons := make(map[string]domain.AppliedCoupon)
+	for _, applied := range order.AppliedCoupons() {
+		coupons[applied.Name()] = applied
+	}
+	for _, adjustment := range breakdown.Adjustments {
+		applied, isCoupon := coupons[adjustment.Rule]
+		if only != nil && (!isCoupon || !only(applied)) {
+			continue
+		}
+		source := "pricing_rule"
+		if isCoupon {
+			source = "coupon"
+		}
+		m.discountsApplied.Inc(source)
+		m.discountAmount.Add(-adjustment.Amount, source)
+	}
+}
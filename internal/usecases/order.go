@@ -1,14 +1,29 @@
 package usecases
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/logging"
+	"simple-order-service/pkg/ulid"
+	"time"
+)
+
+var (
+	ErrOrderNotFound = errors.New("order does not exist")
+	ErrEmptyOrder    = errors.New("cannot place an order without products")
+	ErrInvalidQuery  = errors.New("invalid query")
 )
 
 type OrderInteractor struct {
 	orderRepository   domain.OrderRepository
 	productRepository domain.ProductRepository
+	unitOfWork        domain.UnitOfWork
+	pricingEngine     domain.PricingEngine
+	reservationTTL    time.Duration
+	limits            domain.Limits
+	metrics           *Metrics
 }
 
 type Order struct {
@@ -18,183 +33,589 @@ type Order struct {
 	DispatchDate  string    `json:"dispatch_date,omitempty"`
 	Status        string    `json:"status,omitempty"`
 	Value         float64   `json:"value,omitempty"`
+	Pricing       Pricing   `json:"pricing"`
+	Coupons       []string  `json:"coupons,omitempty"`
+	CustomerID    string    `json:"customer_id,omitempty"`
+	Version       int       `json:"version"`
 }
 
-func NewOrderInteractor(orderRepo domain.OrderRepository, productRepo domain.ProductRepository) *OrderInteractor {
-	return &OrderInteractor{orderRepository: orderRepo, productRepository: productRepo}
+type Pricing struct {
+	Subtotal    float64           `json:"subtotal"`
+	Adjustments []PriceAdjustment `json:"adjustments"`
+	Total       float64           `json:"total"`
 }
 
-// TODO: Refactor after writing the deduplication of products in the domain.Orders.Add()
-func (interactor *OrderInteractor) Products(orderId string) ([]Product, error) {
-	order := interactor.orderRepository.FindById(orderId)
-	orderedProducts := order.Products()
-	if order.ProductQuantity() == 0 {
-		return nil, errors.New("order does not exist. no products found in the order")
-	}
+type PriceAdjustment struct {
+	Rule   string  `json:"rule"`
+	Amount float64 `json:"amount"`
+}
 
-	products := make([]Product, 0)
-	seenProducts := make(map[string]bool)
-	productToCount := make(map[Product]int)
+type StatusTransition struct {
+	From  string    `json:"from,omitempty"`
+	To    string    `json:"to"`
+	At    time.Time `json:"at"`
+	Actor string    `json:"actor"`
+}
 
-	for _, product := range orderedProducts {
-		prod := Product{ID: product.ID(), Name: product.Name(), Category: string(product.Category()), Price: product.Price()}
-		if seenProducts[product.ID()] {
-			productToCount[prod] += 1
-			continue
+// NewOrderInteractor builds an OrderInteractor which reserves the products added to open orders for
+// reservationTTL, and holds orders and pages of orders within the limits.
+func NewOrderInteractor(orderRepo domain.OrderRepository, productRepo domain.ProductRepository, unitOfWork domain.UnitOfWork, pricingEngine domain.PricingEngine, reservationTTL time.Duration, limits domain.Limits, metrics *Metrics) *OrderInteractor {
+	return &OrderInteractor{orderRepository: orderRepo, productRepository: productRepo, unitOfWork: unitOfWork, pricingEngine: pricingEngine, reservationTTL: reservationTTL, limits: limits, metrics: metrics}
+}
+
+func (interactor *OrderInteractor) Products(ctx context.Context, orderId string) ([]Product, error) {
+	order := interactor.orderRepository.FindById(ctx, orderId)
+	if order.ID() == "" {
+		return nil, ErrOrderNotFound
+	}
+	if err := canView(ctx, order); err != nil {
+		return nil, err
+	}
+	return orderedProducts(order.Lines()), nil
+}
+
+// Create creates an empty open order under a generated id, which sorts after the ids of the orders
+// created before it. Customers create orders for themselves; staff may create an order for a
+// customer, or one without a customer.
+func (interactor *OrderInteractor) Create(ctx context.Context, customerID string) (Order, error) {
+	if own := customerOf(ctx); own != "" {
+		if customerID != "" && customerID != own {
+			return Order{}, ErrForbidden
 		}
-		seenProducts[product.ID()] = true
-		productToCount[prod] += 1
+		customerID = own
 	}
+	id, err := ulid.New()
+	if err != nil {
+		return Order{}, err
+	}
+	ctx = logging.With(ctx, "order_id", id)
 
-	for product, count := range productToCount {
-		if count > 0 {
-			product.Quantity = count
-			products = append(products, product)
+	order := domain.NewCustomerOrder(id, customerID)
+	err = interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		if customerID != "" {
+			if customer := repos.Customers.FindById(ctx, customerID); customer.ID() == "" {
+				return fmt.Errorf("%w: %s", ErrCustomerNotFound, customerID)
+			}
 		}
+		return repos.Orders.Store(ctx, order)
+	})
+	if err != nil {
+		return Order{}, err
 	}
-	return products, nil
+	// The repository stored the order as its first version.
+	order.IncrementVersion()
+	logging.FromContext(ctx).Info("order created", "customer_id", customerID)
+	return interactor.toOrder(order), nil
 }
 
-func (interactor *OrderInteractor) Add(orderId, productId string) error {
-	product := interactor.productRepository.FindById(productId)
-	order := interactor.orderRepository.FindById(orderId)
-	if order.ID() == "" {
-		order = domain.NewOrder(orderId)
+// Add adds the given quantity of the product to the open order. The added units are reserved for the
+// order until the reservation expires or the order is placed, and the order, the product and the
+// reservation are written in the same transaction.
+//
+// The order is only changed if its version is expectedVersion, unless expectedVersion is
+// domain.AnyVersion. The same goes for the other updates of an order.
+func (interactor *OrderInteractor) Add(ctx context.Context, orderId, productId string, quantity int, expectedVersion int) error {
+	ctx = logging.With(ctx, "order_id", orderId, "product_id", productId)
+	return interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		product := repos.Products.FindById(ctx, productId)
+		order := repos.Orders.FindById(ctx, orderId)
+		if order.ID() == "" {
+			return ErrOrderNotFound
+		}
+		if err := canChange(ctx, order); err != nil {
+			return err
+		}
+		if err := domain.CheckVersion(expectedVersion, order.Version()); err != nil {
+			return err
+		}
+
+		orderStatus := order.GetOrderStatus()
+		if orderStatus != domain.OrderOpen {
+			return fmt.Errorf("order has already been %s", orderStatus)
+		}
+		if product.ID() == "" {
+			return fmt.Errorf("%w: %s", ErrProductNotFound, productId)
+		}
+
+		if domainErr := order.AddQuantity(product, quantity, interactor.limits); domainErr != nil {
+			message := "Could not add item #%s "
+			message += "to order #%s "
+			message += "because a business rule was violated: '%s'"
+			err := fmt.Errorf(message,
+				product.ID(),
+				order.ID(),
+				domainErr.Error())
+			return err
+		}
+
+		if err := product.Reserve(quantity); err != nil {
+			return err
+		}
+		expiresAt := time.Now().UTC().Add(interactor.reservationTTL)
+		reservation := repos.Reservations.Find(ctx, order.ID(), product.ID())
+		if reservation.OrderID() == "" {
+			reservation = domain.NewReservation(order.ID(), product.ID(), quantity, expiresAt)
+		} else {
+			reservation.Extend(quantity, expiresAt)
+		}
+
+		if err := repos.Products.Store(ctx, product); err != nil {
+			return err
+		}
+		if err := repos.Reservations.Store(ctx, reservation); err != nil {
+			return err
+		}
+		return repos.Orders.Store(ctx, order)
+	})
+}
+
+// UpdateOrderStatus moves the order to the status, and returns the version the order is in once it
+// has been moved.
+func (interactor *OrderInteractor) UpdateOrderStatus(ctx context.Context, orderId string, status domain.OrderStatus, actor string, expectedVersion int) (int, error) {
+	ctx = logging.With(ctx, "order_id", orderId)
+	if !status.IsValid() {
+		return 0, domain.ErrInvalidOrderStatus
 	}
 
-	orderStatus := order.GetOrderStatus()
-	if orderStatus == domain.OrderCompleted || orderStatus == domain.OrderCancelled || orderStatus == domain.OrderDispatched {
-		return fmt.Errorf("order has already been %s", orderStatus)
+	var version int
+	var changed *domain.Order
+	err := interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		order := repos.Orders.FindById(ctx, orderId)
+		if order.ID() == "" {
+			return errors.New("cannot update order status for a non-existent order")
+		}
+		if err := canChangeStatus(ctx, order, status); err != nil {
+			return err
+		}
+		if err := domain.CheckVersion(expectedVersion, order.Version()); err != nil {
+			return err
+		}
+
+		// A retried request finds the order already in the requested status, and must not apply the
+		// side effects of the transition, such as releasing stock, a second time.
+		version = order.Version()
+		previousStatus := order.GetOrderStatus()
+		if previousStatus == status {
+			return nil
+		}
+
+		if domainErr := order.SetOrderStatus(status, actor); domainErr != nil {
+			message := "Could not update status of order #%s "
+			message += "because a business rule was violated: '%s'"
+			err := fmt.Errorf(message,
+				order.ID(),
+				domainErr.Error())
+			return err
+		}
+
+		switch {
+		case status == domain.OrderPlaced:
+			if err := commitReservations(ctx, repos, order); err != nil {
+				return fmt.Errorf("could not place order #%s: %w", order.ID(), err)
+			}
+			if err := redeemCoupons(ctx, repos, order); err != nil {
+				return err
+			}
+		case status == domain.OrderCancelled && previousStatus == domain.OrderOpen:
+			if err := releaseReservations(ctx, repos, order.ID()); err != nil {
+				return err
+			}
+		case status == domain.OrderCancelled && previousStatus == domain.OrderPlaced:
+			if err := releaseStock(ctx, repos, &order); err != nil {
+				return err
+			}
+			if err := releaseCoupons(ctx, repos, order); err != nil {
+				return err
+			}
+		}
+		changed = &order
+		return repos.Orders.Store(ctx, order)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if changed == nil {
+		return version, nil
 	}
+	changed.IncrementVersion()
 
-	if domainErr := order.Add(product); domainErr != nil {
-		message := "Could not add item #%s "
-		message += "to order #%s "
-		message += "because a business rule was violated: '%s'"
-		err := fmt.Errorf(message,
-			product.ID(),
-			order.ID(),
-			domainErr.Error())
-		return err
+	// The metrics are only recorded once the change has been committed.
+	logging.FromContext(ctx).Info("order status changed", "status", string(status), "actor", actor)
+	interactor.metrics.orderStatusChanges.Inc(string(status))
+	if status == domain.OrderPlaced {
+		interactor.metrics.recordDiscounts(*changed, changed.Price(interactor.pricingEngine), nil)
 	}
-	interactor.orderRepository.Store(order)
-	interactor.UpdateOrderStatus(orderId, domain.OrderPlaced)
-	return nil
+	return changed.Version(), nil
 }
 
-// TODO: Always check previous status; status can only move forwards, i.e., placed -> dispatched or cancelled -> completed
-func (interactor *OrderInteractor) UpdateOrderStatus(orderId string, status domain.OrderStatus) error {
-	orderStatusMap := map[domain.OrderStatus]bool{
-		domain.OrderDispatched: true,
-		domain.OrderPlaced:     true,
-		domain.OrderCompleted:  true,
-		domain.OrderCancelled:  true,
-	}
-	_, ok := orderStatusMap[status]
-	if !ok {
-		return errors.New(`invalid order status. the different order status values are: 
-							'placed', 'dispatched', 'cancelled' and 'completed'`)
+// commitReservations takes the stock of the placed order out of the catalogue. The units still
+// reserved for the order are committed, and the units whose reservation has expired are taken from
+// the stock which is still available, failing if there is not enough of it.
+func commitReservations(ctx context.Context, repos domain.Repositories, order domain.Order) error {
+	if order.ProductQuantity() == 0 {
+		return ErrEmptyOrder
 	}
+	for _, line := range order.Lines() {
+		product := repos.Products.FindById(ctx, line.ProductID())
+		if product.ID() == "" {
+			return &domain.OrderError{Err: domain.ErrUnavailableProduct(line.Name())}
+		}
+		reservation := repos.Reservations.Find(ctx, order.ID(), line.ProductID())
+		reserved := reservation.Quantity()
+		if reserved > line.Quantity() {
+			reserved = line.Quantity()
+		}
+		shortfall := line.Quantity() - reserved
+		if shortfall > product.Available() {
+			return &domain.OrderError{Err: domain.ErrInsufficientStock(product.Name(), product.Available())}
+		}
 
-	order := interactor.orderRepository.FindById(orderId)
-	orderStatus := order.GetOrderStatus()
-	if orderStatus == domain.OrderCancelled || orderStatus == domain.OrderDispatched {
-		return fmt.Errorf("cannot update order status as order has been %s", orderStatus)
+		product.CommitReservation(reserved)
+		product.DecreaseStockBy(shortfall)
+		if err := repos.Products.Store(ctx, product); err != nil {
+			return err
+		}
+		if reservation.OrderID() != "" {
+			if err := repos.Reservations.Delete(ctx, order.ID(), line.ProductID()); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
 
-	if order.ID() == "" {
-		return errors.New("cannot update order status for a non-existent order")
+// releaseReservations gives the units reserved for the order back to the catalogue.
+func releaseReservations(ctx context.Context, repos domain.Repositories, orderId string) error {
+	for _, reservation := range repos.Reservations.FindByOrder(ctx, orderId) {
+		if err := releaseReservation(ctx, repos, reservation); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if status == domain.OrderPlaced {
-		for productId := range order.ProductToCount() {
-			product := interactor.productRepository.FindById(productId)
-			product.DecreaseStockBy(1)
-			interactor.productRepository.Store(product)
+func releaseReservation(ctx context.Context, repos domain.Repositories, reservation domain.Reservation) error {
+	product := repos.Products.FindById(ctx, reservation.ProductID())
+	if product.ID() != "" {
+		product.ReleaseReservation(reservation.Quantity())
+		if err := repos.Products.Store(ctx, product); err != nil {
+			return err
 		}
 	}
+	return repos.Reservations.Delete(ctx, reservation.OrderID(), reservation.ProductID())
+}
 
-	order.SetOrderStatus(status)
-	interactor.orderRepository.Store(order)
+// releaseStock gives the quantities consumed by the order back to the catalogue. Products which have
+// since been removed from the catalogue are skipped.
+func releaseStock(ctx context.Context, repos domain.Repositories, order *domain.Order) error {
+	for _, line := range order.ReleaseStock() {
+		product := repos.Products.FindById(ctx, line.ProductID())
+		if product.ID() == "" {
+			continue
+		}
+		product.IncreaseStockBy(line.Quantity())
+		if err := repos.Products.Store(ctx, product); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (interactor *OrderInteractor) UpdateDispatchDate(orderId, date string) error {
-	var message string
-	order := interactor.orderRepository.FindById(orderId)
-	if order.ID() == "" {
-		return errors.New("cannot update dispatch date for a non-existent order")
+func (interactor *OrderInteractor) UpdateDispatchDate(ctx context.Context, orderId, date string, expectedVersion int) error {
+	ctx = logging.With(ctx, "order_id", orderId)
+	return interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		var message string
+		order := repos.Orders.FindById(ctx, orderId)
+		if order.ID() == "" {
+			return errors.New("cannot update dispatch date for a non-existent order")
+		}
+		if err := canChangeDispatchDate(ctx, order); err != nil {
+			return err
+		}
+		if err := domain.CheckVersion(expectedVersion, order.Version()); err != nil {
+			return err
+		}
+
+		orderStatus := order.GetOrderStatus()
+		if orderStatus == domain.OrderCompleted || orderStatus == domain.OrderCancelled {
+			return fmt.Errorf("cannot update dispatch date as order has been %s", orderStatus)
+		}
+
+		if domainErr := order.SetDispatchDate(date); domainErr != nil {
+			message = "Could not update dispatch date: #%s "
+			message += "of order #%s "
+			message += "because a business rule was violated: '%s'"
+			err := fmt.Errorf(message,
+				date,
+				order.ID(),
+				domainErr.Error())
+			return err
+		}
+		return repos.Orders.Store(ctx, order)
+	})
+}
+
+// ApplyCoupon applies the coupon to the order. The redemption of the coupon is counted in the same
+// transaction if the order has already been placed, and when the order is placed otherwise.
+func (interactor *OrderInteractor) ApplyCoupon(ctx context.Context, orderId, code string, expectedVersion int) error {
+	ctx = logging.With(ctx, "order_id", orderId, "coupon", code)
+	var placed *domain.Order
+	err := interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		order := repos.Orders.FindById(ctx, orderId)
+		if order.ID() == "" {
+			return ErrOrderNotFound
+		}
+		if err := canChange(ctx, order); err != nil {
+			return err
+		}
+		if err := domain.CheckVersion(expectedVersion, order.Version()); err != nil {
+			return err
+		}
+		coupon := repos.Coupons.FindByCode(ctx, code)
+		if coupon.Code() == "" {
+			return ErrCouponNotFound
+		}
+
+		orderStatus := order.GetOrderStatus()
+		if orderStatus != domain.OrderOpen && orderStatus != domain.OrderPlaced {
+			return fmt.Errorf("cannot apply coupon as order has been %s", orderStatus)
+		}
+
+		if domainErr := order.ApplyCoupon(coupon, time.Now()); domainErr != nil {
+			message := "Could not apply coupon %s "
+			message += "to order #%s "
+			message += "because a business rule was violated: '%s'"
+			return fmt.Errorf(message, coupon.Code(), order.ID(), domainErr.Error())
+		}
+
+		if orderStatus == domain.OrderPlaced {
+			if err := coupon.Redeem(); err != nil {
+				return err
+			}
+			if err := repos.Coupons.Store(ctx, coupon); err != nil {
+				return err
+			}
+			placed = &order
+		}
+		return repos.Orders.Store(ctx, order)
+	})
+	if err != nil || placed == nil {
+		return err
 	}
 
-	orderStatus := order.GetOrderStatus()
-	if orderStatus == domain.OrderCompleted || orderStatus == domain.OrderCancelled {
-		return fmt.Errorf("cannot update dispatch date as order has been %s", orderStatus)
+	// The discounts of an open order are recorded when it is placed.
+	code = domain.NormalizeCouponCode(code)
+	interactor.metrics.recordDiscounts(*placed, placed.Price(interactor.pricingEngine), func(applied domain.AppliedCoupon) bool {
+		return applied.Code == code
+	})
+	return nil
+}
+
+// RemoveCoupon removes the coupon from the order, giving back its redemption if it was counted.
+func (interactor *OrderInteractor) RemoveCoupon(ctx context.Context, orderId, code string, expectedVersion int) error {
+	ctx = logging.With(ctx, "order_id", orderId, "coupon", code)
+	return interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		order := repos.Orders.FindById(ctx, orderId)
+		if order.ID() == "" {
+			return ErrOrderNotFound
+		}
+		if err := canChange(ctx, order); err != nil {
+			return err
+		}
+		if err := domain.CheckVersion(expectedVersion, order.Version()); err != nil {
+			return err
+		}
+
+		orderStatus := order.GetOrderStatus()
+		if orderStatus != domain.OrderOpen && orderStatus != domain.OrderPlaced {
+			return fmt.Errorf("cannot remove coupon as order has been %s", orderStatus)
+		}
+
+		if domainErr := order.RemoveCoupon(code); domainErr != nil {
+			return domainErr
+		}
+
+		if orderStatus == domain.OrderPlaced {
+			if err := releaseCoupon(ctx, repos, code); err != nil {
+				return err
+			}
+		}
+		return repos.Orders.Store(ctx, order)
+	})
+}
+
+// redeemCoupons counts a redemption of every coupon applied to the order.
+func redeemCoupons(ctx context.Context, repos domain.Repositories, order domain.Order) error {
+	for _, applied := range order.AppliedCoupons() {
+		coupon := repos.Coupons.FindByCode(ctx, applied.Code)
+		if coupon.Code() == "" {
+			return fmt.Errorf("%w: %s", ErrCouponNotFound, applied.Code)
+		}
+		if err := coupon.Redeem(); err != nil {
+			return err
+		}
+		if err := repos.Coupons.Store(ctx, coupon); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if domainErr := order.SetDispatchDate(date); domainErr != nil {
-		message = "Could not update dispatch date: #%s "
-		message += "of order #%s "
-		message += "because a business rule was violated: '%s'"
-		err := fmt.Errorf(message,
-			date,
-			order.ID(),
-			domainErr.Error())
-		return err
+// releaseCoupons gives back the redemption counted for every coupon applied to the order when it was
+// placed.
+func releaseCoupons(ctx context.Context, repos domain.Repositories, order domain.Order) error {
+	for _, applied := range order.AppliedCoupons() {
+		if err := releaseCoupon(ctx, repos, applied.Code); err != nil {
+			return err
+		}
 	}
-	interactor.orderRepository.Store(order)
 	return nil
 }
 
-func (interactor *OrderInteractor) GetDetails(orderId string) (Order, error) {
-	domainOrder := interactor.orderRepository.FindById(orderId)
+// releaseCoupon gives back a redemption of the coupon, unless the coupon no longer exists.
+func releaseCoupon(ctx context.Context, repos domain.Repositories, code string) error {
+	coupon := repos.Coupons.FindByCode(ctx, code)
+	if coupon.Code() == "" {
+		return nil
+	}
+	coupon.ReleaseRedemption()
+	return repos.Coupons.Store(ctx, coupon)
+}
+
+func (interactor *OrderInteractor) History(ctx context.Context, orderId string) ([]StatusTransition, error) {
+	order := interactor.orderRepository.FindById(ctx, orderId)
+	if order.ID() == "" {
+		return nil, ErrOrderNotFound
+	}
+	if err := canView(ctx, order); err != nil {
+		return nil, err
+	}
+	history := make([]StatusTransition, len(order.History()))
+	for idx, transition := range order.History() {
+		history[idx] = StatusTransition{
+			From:  string(transition.From),
+			To:    string(transition.To),
+			At:    transition.At,
+			Actor: transition.Actor,
+		}
+	}
+	return history, nil
+}
+
+func (interactor *OrderInteractor) GetDetails(ctx context.Context, orderId string) (Order, error) {
+	domainOrder := interactor.orderRepository.FindById(ctx, orderId)
 	if domainOrder.ID() == "" {
-		return Order{}, errors.New("order does not exist")
+		return Order{}, ErrOrderNotFound
+	}
+	if err := canView(ctx, domainOrder); err != nil {
+		return Order{}, err
+	}
+	return interactor.toOrder(domainOrder), nil
+}
+
+// GetDetailsAsOf returns the order as it was at the given time, by replaying the events which had
+// happened to it by then.
+func (interactor *OrderInteractor) GetDetailsAsOf(ctx context.Context, orderId string, at time.Time) (Order, error) {
+	events, err := interactor.orderRepository.Events(ctx, orderId)
+	if err != nil {
+		return Order{}, err
+	}
+	events = domain.EventsUntil(events, at)
+	if len(events) == 0 {
+		return Order{}, ErrOrderNotFound
+	}
+	order, err := domain.ReplayOrder(events)
+	if err != nil {
+		return Order{}, err
 	}
-	order := Order{
-		ID:            domainOrder.ID(),
-		TotalQuantity: domainOrder.ProductQuantity(),
-		DispatchDate:  domainOrder.GetDispatchDate(),
-		Status:        string(domainOrder.GetOrderStatus()),
-		Value:         domainOrder.Value(),
-		Products:      getDeduplicatedProductsWithCount(domainOrder.Products()),
+	if err := canView(ctx, order); err != nil {
+		return Order{}, err
 	}
-	return order, nil
+	return interactor.toOrder(order), nil
 }
 
-func (interactor *OrderInteractor) GetAll() []Order {
-	ordersFromDb := interactor.orderRepository.GetAll()
+func (interactor *OrderInteractor) GetAll(ctx context.Context) []Order {
+	ordersFromDb := interactor.orderRepository.GetAll(ctx)
 	if len(ordersFromDb) == 0 {
 		return []Order{}
 	}
 	orders := make([]Order, len(ordersFromDb))
 	for idx, order := range ordersFromDb {
-		orders[idx] = Order{
-			ID:            order.ID(),
-			TotalQuantity: order.ProductQuantity(),
-			DispatchDate:  order.GetDispatchDate(),
-			Status:        string(order.GetOrderStatus()),
-			Value:         order.Value(),
-			Products:      getDeduplicatedProductsWithCount(order.Products()),
-		}
+		orders[idx] = interactor.toOrder(order)
 	}
 	return orders
 }
 
-func getDeduplicatedProductsWithCount(products []domain.Product) []Product {
-	productToCount := make(map[domain.Product]int)
-	for _, product := range products {
-		productToCount[product] += 1
+// List returns a page of the orders matching the query, along with the cursor of the next page. The
+// cursor is empty when there are no more orders. A query without a limit gets the default one.
+// Customers may only list their own orders.
+func (interactor *OrderInteractor) List(ctx context.Context, query domain.OrderQuery) ([]Order, string, error) {
+	if customerID := customerOf(ctx); customerID != "" && query.CustomerID != customerID {
+		return nil, "", ErrForbidden
+	}
+	if query.Limit == 0 {
+		query.Limit = interactor.limits.DefaultPageLimit
+	}
+	if err := query.Validate(interactor.limits); err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, err)
 	}
-	deduplicatedProducts := make([]Product, 0)
-	for product, count := range productToCount {
-		p := Product{
-			ID:       product.ID(),
-			Name:     product.Name(),
-			Category: string(product.Category()),
-			Price:    product.Price(),
-			Quantity: count,
+	if query.Status != "" && !query.Status.IsValid() {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidQuery, domain.ErrInvalidOrderStatus)
+	}
+
+	ordersFromDb, nextCursor, err := interactor.orderRepository.Find(ctx, query)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidQuery, err)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	orders := make([]Order, len(ordersFromDb))
+	for idx, order := range ordersFromDb {
+		orders[idx] = interactor.toOrder(order)
+	}
+	return orders, nextCursor, nil
+}
+
+func (interactor *OrderInteractor) toOrder(order domain.Order) Order {
+	breakdown := order.Price(interactor.pricingEngine)
+	adjustments := make([]PriceAdjustment, len(breakdown.Adjustments))
+	for idx, adjustment := range breakdown.Adjustments {
+		adjustments[idx] = PriceAdjustment{Rule: adjustment.Rule, Amount: adjustment.Amount}
+	}
+	coupons := make([]string, len(order.AppliedCoupons()))
+	for idx, coupon := range order.AppliedCoupons() {
+		coupons[idx] = coupon.Code
+	}
+	return Order{
+		ID:            order.ID(),
+		TotalQuantity: order.ProductQuantity(),
+		DispatchDate:  order.GetDispatchDate(),
+		Status:        string(order.GetOrderStatus()),
+		Value:         breakdown.Total,
+		Products:      orderedProducts(order.Lines()),
+		Pricing: Pricing{
+			Subtotal:    breakdown.Subtotal,
+			Adjustments: adjustments,
+			Total:       breakdown.Total,
+		},
+		Coupons:    coupons,
+		CustomerID: order.CustomerID(),
+		Version:    order.Version(),
+	}
+}
+
+func orderedProducts(lines []domain.OrderLine) []Product {
+	products := make([]Product, len(lines))
+	for idx, line := range lines {
+		products[idx] = Product{
+			ID:        line.ProductID(),
+			Name:      line.Name(),
+			Category:  string(line.Category()),
+			Price:     line.Price(),
+			Quantity:  line.Quantity(),
+			LineTotal: line.Total(),
 		}
-		deduplicatedProducts = append(deduplicatedProducts, p)
 	}
-	return deduplicatedProducts
+	return products
 }
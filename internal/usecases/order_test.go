@@ -1,14 +1,19 @@
 package usecases_test
 
 import (
+	"context"
+	"errors"
 	"simple-order-service/internal/domain"
 	"simple-order-service/internal/usecases"
+	"simple-order-service/pkg/metrics"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestListProductsInOrder(t *testing.T) {
 	orderRepoMock := &domain.OrderRepositoryMock{
-		GetAllFunc: func() []domain.Order {
+		GetAllFunc: func(ctx context.Context) []domain.Order {
 			order := domain.NewOrder("1")
 			product := domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)
 			order.Add(product)
@@ -16,9 +21,10 @@ func TestListProductsInOrder(t *testing.T) {
 		},
 	}
 	productRepoMock := &domain.ProductRepositoryMock{}
+	unitOfWorkMock := &domain.UnitOfWorkMock{}
 
-	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock)
-	got := orderInteractor.GetAll()
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	got := orderInteractor.GetAll(context.Background())
 	if len(got) != 1 {
 		t.Error("number of orders must be equal to 1")
 	}
@@ -26,3 +32,517 @@ func TestListProductsInOrder(t *testing.T) {
 		t.Error("id of order should be 1")
 	}
 }
+
+func TestUpdateOrderStatus_CompletedOrderCannotBePlacedAgain(t *testing.T) {
+	order := domain.NewOrder("1")
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+	order.SetOrderStatus(domain.OrderDispatched, "tester")
+	order.SetOrderStatus(domain.OrderCompleted, "tester")
+
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return order
+		},
+		StoreFunc: func(ctx context.Context, order domain.Order) error {
+			return nil
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock})
+
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	if _, err := orderInteractor.UpdateOrderStatus(context.Background(), "1", domain.OrderPlaced, "tester", domain.AnyVersion); err == nil {
+		t.Error("a completed order must not be moved back to placed")
+	}
+	if len(orderRepoMock.StoreCalls()) != 0 {
+		t.Error("order must not be stored when the transition is rejected")
+	}
+	if len(productRepoMock.FindByIdCalls()) != 0 {
+		t.Error("stock must not be touched when the transition is rejected")
+	}
+}
+
+func TestAddProductToOrder_ReservesStockInOneUnitOfWork(t *testing.T) {
+	product := domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return domain.NewOrder(id)
+		},
+		StoreFunc: func(ctx context.Context, order domain.Order) error {
+			return nil
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return product
+		},
+		StoreFunc: func(ctx context.Context, product domain.Product) error {
+			return nil
+		},
+	}
+	reservationRepoMock := &domain.ReservationRepositoryMock{
+		FindFunc: func(ctx context.Context, orderID, productID string) domain.Reservation {
+			return domain.Reservation{}
+		},
+		StoreFunc: func(ctx context.Context, reservation domain.Reservation) error {
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock, Reservations: reservationRepoMock})
+
+	orderInteractor := usecases.NewOrderInteractor(&domain.OrderRepositoryMock{}, &domain.ProductRepositoryMock{}, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	if err := orderInteractor.Add(context.Background(), "1", "123", 2, domain.AnyVersion); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	if len(unitOfWorkMock.DoCalls()) != 1 {
+		t.Errorf("Got: %v, Want: %v", len(unitOfWorkMock.DoCalls()), 1)
+	}
+
+	storedProduct := productRepoMock.StoreCalls()[0].Product
+	if storedProduct.SKU() != 5 || storedProduct.Reserved() != 2 || storedProduct.Available() != 3 {
+		t.Errorf("Got: %v sku, %v reserved, %v available, Want: 5 sku, 2 reserved, 3 available",
+			storedProduct.SKU(), storedProduct.Reserved(), storedProduct.Available())
+	}
+
+	storedReservation := reservationRepoMock.StoreCalls()[0].Reservation
+	if storedReservation.Quantity() != 2 || !storedReservation.ExpiresAt().After(time.Now()) {
+		t.Errorf("Got: %v units until %v, Want: 2 units until a later time", storedReservation.Quantity(), storedReservation.ExpiresAt())
+	}
+
+	storedOrder := orderRepoMock.StoreCalls()[0].Order
+	if storedOrder.GetOrderStatus() != domain.OrderOpen {
+		t.Errorf("Got: %v, Want: %v", storedOrder.GetOrderStatus(), domain.OrderOpen)
+	}
+}
+
+func TestUpdateOrderStatus_PlacementCommitsReservedAndExpiredStock(t *testing.T) {
+	order := domain.NewOrder("1")
+	order.AddQuantity(domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium), 3, domain.DefaultLimits())
+
+	// Only 2 of the 3 units are still reserved, the reservation of the third one has expired.
+	product := domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)
+	product.Reserve(2)
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return order
+		},
+		StoreFunc: func(ctx context.Context, order domain.Order) error {
+			return nil
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return product
+		},
+		StoreFunc: func(ctx context.Context, product domain.Product) error {
+			return nil
+		},
+	}
+	reservationRepoMock := &domain.ReservationRepositoryMock{
+		FindFunc: func(ctx context.Context, orderID, productID string) domain.Reservation {
+			return domain.NewReservation(orderID, productID, 2, time.Now().Add(time.Minute))
+		},
+		DeleteFunc: func(ctx context.Context, orderID, productID string) error {
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock, Reservations: reservationRepoMock})
+
+	registry := metrics.NewRegistry()
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(registry))
+	if _, err := orderInteractor.UpdateOrderStatus(context.Background(), "1", domain.OrderPlaced, "tester", domain.AnyVersion); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	var exposition strings.Builder
+	registry.Write(&exposition)
+	if want := `shop_order_status_changes_total{status="placed"} 1`; !strings.Contains(exposition.String(), want) {
+		t.Errorf("Got: %v, Want: %v counted", exposition.String(), want)
+	}
+	storedProduct := productRepoMock.StoreCalls()[0].Product
+	if storedProduct.SKU() != 2 || storedProduct.Reserved() != 0 {
+		t.Errorf("Got: %v sku, %v reserved, Want: 2 sku, 0 reserved", storedProduct.SKU(), storedProduct.Reserved())
+	}
+	if len(reservationRepoMock.DeleteCalls()) != 1 {
+		t.Errorf("Got: %v, Want: %v", len(reservationRepoMock.DeleteCalls()), 1)
+	}
+	if orderRepoMock.StoreCalls()[0].Order.GetOrderStatus() != domain.OrderPlaced {
+		t.Errorf("Got: %v, Want: %v", orderRepoMock.StoreCalls()[0].Order.GetOrderStatus(), domain.OrderPlaced)
+	}
+}
+
+func newUnitOfWorkMock(repos domain.Repositories) *domain.UnitOfWorkMock {
+	return &domain.UnitOfWorkMock{
+		DoFunc: func(ctx context.Context, fn func(repos domain.Repositories) error) error {
+			return fn(repos)
+		},
+	}
+}
+
+func TestApplyCoupon_RedeemsCouponOfPlacedOrder(t *testing.T) {
+	order := domain.NewOrder("1")
+	order.Add(domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium))
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+
+	now := time.Now()
+	coupon := domain.NewCoupon("save10", domain.PercentDiscount, 0.1, now.Add(-time.Hour), now.Add(time.Hour), 5, true)
+
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return order
+		},
+		StoreFunc: func(ctx context.Context, order domain.Order) error {
+			return nil
+		},
+	}
+	couponRepoMock := &domain.CouponRepositoryMock{
+		FindByCodeFunc: func(ctx context.Context, code string) domain.Coupon {
+			return coupon
+		},
+		StoreFunc: func(ctx context.Context, coupon domain.Coupon) error {
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Coupons: couponRepoMock})
+
+	engine := domain.DefaultPricingEngine(domain.DefaultLimits())
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, unitOfWorkMock, engine, 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	if err := orderInteractor.ApplyCoupon(context.Background(), "1", "SAVE10", domain.AnyVersion); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	storedCoupon := couponRepoMock.StoreCalls()[0].Coupon
+	if storedCoupon.Redemptions() != 1 {
+		t.Errorf("Got: %v, Want: %v", storedCoupon.Redemptions(), 1)
+	}
+	storedOrder := orderRepoMock.StoreCalls()[0].Order
+	if storedOrder.Value(engine) != 90.0 {
+		t.Errorf("Got: %v, Want: %v", storedOrder.Value(engine), 90.0)
+	}
+}
+
+func TestListOrders_InvalidLimit(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{}
+
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, &domain.UnitOfWorkMock{}, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	_, _, got := orderInteractor.List(context.Background(), domain.OrderQuery{Page: domain.Page{Limit: 500}})
+
+	if !errors.Is(got, usecases.ErrInvalidQuery) || !errors.Is(got, domain.ErrInvalidPageLimit) {
+		t.Errorf("Got: %v, Want: %v", got, domain.ErrInvalidPageLimit)
+	}
+	if len(orderRepoMock.FindCalls()) != 0 {
+		t.Error("orders must not be read for an invalid query")
+	}
+}
+
+func TestListOrders_UsesTheDefaultLimitOfTheLimits(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindFunc: func(ctx context.Context, query domain.OrderQuery) ([]domain.Order, string, error) {
+			return nil, "", nil
+		},
+	}
+	limits := domain.DefaultLimits()
+	limits.DefaultPageLimit = 5
+
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, &domain.UnitOfWorkMock{}, domain.DefaultPricingEngine(limits), 15*time.Minute, limits, usecases.NewMetrics(metrics.NewRegistry()))
+	if _, _, err := orderInteractor.List(context.Background(), domain.OrderQuery{}); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	if got := orderRepoMock.FindCalls()[0].Query.Limit; got != 5 {
+		t.Errorf("Got: %v, Want: %v", got, 5)
+	}
+}
+
+func TestListOrders_ReturnsNextCursor(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindFunc: func(ctx context.Context, query domain.OrderQuery) ([]domain.Order, string, error) {
+			return []domain.Order{domain.NewOrder("1")}, "next", nil
+		},
+	}
+
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, &domain.UnitOfWorkMock{}, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	orders, nextCursor, err := orderInteractor.List(context.Background(), domain.OrderQuery{Page: domain.Page{Limit: 1}, Status: domain.OrderPlaced})
+
+	if err != nil || len(orders) != 1 || nextCursor != "next" {
+		t.Errorf("Got: %v, %v, %v, Want: 1 order and the next cursor", orders, nextCursor, err)
+	}
+	if orderRepoMock.FindCalls()[0].Query.Status != domain.OrderPlaced {
+		t.Errorf("Got: %v, Want: %v", orderRepoMock.FindCalls()[0].Query.Status, domain.OrderPlaced)
+	}
+}
+
+func TestUpdateOrderStatus_CancellationRestocksOnce(t *testing.T) {
+	order := domain.NewOrder("1")
+	order.AddQuantity(domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium), 3, domain.DefaultLimits())
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+
+	product := domain.NewProduct("123", "nike shoes", 100.0, 2, domain.Premium)
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return order
+		},
+		StoreFunc: func(ctx context.Context, stored domain.Order) error {
+			order = stored
+			return nil
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return product
+		},
+		StoreFunc: func(ctx context.Context, stored domain.Product) error {
+			product = stored
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock})
+
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	for i := 0; i < 2; i++ {
+		if _, err := orderInteractor.UpdateOrderStatus(context.Background(), "1", domain.OrderCancelled, "tester", domain.AnyVersion); err != nil {
+			t.Fatalf("Got: %v, Want: %v", err, nil)
+		}
+	}
+
+	if product.SKU() != 5 {
+		t.Errorf("Got: %v, Want: %v", product.SKU(), 5)
+	}
+	if len(productRepoMock.StoreCalls()) != 1 {
+		t.Errorf("Got: %v, Want: %v", len(productRepoMock.StoreCalls()), 1)
+	}
+}
+
+func TestUpdateOrderStatus_CancellationReleasesCouponRedemptionsOnce(t *testing.T) {
+	now := time.Now()
+	coupon := domain.NewCoupon("save10", domain.PercentDiscount, 0.1, now.Add(-time.Hour), now.Add(time.Hour), 1, true)
+	order := domain.NewOrder("1")
+	order.Add(domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium))
+	order.ApplyCoupon(coupon, now)
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+	coupon.Redeem()
+
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return order
+		},
+		StoreFunc: func(ctx context.Context, stored domain.Order) error {
+			order = stored
+			return nil
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return domain.NewProduct("123", "nike shoes", 100.0, 4, domain.Premium)
+		},
+		StoreFunc: func(ctx context.Context, product domain.Product) error {
+			return nil
+		},
+	}
+	couponRepoMock := &domain.CouponRepositoryMock{
+		FindByCodeFunc: func(ctx context.Context, code string) domain.Coupon {
+			return coupon
+		},
+		StoreFunc: func(ctx context.Context, stored domain.Coupon) error {
+			coupon = stored
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock, Coupons: couponRepoMock})
+
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	for i := 0; i < 2; i++ {
+		if _, err := orderInteractor.UpdateOrderStatus(context.Background(), "1", domain.OrderCancelled, "tester", domain.AnyVersion); err != nil {
+			t.Fatalf("Got: %v, Want: %v", err, nil)
+		}
+	}
+
+	if coupon.Redemptions() != 0 {
+		t.Errorf("Got: %v, Want: %v", coupon.Redemptions(), 0)
+	}
+	if len(couponRepoMock.StoreCalls()) != 1 {
+		t.Errorf("Got: %v, Want: %v", len(couponRepoMock.StoreCalls()), 1)
+	}
+}
+
+func TestUpdateOrderStatus_StaleVersionIsRejected(t *testing.T) {
+	order := domain.NewOrder("1")
+	order.Add(domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium))
+	order.IncrementVersion()
+	order.IncrementVersion()
+
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return order
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock})
+
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	_, got := orderInteractor.UpdateOrderStatus(context.Background(), "1", domain.OrderCancelled, "tester", 1)
+
+	if !errors.Is(got, domain.ErrVersionConflict) {
+		t.Errorf("Got: %v, Want: %v", got, domain.ErrVersionConflict)
+	}
+	if len(orderRepoMock.StoreCalls()) != 0 {
+		t.Error("order must not be stored when its version has changed")
+	}
+}
+
+func TestCreateOrder_OwnedByCustomer(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		StoreFunc: func(ctx context.Context, order domain.Order) error {
+			return nil
+		},
+	}
+	customerRepoMock := &domain.CustomerRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Customer {
+			if id != "c-1" {
+				return domain.Customer{}
+			}
+			return domain.NewCustomer(id, "Jane Doe", "jane@example.com", "", time.Now())
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Customers: customerRepoMock})
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+
+	ctx := usecases.WithPrincipal(context.Background(), domain.Principal{Subject: "c-1", Role: domain.RoleCustomer})
+	created, err := orderInteractor.Create(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.CustomerID != "c-1" || created.Version != 1 {
+		t.Errorf("Got: %+v, Want: a first version of an order of c-1 with an id", created)
+	}
+	if _, err := orderInteractor.Create(ctx, "c-2"); !errors.Is(err, usecases.ErrForbidden) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrForbidden)
+	}
+	if _, err := orderInteractor.Create(context.Background(), "c-2"); !errors.Is(err, usecases.ErrCustomerNotFound) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrCustomerNotFound)
+	}
+	if len(orderRepoMock.StoreCalls()) != 1 {
+		t.Errorf("Got: %v, Want: %v", len(orderRepoMock.StoreCalls()), 1)
+	}
+}
+
+func TestAdd_UnknownOrderIsNotFound(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return domain.Order{}
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock})
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+
+	if err := orderInteractor.Add(context.Background(), "1", "123", 1, domain.AnyVersion); !errors.Is(err, usecases.ErrOrderNotFound) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrOrderNotFound)
+	}
+	if len(orderRepoMock.StoreCalls()) != 0 || len(productRepoMock.StoreCalls()) != 0 {
+		t.Error("nothing must be stored when the order does not exist")
+	}
+}
+
+func TestAddProductToOrder_UnknownProduct(t *testing.T) {
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return domain.NewOrder(id)
+		},
+	}
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return domain.Product{}
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock, Products: productRepoMock})
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, productRepoMock, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+
+	if err := orderInteractor.Add(context.Background(), "1", "42", 1, domain.AnyVersion); !errors.Is(err, usecases.ErrProductNotFound) {
+		t.Errorf("Got: %v, Want: %v", err, usecases.ErrProductNotFound)
+	}
+	if len(orderRepoMock.StoreCalls()) != 0 || len(productRepoMock.StoreCalls()) != 0 {
+		t.Error("nothing must be stored when the product does not exist")
+	}
+}
+
+func TestListOrders_DoesNotTakeReadFailuresForInvalidQueries(t *testing.T) {
+	tests := []struct {
+		name         string
+		findErr      error
+		invalidQuery bool
+	}{
+		{name: "invalid cursor", findErr: domain.ErrInvalidCursor, invalidQuery: true},
+		{name: "read failure", findErr: errors.New("database is closed"), invalidQuery: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderRepoMock := &domain.OrderRepositoryMock{
+				FindFunc: func(ctx context.Context, query domain.OrderQuery) ([]domain.Order, string, error) {
+					return nil, "", tt.findErr
+				},
+			}
+
+			orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, &domain.UnitOfWorkMock{}, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+			_, _, err := orderInteractor.List(context.Background(), domain.OrderQuery{Page: domain.Page{Limit: 1}})
+
+			if err == nil || errors.Is(err, usecases.ErrInvalidQuery) != tt.invalidQuery {
+				t.Errorf("Got: %v, Want: an error which is an invalid query: %v", err, tt.invalidQuery)
+			}
+		})
+	}
+}
+
+func TestUpdateOrderStatus_ReturnsVersionOfChangedOrder(t *testing.T) {
+	order := domain.NewOrder("1")
+	order.Add(domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium))
+	order.SetOrderStatus(domain.OrderPlaced, "tester")
+
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return order
+		},
+		StoreFunc: func(ctx context.Context, stored domain.Order) error {
+			stored.IncrementVersion()
+			order = stored
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock})
+
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	version, err := orderInteractor.UpdateOrderStatus(context.Background(), "1", domain.OrderDispatched, "tester", 0)
+	if err != nil || version != 1 {
+		t.Errorf("Got: %v, %v, Want: version 1", version, err)
+	}
+
+	// A retry finds the order in the status already, and leaves it in the same version.
+	version, err = orderInteractor.UpdateOrderStatus(context.Background(), "1", domain.OrderDispatched, "tester", domain.AnyVersion)
+	if err != nil || version != 1 {
+		t.Errorf("Got: %v, %v, Want: version 1", version, err)
+	}
+}
+
+func TestRemoveCoupon_FailsWhenOrderHasChanged(t *testing.T) {
+	order := domain.NewOrder("1")
+	orderRepoMock := &domain.OrderRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Order {
+			return order
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Orders: orderRepoMock})
+
+	orderInteractor := usecases.NewOrderInteractor(orderRepoMock, &domain.ProductRepositoryMock{}, unitOfWorkMock, domain.DefaultPricingEngine(domain.DefaultLimits()), 15*time.Minute, domain.DefaultLimits(), usecases.NewMetrics(metrics.NewRegistry()))
+	err := orderInteractor.RemoveCoupon(context.Background(), "1", "SAVE10", 1)
+
+	if !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("Got: %v, Want: %v", err, domain.ErrVersionConflict)
+	}
+	if len(orderRepoMock.StoreCalls()) != 0 {
+		t.Error("order must not be stored when its version has changed")
+	}
+}
@@ -1,56 +1,216 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/logging"
+)
+
+var (
+	ErrProductNotFound      = errors.New("product does not exist")
+	ErrProductAlreadyExists = errors.New("product already exists")
 )
 
 type Product struct {
-	ID       string  `json:"id"`
-	Name     string  `json:"name"`
-	Category string  `json:"category"`
-	Price    float64 `json:"price"`
-	SKU      int     `json:"sku,omitempty"`
-	Quantity int     `json:"quantity,omitempty"`
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Category  string  `json:"category"`
+	Price     float64 `json:"price"`
+	SKU       int     `json:"sku,omitempty"`
+	Available int     `json:"available"`
+	Reserved  int     `json:"reserved"`
+	Quantity  int     `json:"quantity,omitempty"`
+	LineTotal float64 `json:"line_total,omitempty"`
+	Version   int     `json:"version,omitempty"`
+}
+
+// ProductUpdate holds the fields of a product which are changed by a partial update. Fields left nil
+// keep their current value.
+type ProductUpdate struct {
+	Name     *string
+	Category *string
+	Price    *float64
+	SKU      *int
 }
 
 type ProductInteractor struct {
 	productRepository domain.ProductRepository
+	unitOfWork        domain.UnitOfWork
+	limits            domain.Limits
 }
 
-func NewProductInteractor(productRepo domain.ProductRepository) *ProductInteractor {
-	return &ProductInteractor{productRepository: productRepo}
+// NewProductInteractor builds a ProductInteractor which lists products in pages within the limits.
+func NewProductInteractor(productRepo domain.ProductRepository, unitOfWork domain.UnitOfWork, limits domain.Limits) *ProductInteractor {
+	return &ProductInteractor{productRepository: productRepo, unitOfWork: unitOfWork, limits: limits}
 }
 
-func (interactor *ProductInteractor) GetDetails(productID string) (Product, error) {
-	domainProduct := interactor.productRepository.FindById(productID)
+func (interactor *ProductInteractor) GetDetails(ctx context.Context, productID string) (Product, error) {
+	domainProduct := interactor.productRepository.FindById(ctx, productID)
 	if domainProduct.ID() == "" {
-		return Product{}, errors.New("product does not exist")
-	}
-	product := Product{
-		ID:       domainProduct.ID(),
-		Name:     domainProduct.Name(),
-		Category: string(domainProduct.Category()),
-		Price:    domainProduct.Price(),
+		return Product{}, ErrProductNotFound
 	}
-	return product, nil
+	return catalogueProduct(domainProduct), nil
 }
 
-func (interactor *ProductInteractor) GetAll() []Product {
-	productsFromDb := interactor.productRepository.GetAll()
+func (interactor *ProductInteractor) GetAll(ctx context.Context) []Product {
+	productsFromDb := interactor.productRepository.GetAll(ctx)
 	if len(productsFromDb) == 0 {
 		return []Product{}
 	}
 	products := make([]Product, 0)
 	for _, product := range productsFromDb {
 		if product.SKU() > 0 {
-			products = append(products, Product{ID: product.ID(),
-				Name:     product.Name(),
-				Category: string(product.Category()),
-				Price:    product.Price(),
-				SKU:      product.SKU(),
-			})
+			products = append(products, catalogueProduct(product))
 		}
 	}
 	return products
 }
+
+// List returns a page of the products in stock which match the query, along with the cursor of the
+// next page. The cursor is empty when there are no more products. A query without a limit gets the
+// default one.
+func (interactor *ProductInteractor) List(ctx context.Context, query domain.ProductQuery) ([]Product, string, error) {
+	if query.Limit == 0 {
+		query.Limit = interactor.limits.DefaultPageLimit
+	}
+	if err := query.Validate(interactor.limits); err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+	if query.Category != "" && !query.Category.IsValid() {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidQuery, domain.ErrInvalidProductCategory)
+	}
+	if query.MinPrice < 0 {
+		return nil, "", fmt.Errorf("%w: min_price must not be negative", ErrInvalidQuery)
+	}
+
+	query.InStockOnly = true
+	productsFromDb, nextCursor, err := interactor.productRepository.Find(ctx, query)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidQuery, err)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	products := make([]Product, len(productsFromDb))
+	for idx, product := range productsFromDb {
+		products[idx] = catalogueProduct(product)
+	}
+	return products, nextCursor, nil
+}
+
+func (interactor *ProductInteractor) Create(ctx context.Context, input Product) (Product, error) {
+	product := domain.NewProduct(input.ID, input.Name, input.Price, input.SKU, domain.ProductCategory(input.Category))
+	if err := product.Validate(); err != nil {
+		return Product{}, err
+	}
+
+	ctx = logging.With(ctx, "product_id", product.ID())
+	err := interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		existing := repos.Products.FindById(ctx, product.ID())
+		if existing.ID() != "" {
+			return fmt.Errorf("%w: #%s", ErrProductAlreadyExists, product.ID())
+		}
+		return repos.Products.Store(ctx, product)
+	})
+	if err != nil {
+		return Product{}, err
+	}
+	product.IncrementVersion()
+	return catalogueProduct(product), nil
+}
+
+// Replace overwrites every field of an existing product apart from its id and the units reserved for
+// open orders. The product is only changed if its version is expectedVersion, unless expectedVersion
+// is domain.AnyVersion.
+func (interactor *ProductInteractor) Replace(ctx context.Context, productID string, input Product, expectedVersion int) (Product, error) {
+	ctx = logging.With(ctx, "product_id", productID)
+	product := domain.NewProduct(productID, input.Name, input.Price, input.SKU, domain.ProductCategory(input.Category))
+	if err := product.Validate(); err != nil {
+		return Product{}, err
+	}
+
+	err := interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		existing := repos.Products.FindById(ctx, productID)
+		if existing.ID() == "" {
+			return ErrProductNotFound
+		}
+		if err := domain.CheckVersion(expectedVersion, existing.Version()); err != nil {
+			return err
+		}
+		product = existing.WithDetails(product.Name(), product.Price(), product.SKU(), product.Category())
+		return repos.Products.Store(ctx, product)
+	})
+	if err != nil {
+		return Product{}, err
+	}
+	product.IncrementVersion()
+	return catalogueProduct(product), nil
+}
+
+func (interactor *ProductInteractor) Update(ctx context.Context, productID string, update ProductUpdate, expectedVersion int) (Product, error) {
+	ctx = logging.With(ctx, "product_id", productID)
+	var product domain.Product
+	err := interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		existing := repos.Products.FindById(ctx, productID)
+		if existing.ID() == "" {
+			return ErrProductNotFound
+		}
+		if err := domain.CheckVersion(expectedVersion, existing.Version()); err != nil {
+			return err
+		}
+
+		name, category, price, sku := existing.Name(), existing.Category(), existing.Price(), existing.SKU()
+		if update.Name != nil {
+			name = *update.Name
+		}
+		if update.Category != nil {
+			category = domain.ProductCategory(*update.Category)
+		}
+		if update.Price != nil {
+			price = *update.Price
+		}
+		if update.SKU != nil {
+			sku = *update.SKU
+		}
+
+		product = existing.WithDetails(name, price, sku, category)
+		if err := product.Validate(); err != nil {
+			return err
+		}
+		return repos.Products.Store(ctx, product)
+	})
+	if err != nil {
+		return Product{}, err
+	}
+	product.IncrementVersion()
+	return catalogueProduct(product), nil
+}
+
+func (interactor *ProductInteractor) Delete(ctx context.Context, productID string, expectedVersion int) error {
+	ctx = logging.With(ctx, "product_id", productID)
+	return interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		existing := repos.Products.FindById(ctx, productID)
+		if existing.ID() == "" {
+			return ErrProductNotFound
+		}
+		if err := domain.CheckVersion(expectedVersion, existing.Version()); err != nil {
+			return err
+		}
+		return repos.Products.Delete(ctx, productID)
+	})
+}
+
+func catalogueProduct(product domain.Product) Product {
+	return Product{
+		ID:        product.ID(),
+		Name:      product.Name(),
+		Category:  string(product.Category()),
+		Price:     product.Price(),
+		SKU:       product.SKU(),
+		Available: product.Available(),
+		Reserved:  product.Reserved(),
+		Version:   product.Version(),
+	}
+}
@@ -0,0 +1,102 @@
+package usecases_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+	"testing"
+)
+
+func TestCreateProduct_DuplicateID(t *testing.T) {
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return domain.NewProduct(id, "nike shoes", 100.0, 5, domain.Premium)
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Products: productRepoMock})
+
+	productInteractor := usecases.NewProductInteractor(productRepoMock, unitOfWorkMock, domain.DefaultLimits())
+	_, got := productInteractor.Create(context.Background(), usecases.Product{ID: "1", Name: "shirt", Category: "regular", Price: 10.0, SKU: 1})
+
+	if !errors.Is(got, usecases.ErrProductAlreadyExists) {
+		t.Errorf("Got: %v, Want: %v", got, usecases.ErrProductAlreadyExists)
+	}
+	if len(productRepoMock.StoreCalls()) != 0 {
+		t.Error("product must not be stored when the id is already taken")
+	}
+}
+
+func TestCreateProduct_InvalidProduct(t *testing.T) {
+	productRepoMock := &domain.ProductRepositoryMock{}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Products: productRepoMock})
+
+	productInteractor := usecases.NewProductInteractor(productRepoMock, unitOfWorkMock, domain.DefaultLimits())
+	_, got := productInteractor.Create(context.Background(), usecases.Product{ID: "1", Name: "shirt", Category: "regular", Price: -10.0, SKU: 1})
+
+	var productErr *domain.ProductError
+	if !errors.As(got, &productErr) {
+		t.Errorf("Got: %v, Want: %v", got, domain.ErrInvalidProductPrice)
+	}
+}
+
+func TestUpdateProduct_OnlyChangesGivenFields(t *testing.T) {
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return domain.NewProduct(id, "nike shoes", 100.0, 5, domain.Premium)
+		},
+		StoreFunc: func(ctx context.Context, product domain.Product) error {
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Products: productRepoMock})
+
+	price := 80.0
+	productInteractor := usecases.NewProductInteractor(productRepoMock, unitOfWorkMock, domain.DefaultLimits())
+	got, err := productInteractor.Update(context.Background(), "1", usecases.ProductUpdate{Price: &price}, domain.AnyVersion)
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	want := usecases.Product{ID: "1", Name: "nike shoes", Category: "premium", Price: 80.0, SKU: 5, Available: 5, Version: 1}
+	if got != want {
+		t.Errorf("Got: %+v, Want: %+v", got, want)
+	}
+}
+
+func TestDeleteProduct_NotFound(t *testing.T) {
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return domain.Product{}
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Products: productRepoMock})
+
+	productInteractor := usecases.NewProductInteractor(productRepoMock, unitOfWorkMock, domain.DefaultLimits())
+	got := productInteractor.Delete(context.Background(), "1", domain.AnyVersion)
+
+	if !errors.Is(got, usecases.ErrProductNotFound) {
+		t.Errorf("Got: %v, Want: %v", got, usecases.ErrProductNotFound)
+	}
+}
+
+func TestGetProductDetails_ReportsSoldOutProductsAsSuch(t *testing.T) {
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return domain.NewProduct(id, "nike shoes", 100.0, 0, domain.Premium)
+		},
+	}
+	productInteractor := usecases.NewProductInteractor(productRepoMock, newUnitOfWorkMock(domain.Repositories{}), domain.DefaultLimits())
+	product, err := productInteractor.GetDetails(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	data, _ := json.Marshal(product)
+	got := map[string]interface{}{}
+	json.Unmarshal(data, &got)
+	if got["available"] != 0.0 || got["reserved"] != 0.0 {
+		t.Errorf("Got: %s, Want: available and reserved stock of 0", data)
+	}
+}
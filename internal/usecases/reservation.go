@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/logging"
+	"time"
+)
+
+// ReservationSweeper releases the stock held by expired reservations, so that products added to
+// abandoned carts become available to other orders again.
+type ReservationSweeper struct {
+	unitOfWork domain.UnitOfWork
+	interval   time.Duration
+}
+
+func NewReservationSweeper(unitOfWork domain.UnitOfWork, interval time.Duration) *ReservationSweeper {
+	return &ReservationSweeper{unitOfWork: unitOfWork, interval: interval}
+}
+
+// SweepExpired releases every reservation which has expired at the given time, and returns how many
+// were released.
+func (sweeper *ReservationSweeper) SweepExpired(ctx context.Context, at time.Time) (int, error) {
+	released := 0
+	err := sweeper.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		released = 0
+		for _, reservation := range repos.Reservations.FindExpired(ctx, at) {
+			if err := releaseReservation(ctx, repos, reservation); err != nil {
+				return err
+			}
+			released += 1
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return released, nil
+}
+
+// Run sweeps expired reservations every interval until the context is done.
+func (sweeper *ReservationSweeper) Run(ctx context.Context) {
+	logger := logging.Default().With("worker", "reservation_sweeper")
+	ctx = logging.NewContext(ctx, logger)
+	ticker := time.NewTicker(sweeper.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case at := <-ticker.C:
+			released, err := sweeper.SweepExpired(ctx, at.UTC())
+			if err != nil {
+				logger.Error("could not sweep expired reservations", "error", err)
+				continue
+			}
+			if released > 0 {
+				logger.Info("released expired reservations", "released", released)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package usecases_test
+
+import (
+	"context"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+	"testing"
+	"time"
+)
+
+func TestSweepExpired_ReleasesReservedStock(t *testing.T) {
+	now := time.Now()
+	product := domain.NewProduct("123", "nike shoes", 100.0, 5, domain.Premium)
+	product.Reserve(4)
+	productRepoMock := &domain.ProductRepositoryMock{
+		FindByIdFunc: func(ctx context.Context, id string) domain.Product {
+			return product
+		},
+		StoreFunc: func(ctx context.Context, stored domain.Product) error {
+			product = stored
+			return nil
+		},
+	}
+	reservationRepoMock := &domain.ReservationRepositoryMock{
+		FindExpiredFunc: func(ctx context.Context, at time.Time) []domain.Reservation {
+			return []domain.Reservation{domain.NewReservation("1", "123", 3, now.Add(-time.Minute))}
+		},
+		DeleteFunc: func(ctx context.Context, orderID, productID string) error {
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Products: productRepoMock, Reservations: reservationRepoMock})
+
+	sweeper := usecases.NewReservationSweeper(unitOfWorkMock, time.Minute)
+	released, err := sweeper.SweepExpired(context.Background(), now)
+	if err != nil || released != 1 {
+		t.Fatalf("Got: %v, %v, Want: 1, %v", released, err, nil)
+	}
+
+	if product.Reserved() != 1 || product.Available() != 4 {
+		t.Errorf("Got: %v reserved, %v available, Want: 1 reserved, 4 available", product.Reserved(), product.Available())
+	}
+	if len(reservationRepoMock.DeleteCalls()) != 1 {
+		t.Errorf("Got: %v, Want: %v", len(reservationRepoMock.DeleteCalls()), 1)
+	}
+}
@@ -0,0 +1,175 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"simple-order-service/internal/domain"
+	"time"
+)
+
+var ErrWebhookNotFound = errors.New("webhook does not exist")
+
+// Webhook holds the secret of the webhook only when it is created, since the secret is used to sign
+// the events sent to the webhook.
+type Webhook struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type DeadLetter struct {
+	ID        string    `json:"id"`
+	OrderID   string    `json:"order_id"`
+	EventType string    `json:"event_type"`
+	Sequence  int       `json:"sequence"`
+	At        time.Time `json:"at"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+}
+
+type WebhookInteractor struct {
+	webhookRepository domain.WebhookRepository
+	outboxRepository  domain.OutboxRepository
+	unitOfWork        domain.UnitOfWork
+	allowedHosts      []string
+}
+
+// NewWebhookInteractor builds a WebhookInteractor which only lets webhooks target loopback,
+// link-local or private addresses on allowedHosts.
+func NewWebhookInteractor(webhookRepo domain.WebhookRepository, outboxRepo domain.OutboxRepository, unitOfWork domain.UnitOfWork, allowedHosts []string) *WebhookInteractor {
+	return &WebhookInteractor{webhookRepository: webhookRepo, outboxRepository: outboxRepo, unitOfWork: unitOfWork, allowedHosts: allowedHosts}
+}
+
+// Create registers a webhook. A secret is generated for the webhook when none is given.
+func (interactor *WebhookInteractor) Create(ctx context.Context, input Webhook) (Webhook, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return Webhook{}, err
+	}
+	if input.Secret == "" {
+		if input.Secret, err = randomHex(32); err != nil {
+			return Webhook{}, err
+		}
+	}
+	webhook := domain.NewWebhook(id, input.URL, input.Secret, eventTypes(input.EventTypes), time.Now().UTC())
+	if err := webhook.Validate(interactor.allowedHosts); err != nil {
+		return Webhook{}, err
+	}
+
+	err = interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		return repos.Webhooks.Store(ctx, webhook)
+	})
+	if err != nil {
+		return Webhook{}, err
+	}
+	created := toWebhook(webhook)
+	created.Secret = webhook.Secret()
+	return created, nil
+}
+
+func (interactor *WebhookInteractor) GetDetails(ctx context.Context, id string) (Webhook, error) {
+	webhook := interactor.webhookRepository.FindById(ctx, id)
+	if webhook.ID() == "" {
+		return Webhook{}, ErrWebhookNotFound
+	}
+	return toWebhook(webhook), nil
+}
+
+func (interactor *WebhookInteractor) GetAll(ctx context.Context) []Webhook {
+	webhooksFromDb := interactor.webhookRepository.GetAll(ctx)
+	webhooks := make([]Webhook, len(webhooksFromDb))
+	for idx, webhook := range webhooksFromDb {
+		webhooks[idx] = toWebhook(webhook)
+	}
+	return webhooks
+}
+
+// Replace changes the url and the event types of a webhook, and its secret when one is given.
+func (interactor *WebhookInteractor) Replace(ctx context.Context, id string, input Webhook) (Webhook, error) {
+	var webhook domain.Webhook
+	err := interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		existing := repos.Webhooks.FindById(ctx, id)
+		if existing.ID() == "" {
+			return ErrWebhookNotFound
+		}
+		secret := input.Secret
+		if secret == "" {
+			secret = existing.Secret()
+		}
+		webhook = domain.NewWebhook(id, input.URL, secret, eventTypes(input.EventTypes), existing.CreatedAt())
+		if err := webhook.Validate(interactor.allowedHosts); err != nil {
+			return err
+		}
+		return repos.Webhooks.Store(ctx, webhook)
+	})
+	if err != nil {
+		return Webhook{}, err
+	}
+	return toWebhook(webhook), nil
+}
+
+// Delete removes the webhook. Its pending deliveries are dropped by the dispatcher.
+func (interactor *WebhookInteractor) Delete(ctx context.Context, id string) error {
+	return interactor.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		existing := repos.Webhooks.FindById(ctx, id)
+		if existing.ID() == "" {
+			return ErrWebhookNotFound
+		}
+		return repos.Webhooks.Delete(ctx, id)
+	})
+}
+
+// DeadLetters returns the events which could not be delivered to the webhook.
+func (interactor *WebhookInteractor) DeadLetters(ctx context.Context, id string) ([]DeadLetter, error) {
+	webhook := interactor.webhookRepository.FindById(ctx, id)
+	if webhook.ID() == "" {
+		return nil, ErrWebhookNotFound
+	}
+	deliveries := interactor.outboxRepository.DeadLetters(ctx, id)
+	deadLetters := make([]DeadLetter, len(deliveries))
+	for idx, delivery := range deliveries {
+		deadLetters[idx] = DeadLetter{
+			ID:        delivery.ID,
+			OrderID:   delivery.Event.OrderID,
+			EventType: string(delivery.Event.Type),
+			Sequence:  delivery.Event.Sequence,
+			At:        delivery.Event.At,
+			Attempts:  delivery.Attempts,
+			LastError: delivery.LastError,
+		}
+	}
+	return deadLetters, nil
+}
+
+func toWebhook(webhook domain.Webhook) Webhook {
+	types := make([]string, len(webhook.EventTypes()))
+	for idx, eventType := range webhook.EventTypes() {
+		types[idx] = string(eventType)
+	}
+	return Webhook{
+		ID:         webhook.ID(),
+		URL:        webhook.URL(),
+		EventTypes: types,
+		CreatedAt:  webhook.CreatedAt(),
+	}
+}
+
+func eventTypes(types []string) []domain.OrderEventType {
+	eventTypes := make([]domain.OrderEventType, len(types))
+	for idx, eventType := range types {
+		eventTypes[idx] = domain.OrderEventType(eventType)
+	}
+	return eventTypes
+}
+
+func randomHex(size int) (string, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
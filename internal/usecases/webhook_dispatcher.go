@@ -0,0 +1,222 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"simple-order-service/internal/domain"
+	"simple-order-service/pkg/logging"
+	"sync"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader carries the HMAC-SHA256 of the payload, keyed with the secret of the
+	// webhook, in the form "sha256=<hex digest>".
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+
+	webhookBatchSize = 100
+)
+
+// WebhookSender posts a payload to the url of a webhook, and returns an error if the webhook did not
+// accept it.
+type WebhookSender interface {
+	Send(url string, payload []byte, headers map[string]string) error
+}
+
+// WebhookDispatcher hands the messages of the outbox to the webhooks subscribed to them, and
+// delivers them to up to concurrency webhooks at once. A failed delivery is retried after
+// baseBackoff, doubled for every failed attempt up to maxBackoff, and is moved to the dead letters
+// after maxAttempts attempts.
+type WebhookDispatcher struct {
+	unitOfWork  domain.UnitOfWork
+	sender      WebhookSender
+	interval    time.Duration
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	concurrency int
+}
+
+type webhookPayload struct {
+	ID    string            `json:"id"`
+	Event domain.OrderEvent `json:"event"`
+}
+
+func NewWebhookDispatcher(unitOfWork domain.UnitOfWork, sender WebhookSender, interval time.Duration, maxAttempts int, baseBackoff, maxBackoff time.Duration, concurrency int) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		unitOfWork:  unitOfWork,
+		sender:      sender,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		baseBackoff: baseBackoff,
+		maxBackoff:  maxBackoff,
+		concurrency: concurrency,
+	}
+}
+
+// FanOut turns every message of the outbox into one delivery for each webhook subscribed to it, and
+// removes the message from the outbox.
+func (dispatcher *WebhookDispatcher) FanOut(ctx context.Context, at time.Time) error {
+	return dispatcher.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		webhooks := repos.Webhooks.GetAll(ctx)
+		for _, message := range repos.Outbox.Pending(ctx, webhookBatchSize) {
+			for _, webhook := range webhooks {
+				if !webhook.Subscribes(message.Event.Type) {
+					continue
+				}
+				if err := repos.Outbox.StoreDelivery(ctx, domain.NewWebhookDelivery(webhook.ID(), message, at)); err != nil {
+					return err
+				}
+			}
+			if err := repos.Outbox.Delete(ctx, message.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeliverDue attempts the deliveries which are due at the given time, and returns how many of them
+// succeeded and failed. The webhooks are called outside of any transaction.
+//
+// Up to concurrency webhooks are delivered to at once, so that a slow webhook does not hold up the
+// others. The deliveries to a webhook are attempted one at a time, in the order they are due; once
+// one fails, the others are left due until the next round rather than kept waiting on the webhook.
+func (dispatcher *WebhookDispatcher) DeliverDue(ctx context.Context, at time.Time) (int, int, error) {
+	var deliveries []domain.WebhookDelivery
+	webhooks := make(map[string]domain.Webhook)
+	err := dispatcher.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		deliveries = repos.Outbox.DueDeliveries(ctx, at, webhookBatchSize)
+		for _, webhook := range repos.Webhooks.GetAll(ctx) {
+			webhooks[webhook.ID()] = webhook
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	webhookIDs := make([]string, 0)
+	deliveriesByWebhook := make(map[string][]domain.WebhookDelivery)
+	for _, delivery := range deliveries {
+		if _, ok := deliveriesByWebhook[delivery.WebhookID]; !ok {
+			webhookIDs = append(webhookIDs, delivery.WebhookID)
+		}
+		deliveriesByWebhook[delivery.WebhookID] = append(deliveriesByWebhook[delivery.WebhookID], delivery)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	delivered, failed := 0, 0
+	var firstErr error
+	slots := make(chan struct{}, dispatcher.concurrency)
+	for _, webhookID := range webhookIDs {
+		webhook, ok := webhooks[webhookID]
+		queued := deliveriesByWebhook[webhookID]
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			var webhookDelivered, webhookFailed int
+			var err error
+			if ok {
+				webhookDelivered, webhookFailed, err = dispatcher.deliverTo(ctx, webhook, queued, at)
+			} else {
+				// The webhook has been deleted since the deliveries were queued.
+				err = dispatcher.completeAll(ctx, queued)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			delivered += webhookDelivered
+			failed += webhookFailed
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}()
+	}
+	wg.Wait()
+	return delivered, failed, firstErr
+}
+
+// deliverTo attempts the deliveries to the webhook in turn, until one of them fails.
+func (dispatcher *WebhookDispatcher) deliverTo(ctx context.Context, webhook domain.Webhook, deliveries []domain.WebhookDelivery, at time.Time) (int, int, error) {
+	delivered := 0
+	for _, delivery := range deliveries {
+		if sendErr := dispatcher.send(webhook, delivery); sendErr != nil {
+			logging.FromContext(ctx).Warn("webhook delivery failed", "delivery_id", delivery.ID, "webhook_id", webhook.ID(), "error", sendErr)
+			return delivered, 1, dispatcher.fail(ctx, delivery, sendErr, at)
+		}
+		delivered += 1
+		if err := dispatcher.complete(ctx, delivery); err != nil {
+			return delivered, 0, err
+		}
+	}
+	return delivered, 0, nil
+}
+
+func (dispatcher *WebhookDispatcher) completeAll(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	for _, delivery := range deliveries {
+		if err := dispatcher.complete(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (dispatcher *WebhookDispatcher) send(webhook domain.Webhook, delivery domain.WebhookDelivery) error {
+	payload, err := json.Marshal(webhookPayload{ID: delivery.MessageID, Event: delivery.Event})
+	if err != nil {
+		return err
+	}
+	return dispatcher.sender.Send(webhook.URL(), payload, map[string]string{
+		WebhookSignatureHeader: "sha256=" + webhook.Sign(payload),
+		WebhookEventHeader:     string(delivery.Event.Type),
+		WebhookDeliveryHeader:  delivery.ID,
+	})
+}
+
+func (dispatcher *WebhookDispatcher) complete(ctx context.Context, delivery domain.WebhookDelivery) error {
+	return dispatcher.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		return repos.Outbox.DeleteDelivery(ctx, delivery.ID)
+	})
+}
+
+func (dispatcher *WebhookDispatcher) fail(ctx context.Context, delivery domain.WebhookDelivery, sendErr error, at time.Time) error {
+	delivery.Fail(sendErr.Error(), at, dispatcher.baseBackoff, dispatcher.maxBackoff)
+	return dispatcher.unitOfWork.Do(ctx, func(repos domain.Repositories) error {
+		if delivery.Attempts < dispatcher.maxAttempts {
+			return repos.Outbox.StoreDelivery(ctx, delivery)
+		}
+		if err := repos.Outbox.DeleteDelivery(ctx, delivery.ID); err != nil {
+			return err
+		}
+		return repos.Outbox.StoreDeadLetter(ctx, delivery)
+	})
+}
+
+// Run dispatches the outbox every interval until the context is done.
+func (dispatcher *WebhookDispatcher) Run(ctx context.Context) {
+	logger := logging.Default().With("worker", "webhook_dispatcher")
+	ctx = logging.NewContext(ctx, logger)
+	ticker := time.NewTicker(dispatcher.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case at := <-ticker.C:
+			if err := dispatcher.FanOut(ctx, at.UTC()); err != nil {
+				logger.Error("could not fan out the outbox", "error", err)
+				continue
+			}
+			if _, _, err := dispatcher.DeliverDue(ctx, at.UTC()); err != nil {
+				logger.Error("could not deliver webhooks", "error", err)
+			}
+		}
+	}
+}
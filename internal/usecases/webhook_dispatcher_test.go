@@ -0,0 +1,214 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"simple-order-service/internal/domain"
+	"simple-order-service/internal/usecases"
+	"sync"
+	"testing"
+	"time"
+)
+
+type senderFunc func(url string, payload []byte, headers map[string]string) error
+
+func (send senderFunc) Send(url string, payload []byte, headers map[string]string) error {
+	return send(url, payload, headers)
+}
+
+func TestFanOut_QueuesDeliveriesForSubscribedWebhooks(t *testing.T) {
+	now := time.Now()
+	webhookRepoMock := &domain.WebhookRepositoryMock{
+		GetAllFunc: func(ctx context.Context) []domain.Webhook {
+			return []domain.Webhook{
+				domain.NewWebhook("all", "https://example.com/all", "secret", nil, now),
+				domain.NewWebhook("status", "https://example.com/status", "secret", []domain.OrderEventType{domain.OrderStatusChanged}, now),
+			}
+		},
+	}
+	outboxRepoMock := &domain.OutboxRepositoryMock{
+		PendingFunc: func(ctx context.Context, limit int) []domain.OutboxMessage {
+			return []domain.OutboxMessage{{ID: "1", Event: domain.OrderEvent{OrderID: "1", Type: domain.OrderCreated}}}
+		},
+		StoreDeliveryFunc: func(ctx context.Context, delivery domain.WebhookDelivery) error {
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, id string) error {
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Webhooks: webhookRepoMock, Outbox: outboxRepoMock})
+
+	dispatcher := usecases.NewWebhookDispatcher(unitOfWorkMock, nil, time.Second, 3, time.Second, time.Minute, 4)
+	if err := dispatcher.FanOut(context.Background(), now); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	deliveries := outboxRepoMock.StoreDeliveryCalls()
+	if len(deliveries) != 1 || deliveries[0].Delivery.WebhookID != "all" {
+		t.Errorf("Got: %v, Want: one delivery to webhook all", deliveries)
+	}
+	if len(outboxRepoMock.DeleteCalls()) != 1 {
+		t.Errorf("Got: %v, Want: %v", len(outboxRepoMock.DeleteCalls()), 1)
+	}
+}
+
+func TestDeliverDue_SignsPayload(t *testing.T) {
+	now := time.Now()
+	webhook := domain.NewWebhook("1", "https://example.com/hooks", "secret", nil, now)
+	webhookRepoMock := &domain.WebhookRepositoryMock{
+		GetAllFunc: func(ctx context.Context) []domain.Webhook {
+			return []domain.Webhook{webhook}
+		},
+	}
+	outboxRepoMock := &domain.OutboxRepositoryMock{
+		DueDeliveriesFunc: func(ctx context.Context, at time.Time, limit int) []domain.WebhookDelivery {
+			return []domain.WebhookDelivery{domain.NewWebhookDelivery("1", domain.OutboxMessage{ID: "7", Event: domain.OrderEvent{Type: domain.OrderCreated}}, now)}
+		},
+		DeleteDeliveryFunc: func(ctx context.Context, id string) error {
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Webhooks: webhookRepoMock, Outbox: outboxRepoMock})
+
+	var headers map[string]string
+	var payload []byte
+	sender := senderFunc(func(url string, body []byte, sent map[string]string) error {
+		payload, headers = body, sent
+		return nil
+	})
+
+	dispatcher := usecases.NewWebhookDispatcher(unitOfWorkMock, sender, time.Second, 3, time.Second, time.Minute, 4)
+	delivered, failed, err := dispatcher.DeliverDue(context.Background(), now)
+	if err != nil || delivered != 1 || failed != 0 {
+		t.Fatalf("Got: %v, %v, %v, Want: 1, 0, %v", delivered, failed, err, nil)
+	}
+
+	if want := "sha256=" + webhook.Sign(payload); headers[usecases.WebhookSignatureHeader] != want {
+		t.Errorf("Got: %v, Want: %v", headers[usecases.WebhookSignatureHeader], want)
+	}
+	if headers[usecases.WebhookEventHeader] != string(domain.OrderCreated) {
+		t.Errorf("Got: %v, Want: %v", headers[usecases.WebhookEventHeader], domain.OrderCreated)
+	}
+	if len(outboxRepoMock.DeleteDeliveryCalls()) != 1 {
+		t.Errorf("Got: %v, Want: %v", len(outboxRepoMock.DeleteDeliveryCalls()), 1)
+	}
+}
+
+func TestDeliverDue_RetriesThenDeadLetters(t *testing.T) {
+	now := time.Now()
+	delivery := domain.NewWebhookDelivery("1", domain.OutboxMessage{ID: "7", Event: domain.OrderEvent{Type: domain.OrderCreated}}, now)
+	webhookRepoMock := &domain.WebhookRepositoryMock{
+		GetAllFunc: func(ctx context.Context) []domain.Webhook {
+			return []domain.Webhook{domain.NewWebhook("1", "https://example.com/hooks", "secret", nil, now)}
+		},
+	}
+	outboxRepoMock := &domain.OutboxRepositoryMock{
+		DueDeliveriesFunc: func(ctx context.Context, at time.Time, limit int) []domain.WebhookDelivery {
+			return []domain.WebhookDelivery{delivery}
+		},
+		StoreDeliveryFunc: func(ctx context.Context, stored domain.WebhookDelivery) error {
+			delivery = stored
+			return nil
+		},
+		DeleteDeliveryFunc: func(ctx context.Context, id string) error {
+			return nil
+		},
+		StoreDeadLetterFunc: func(ctx context.Context, deadLetter domain.WebhookDelivery) error {
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Webhooks: webhookRepoMock, Outbox: outboxRepoMock})
+	sender := senderFunc(func(url string, body []byte, headers map[string]string) error {
+		return errors.New("webhook responded with status 500")
+	})
+
+	dispatcher := usecases.NewWebhookDispatcher(unitOfWorkMock, sender, time.Second, 2, time.Second, time.Minute, 4)
+	if _, failed, err := dispatcher.DeliverDue(context.Background(), now); err != nil || failed != 1 {
+		t.Fatalf("Got: %v, %v, Want: 1, %v", failed, err, nil)
+	}
+	if delivery.Attempts != 1 || !delivery.NextAttemptAt.Equal(now.Add(time.Second)) {
+		t.Errorf("Got: %v attempts, next at %v, Want: 1 attempt, next at %v", delivery.Attempts, delivery.NextAttemptAt, now.Add(time.Second))
+	}
+	if len(outboxRepoMock.StoreDeadLetterCalls()) != 0 {
+		t.Errorf("Got: %v, Want: %v", len(outboxRepoMock.StoreDeadLetterCalls()), 0)
+	}
+
+	if _, _, err := dispatcher.DeliverDue(context.Background(), now); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	deadLetters := outboxRepoMock.StoreDeadLetterCalls()
+	if len(deadLetters) != 1 || deadLetters[0].Delivery.Attempts != 2 {
+		t.Errorf("Got: %v, Want: one dead letter after 2 attempts", deadLetters)
+	}
+}
+
+func TestDeliverDue_DoesNotHoldUpWebhooksBehindASlowOne(t *testing.T) {
+	now := time.Now()
+	delivery := func(id, webhookID string) domain.WebhookDelivery {
+		delivery := domain.NewWebhookDelivery(webhookID, domain.OutboxMessage{ID: id, Event: domain.OrderEvent{Type: domain.OrderCreated}}, now)
+		delivery.ID = id
+		return delivery
+	}
+	webhookRepoMock := &domain.WebhookRepositoryMock{
+		GetAllFunc: func(ctx context.Context) []domain.Webhook {
+			return []domain.Webhook{
+				domain.NewWebhook("slow", "https://example.com/slow", "secret", nil, now),
+				domain.NewWebhook("fast", "https://example.com/fast", "secret", nil, now),
+			}
+		},
+	}
+	outboxRepoMock := &domain.OutboxRepositoryMock{
+		DueDeliveriesFunc: func(ctx context.Context, at time.Time, limit int) []domain.WebhookDelivery {
+			return []domain.WebhookDelivery{delivery("1", "slow"), delivery("2", "slow"), delivery("3", "fast"), delivery("4", "fast")}
+		},
+		StoreDeliveryFunc: func(ctx context.Context, stored domain.WebhookDelivery) error {
+			return nil
+		},
+		DeleteDeliveryFunc: func(ctx context.Context, id string) error {
+			return nil
+		},
+	}
+	unitOfWorkMock := newUnitOfWorkMock(domain.Repositories{Webhooks: webhookRepoMock, Outbox: outboxRepoMock})
+
+	// The slow webhook times out once the fast one has been delivered everything.
+	var mu sync.Mutex
+	sent := make([]string, 0)
+	fastDone := make(chan struct{})
+	heldUp := false
+	sender := senderFunc(func(url string, body []byte, headers map[string]string) error {
+		mu.Lock()
+		sent = append(sent, headers[usecases.WebhookDeliveryHeader])
+		mu.Unlock()
+		if url == "https://example.com/slow" {
+			select {
+			case <-fastDone:
+			case <-time.After(time.Second):
+				heldUp = true
+			}
+			return errors.New("webhook timed out")
+		}
+		if headers[usecases.WebhookDeliveryHeader] == "4" {
+			close(fastDone)
+		}
+		return nil
+	})
+
+	dispatcher := usecases.NewWebhookDispatcher(unitOfWorkMock, sender, time.Second, 3, time.Second, time.Minute, 2)
+	delivered, failed, err := dispatcher.DeliverDue(context.Background(), now)
+	if err != nil || delivered != 2 || failed != 1 {
+		t.Fatalf("Got: %v, %v, %v, Want: 2, 1, %v", delivered, failed, err, nil)
+	}
+	if heldUp {
+		t.Errorf("Got: the fast webhook held up behind the slow one, Want: both delivered to at once")
+	}
+	// The second delivery to the slow webhook is left due once the first one has failed.
+	for _, id := range sent {
+		if id == "2" {
+			t.Errorf("Got: %v sent, Want: delivery 2 left due", sent)
+		}
+	}
+	if stored := outboxRepoMock.StoreDeliveryCalls(); len(stored) != 1 || stored[0].Delivery.ID != "1" {
+		t.Errorf("Got: %v, Want: the failed delivery 1 stored for a retry", stored)
+	}
+}
@@ -1,61 +1,386 @@
 package database
 
 import (
+	"bytes"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
 )
 
+// Store is implemented by both DB and Tx so that repositories can be used either on their own,
+// where every call runs in its own transaction, or as part of a larger unit of work.
+type Store interface {
+	Tx(fn func(tx *Tx) error) error
+	Put(schema, key, value []byte) error
+	Update(schema, key []byte, fn func(current []byte) ([]byte, error)) error
+	Get(schema, key []byte) []byte
+	GetAll(schema []byte) [][]byte
+	Scan(schema, prefix []byte) [][]byte
+	NextSequence(schema []byte) (uint64, error)
+	Delete(schema, key []byte) error
+	Page(schema []byte, query PageQuery, match func(value []byte) bool) (vals [][]byte, next []byte)
+}
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrMissingBucket = func(schema []byte) error {
+		return fmt.Errorf("bucket %s does not exist", schema)
+	}
+)
+
+// PageQuery selects up to Limit values in key order, starting after the key After. A nil After
+// starts from the first key, or from the last key when Descending is set.
+type PageQuery struct {
+	After      []byte
+	Limit      int
+	Descending bool
+}
+
+// EncodeCursor turns the key returned as the next page of a query into an opaque cursor.
+func EncodeCursor(key []byte) string {
+	if key == nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+func DecodeCursor(cursor string) ([]byte, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return key, nil
+}
+
 type DB struct {
-	client *bolt.DB
+	client  *bolt.DB
+	observe func(mode string, duration time.Duration)
+
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]bool
 }
 
 func NewInstance(path string) (*DB, error) {
-	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	return Open(path, 1*time.Second)
+}
+
+// Open opens the database file at path, waiting up to timeout for another process to release its
+// lock on the file.
+func Open(path string, timeout time.Duration) (*DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: timeout})
 	if err != nil {
 		return nil, err
 	}
-	return &DB{client: db}, nil
+	return &DB{client: db, watchers: make(map[string]map[chan struct{}]bool)}, nil
 }
 
-func (db *DB) Put(schema, key, value []byte) error {
+// ObserveTransactions has observe called with the duration of every transaction, along with its
+// mode, read or write. It is meant to be called before the database is used.
+func (db *DB) ObserveTransactions(observe func(mode string, duration time.Duration)) {
+	db.observe = observe
+}
+
+func (db *DB) observeTransaction(mode string, start time.Time) {
+	if db.observe != nil {
+		db.observe(mode, time.Since(start))
+	}
+}
+
+// Close waits for the running transactions to finish, then releases the lock on the database file.
+func (db *DB) Close() error {
+	return db.client.Close()
+}
+
+// Tx runs fn inside a single read-write transaction. All the writes made through tx are committed
+// together if fn returns nil, and are rolled back otherwise. The watchers of the buckets written by
+// the transaction are notified once it is committed.
+func (db *DB) Tx(fn func(tx *Tx) error) error {
+	start := time.Now()
+	written := make(map[string]bool)
 	err := db.client.Update(func(tx *bolt.Tx) error {
-		b, err := tx.CreateBucketIfNotExists(schema)
-		if err != nil {
-			return err
-		}
-		err = b.Put(key, value)
+		return fn(&Tx{tx: tx, written: written})
+	})
+	db.observeTransaction("write", start)
+	if err != nil {
 		return err
+	}
+	db.notify(written)
+	return nil
+}
+
+// View runs fn inside a single read-only transaction. The writes attempted through tx fail with the
+// error of bbolt, and notify no watcher.
+func (db *DB) View(fn func(tx *Tx) error) error {
+	start := time.Now()
+	defer db.observeTransaction("read", start)
+	return db.client.View(func(tx *bolt.Tx) error {
+		return fn(&Tx{tx: tx, written: make(map[string]bool)})
+	})
+}
+
+// Check verifies that the database can be read, and that the given buckets exist.
+func (db *DB) Check(schemas ...[]byte) error {
+	return db.View(func(tx *Tx) error {
+		for _, schema := range schemas {
+			if tx.tx.Bucket(schema) == nil {
+				return ErrMissingBucket(schema)
+			}
+		}
+		return nil
+	})
+}
+
+// Watch returns a channel which receives a value after every committed transaction which wrote to
+// the bucket. Notifications are coalesced: a watcher which is busy when several transactions are
+// committed receives a single value. The returned function stops the notifications.
+func (db *DB) Watch(schema []byte) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.watchers[string(schema)] == nil {
+		db.watchers[string(schema)] = make(map[chan struct{}]bool)
+	}
+	db.watchers[string(schema)][ch] = true
+
+	return ch, func() {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		delete(db.watchers[string(schema)], ch)
+	}
+}
+
+func (db *DB) notify(written map[string]bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for schema := range written {
+		for ch := range db.watchers[schema] {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (db *DB) Put(schema, key, value []byte) error {
+	return db.Tx(func(tx *Tx) error {
+		return tx.Put(schema, key, value)
+	})
+}
+
+func (db *DB) Update(schema, key []byte, fn func(current []byte) ([]byte, error)) error {
+	return db.Tx(func(tx *Tx) error {
+		return tx.Update(schema, key, fn)
 	})
-	return err
 }
 
 func (db *DB) Get(schema, key []byte) []byte {
 	var val []byte
-	db.client.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(schema)
-		if b == nil {
-			return errors.New("bucket does not exist")
-		}
-		val = b.Get(key)
+	db.View(func(tx *Tx) error {
+		val = tx.Get(schema, key)
 		return nil
 	})
 	return val
 }
 
 func (db *DB) GetAll(schema []byte) [][]byte {
+	var vals [][]byte
+	db.View(func(tx *Tx) error {
+		vals = tx.GetAll(schema)
+		return nil
+	})
+	return vals
+}
+
+func (db *DB) Scan(schema, prefix []byte) [][]byte {
+	var vals [][]byte
+	db.View(func(tx *Tx) error {
+		vals = tx.Scan(schema, prefix)
+		return nil
+	})
+	return vals
+}
+
+func (db *DB) NextSequence(schema []byte) (uint64, error) {
+	var seq uint64
+	err := db.Tx(func(tx *Tx) error {
+		var err error
+		seq, err = tx.NextSequence(schema)
+		return err
+	})
+	return seq, err
+}
+
+func (db *DB) Delete(schema, key []byte) error {
+	return db.Tx(func(tx *Tx) error {
+		return tx.Delete(schema, key)
+	})
+}
+
+func (db *DB) Page(schema []byte, query PageQuery, match func(value []byte) bool) ([][]byte, []byte) {
+	var vals [][]byte
+	var next []byte
+	db.View(func(tx *Tx) error {
+		vals, next = tx.Page(schema, query, match)
+		return nil
+	})
+	return vals, next
+}
+
+type Tx struct {
+	tx      *bolt.Tx
+	written map[string]bool
+}
+
+// Tx runs fn as part of the transaction, so that code written against a Store can group several
+// writes whether it is given a DB or a Tx.
+func (tx *Tx) Tx(fn func(tx *Tx) error) error {
+	return fn(tx)
+}
+
+func (tx *Tx) Put(schema, key, value []byte) error {
+	b, err := tx.tx.CreateBucketIfNotExists(schema)
+	if err != nil {
+		return err
+	}
+	tx.written[string(schema)] = true
+	return b.Put(key, value)
+}
+
+// Update replaces the value stored against the key with the value returned by fn, which is given the
+// current value, or nil if there is none. Reading and writing the value happen in the same
+// transaction, so fn can safely reject the write by returning an error.
+func (tx *Tx) Update(schema, key []byte, fn func(current []byte) ([]byte, error)) error {
+	b, err := tx.tx.CreateBucketIfNotExists(schema)
+	if err != nil {
+		return err
+	}
+	value, err := fn(copyBytes(b.Get(key)))
+	if err != nil {
+		return err
+	}
+	tx.written[string(schema)] = true
+	return b.Put(key, value)
+}
+
+// Get returns a copy of the value stored against the key, since the memory backing the value is
+// only valid for the lifetime of the transaction.
+func (tx *Tx) Get(schema, key []byte) []byte {
+	b := tx.tx.Bucket(schema)
+	if b == nil {
+		return nil
+	}
+	return copyBytes(b.Get(key))
+}
+
+func (tx *Tx) GetAll(schema []byte) [][]byte {
 	vals := make([][]byte, 0)
-	db.client.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(schema)
-		if b == nil {
-			return errors.New("bucket does not exist")
-		}
-		b.ForEach(func(_, v []byte) error {
-			vals = append(vals, v)
-			return nil
-		})
+	b := tx.tx.Bucket(schema)
+	if b == nil {
+		return vals
+	}
+	b.ForEach(func(_, v []byte) error {
+		vals = append(vals, copyBytes(v))
 		return nil
 	})
 	return vals
 }
+
+// Scan returns, in key order, the values whose keys start with the given prefix.
+func (tx *Tx) Scan(schema, prefix []byte) [][]byte {
+	vals := make([][]byte, 0)
+	b := tx.tx.Bucket(schema)
+	if b == nil {
+		return vals
+	}
+	c := b.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		vals = append(vals, copyBytes(v))
+	}
+	return vals
+}
+
+// NextSequence returns the next number of an increasing sequence kept for the bucket, which starts
+// at 1. The sequence only moves forward if the transaction is committed.
+func (tx *Tx) NextSequence(schema []byte) (uint64, error) {
+	b, err := tx.tx.CreateBucketIfNotExists(schema)
+	if err != nil {
+		return 0, err
+	}
+	return b.NextSequence()
+}
+
+func (tx *Tx) Delete(schema, key []byte) error {
+	b := tx.tx.Bucket(schema)
+	if b == nil {
+		return nil
+	}
+	tx.written[string(schema)] = true
+	return b.Delete(key)
+}
+
+// Page walks the bucket with a cursor and returns the values for which match returns true, along with
+// the key to pass as After to read the next page. The next key is nil when there are no more
+// matching values.
+func (tx *Tx) Page(schema []byte, query PageQuery, match func(value []byte) bool) ([][]byte, []byte) {
+	vals := make([][]byte, 0)
+	b := tx.tx.Bucket(schema)
+	if b == nil {
+		return vals, nil
+	}
+
+	c := b.Cursor()
+	step := c.Next
+	if query.Descending {
+		step = c.Prev
+	}
+
+	var k, v []byte
+	switch {
+	case query.After == nil && query.Descending:
+		k, v = c.Last()
+	case query.After == nil:
+		k, v = c.First()
+	default:
+		k, v = c.Seek(query.After)
+		if query.Descending {
+			if k == nil {
+				k, v = c.Last()
+			}
+			if k != nil && bytes.Compare(k, query.After) >= 0 {
+				k, v = c.Prev()
+			}
+		} else if k != nil && bytes.Equal(k, query.After) {
+			k, v = c.Next()
+		}
+	}
+
+	var lastKey []byte
+	for ; k != nil; k, v = step() {
+		if match != nil && !match(v) {
+			continue
+		}
+		if len(vals) == query.Limit {
+			return vals, lastKey
+		}
+		vals = append(vals, copyBytes(v))
+		lastKey = copyBytes(k)
+	}
+	return vals, nil
+}
+
+func copyBytes(src []byte) []byte {
+	if src == nil {
+		return nil
+	}
+	dst := make([]byte, len(src))
+	copy(dst, src)
+	return dst
+}
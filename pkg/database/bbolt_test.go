@@ -0,0 +1,247 @@
+package database_test
+
+import (
+	"errors"
+	"path/filepath"
+	"simple-order-service/pkg/database"
+	"testing"
+	"time"
+)
+
+func newTestDB(t *testing.T) *database.DB {
+	db, err := database.NewInstance(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		db.Put([]byte("items"), []byte(key), []byte(key))
+	}
+	return db
+}
+
+func readPages(db *database.DB, query database.PageQuery, match func(value []byte) bool) []string {
+	got := make([]string, 0)
+	for {
+		vals, next := db.Page([]byte("items"), query, match)
+		for _, val := range vals {
+			got = append(got, string(val))
+		}
+		if next == nil {
+			return got
+		}
+		query.After = next
+	}
+}
+
+func assertValues(t *testing.T, got []string, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Got: %v, Want: %v", got, want)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Fatalf("Got: %v, Want: %v", got, want)
+		}
+	}
+}
+
+func TestPage_Ascending(t *testing.T) {
+	db := newTestDB(t)
+
+	vals, next := db.Page([]byte("items"), database.PageQuery{Limit: 2}, nil)
+	assertValues(t, []string{string(vals[0]), string(vals[1])}, []string{"a", "b"})
+	if string(next) != "b" {
+		t.Errorf("Got: %s, Want: %s", next, "b")
+	}
+
+	assertValues(t, readPages(db, database.PageQuery{Limit: 2}, nil), []string{"a", "b", "c", "d", "e"})
+}
+
+func TestPage_Descending(t *testing.T) {
+	db := newTestDB(t)
+
+	assertValues(t, readPages(db, database.PageQuery{Limit: 2, Descending: true}, nil), []string{"e", "d", "c", "b", "a"})
+}
+
+func TestPage_WithFilter(t *testing.T) {
+	db := newTestDB(t)
+	vowels := func(value []byte) bool {
+		return string(value) == "a" || string(value) == "e"
+	}
+
+	vals, next := db.Page([]byte("items"), database.PageQuery{Limit: 2}, vowels)
+	if len(vals) != 2 || next != nil {
+		t.Errorf("Got: %d values and next %s, Want: %d values and no next page", len(vals), next, 2)
+	}
+}
+
+func TestPage_LastPageHasNoNextCursor(t *testing.T) {
+	db := newTestDB(t)
+
+	_, next := db.Page([]byte("items"), database.PageQuery{Limit: 5}, nil)
+	if next != nil {
+		t.Errorf("Got: %s, Want: %v", next, nil)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	key, err := database.DecodeCursor(database.EncodeCursor([]byte("order-1")))
+	if err != nil || string(key) != "order-1" {
+		t.Errorf("Got: %s, %v, Want: %s", key, err, "order-1")
+	}
+
+	if _, err := database.DecodeCursor("not a cursor!"); err != database.ErrInvalidCursor {
+		t.Errorf("Got: %v, Want: %v", err, database.ErrInvalidCursor)
+	}
+}
+
+func TestTx_RollsBackOnError(t *testing.T) {
+	db := newTestDB(t)
+
+	db.Tx(func(tx *database.Tx) error {
+		tx.Put([]byte("items"), []byte("f"), []byte("f"))
+		return database.ErrInvalidCursor
+	})
+
+	if got := db.Get([]byte("items"), []byte("f")); got != nil {
+		t.Errorf("Got: %s, Want: %v", got, nil)
+	}
+}
+
+func TestView_RejectsWrites(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.View(func(tx *database.Tx) error {
+		if err := tx.Put([]byte("items"), []byte("f"), []byte("f")); err == nil {
+			t.Errorf("Got: %v, Want: the put rejected", err)
+		}
+		if err := tx.Update([]byte("items"), []byte("a"), func(current []byte) ([]byte, error) { return []byte("z"), nil }); err == nil {
+			t.Errorf("Got: %v, Want: the update rejected", err)
+		}
+		if err := tx.Delete([]byte("items"), []byte("a")); err == nil {
+			t.Errorf("Got: %v, Want: the delete rejected", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Got: %v, Want: %v", err, nil)
+	}
+	if got := db.Get([]byte("items"), []byte("a")); string(got) != "a" {
+		t.Errorf("Got: %s, Want: %s", got, "a")
+	}
+}
+
+func TestUpdate_RejectedWriteKeepsCurrentValue(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.Update([]byte("items"), []byte("a"), func(current []byte) ([]byte, error) {
+		if string(current) != "a" {
+			t.Errorf("Got: %s, Want: %s", current, "a")
+		}
+		return nil, database.ErrInvalidCursor
+	})
+
+	if err != database.ErrInvalidCursor {
+		t.Errorf("Got: %v, Want: %v", err, database.ErrInvalidCursor)
+	}
+	if got := db.Get([]byte("items"), []byte("a")); string(got) != "a" {
+		t.Errorf("Got: %s, Want: %s", got, "a")
+	}
+}
+
+func TestScan_ReturnsKeysWithPrefix(t *testing.T) {
+	db := newTestDB(t)
+	db.Put([]byte("items"), []byte("ab"), []byte("ab"))
+	db.Put([]byte("items"), []byte("ac"), []byte("ac"))
+
+	var got []string
+	for _, val := range db.Scan([]byte("items"), []byte("a")) {
+		got = append(got, string(val))
+	}
+	assertValues(t, got, []string{"a", "ab", "ac"})
+}
+
+func TestWatch_NotifiedAfterCommit(t *testing.T) {
+	db := newTestDB(t)
+	notifications, stop := db.Watch([]byte("items"))
+	defer stop()
+
+	db.Tx(func(tx *database.Tx) error {
+		tx.Put([]byte("items"), []byte("f"), []byte("f"))
+		return errors.New("rolled back")
+	})
+	select {
+	case <-notifications:
+		t.Fatal("Got: notification for a rolled back transaction, Want: none")
+	default:
+	}
+
+	db.Put([]byte("other"), []byte("a"), []byte("a"))
+	db.Put([]byte("items"), []byte("f"), []byte("f"))
+	db.Put([]byte("items"), []byte("g"), []byte("g"))
+	select {
+	case <-notifications:
+	default:
+		t.Fatal("Got: no notification, Want: one")
+	}
+	select {
+	case <-notifications:
+		t.Fatal("Got: second notification, Want: notifications coalesced")
+	default:
+	}
+}
+
+func TestClose_ReleasesFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := database.Open(path, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Put([]byte("items"), []byte("a"), []byte("a"))
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+
+	reopened, err := database.Open(path, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Got: %v, Want: %v", err, nil)
+	}
+	defer reopened.Close()
+	if got := string(reopened.Get([]byte("items"), []byte("a"))); got != "a" {
+		t.Errorf("Got: %v, Want: %v", got, "a")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.Check([]byte("items")); err != nil {
+		t.Errorf("Got: %v, Want: %v", err, nil)
+	}
+	if err := db.Check([]byte("items"), []byte("missing")); err == nil {
+		t.Errorf("Got: %v, Want: %v", err, database.ErrMissingBucket([]byte("missing")))
+	}
+
+	db.Close()
+	if err := db.Check(); err == nil {
+		t.Errorf("Got: %v, Want: an error for a closed database", err)
+	}
+}
+
+func TestObserveTransactions_ReportsTheModeOfEveryTransaction(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	modes := make([]string, 0)
+	db.ObserveTransactions(func(mode string, duration time.Duration) {
+		modes = append(modes, mode)
+	})
+
+	db.Put([]byte("items"), []byte("f"), []byte("f"))
+	db.Get([]byte("items"), []byte("f"))
+
+	if len(modes) != 2 || modes[0] != "write" || modes[1] != "read" {
+		t.Errorf("Got: %v, Want: %v", modes, []string{"write", "read"})
+	}
+}
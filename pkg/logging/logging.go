@@ -0,0 +1,205 @@
+// Package logging writes structured logs as one JSON object per line. A logger carries fields which
+// are added to every entry it writes, and travels with a request in its context.Context.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var ErrInvalidLevel = errors.New("invalid log level. the different levels are: 'debug', 'info', 'warn' and 'error'")
+
+func (level Level) String() string {
+	switch level {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	}
+	return "error"
+}
+
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelError, ErrInvalidLevel
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+// output is shared by a logger and the loggers derived from it, so that entries written
+// concurrently are not interleaved.
+type output struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+}
+
+type Logger struct {
+	out    *output
+	fields []field
+}
+
+func New(w io.Writer, level Level) *Logger {
+	return &Logger{out: &output{w: w, level: level}}
+}
+
+var defaultLogger = New(os.Stderr, LevelInfo)
+
+// Default is the logger of the requests whose context carries none, and of the background workers.
+func Default() *Logger {
+	return defaultLogger
+}
+
+func SetDefault(logger *Logger) {
+	defaultLogger = logger
+}
+
+// With returns a logger which adds the given key and value pairs to every entry.
+func (logger *Logger) With(keyvals ...interface{}) *Logger {
+	fields := make([]field, len(logger.fields), len(logger.fields)+len(keyvals)/2)
+	copy(fields, logger.fields)
+	return &Logger{out: logger.out, fields: appendFields(fields, keyvals)}
+}
+
+func (logger *Logger) Debug(msg string, keyvals ...interface{}) {
+	logger.log(LevelDebug, msg, keyvals)
+}
+
+func (logger *Logger) Info(msg string, keyvals ...interface{}) {
+	logger.log(LevelInfo, msg, keyvals)
+}
+
+func (logger *Logger) Warn(msg string, keyvals ...interface{}) {
+	logger.log(LevelWarn, msg, keyvals)
+}
+
+func (logger *Logger) Error(msg string, keyvals ...interface{}) {
+	logger.log(LevelError, msg, keyvals)
+}
+
+func (logger *Logger) log(level Level, msg string, keyvals []interface{}) {
+	if level < logger.out.level {
+		return
+	}
+	fields := appendFields(append([]field(nil), logger.fields...), keyvals)
+
+	var entry strings.Builder
+	entry.WriteString(`{"time":`)
+	writeJSON(&entry, time.Now().UTC().Format(time.RFC3339Nano))
+	entry.WriteString(`,"level":`)
+	writeJSON(&entry, level.String())
+	entry.WriteString(`,"msg":`)
+	writeJSON(&entry, msg)
+	for _, f := range fields {
+		entry.WriteString(",")
+		writeJSON(&entry, f.key)
+		entry.WriteString(":")
+		writeJSON(&entry, f.value)
+	}
+	entry.WriteString("}\n")
+
+	logger.out.mu.Lock()
+	defer logger.out.mu.Unlock()
+	io.WriteString(logger.out.w, entry.String())
+}
+
+// appendFields adds the key and value pairs to the fields. A key which is already set is given the
+// new value, so that an entry never repeats a key.
+func appendFields(fields []field, keyvals []interface{}) []field {
+next:
+	for idx := 0; idx < len(keyvals); idx += 2 {
+		key := fmt.Sprint(keyvals[idx])
+		var value interface{} = "MISSING"
+		if idx+1 < len(keyvals) {
+			value = keyvals[idx+1]
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		for existing := range fields {
+			if fields[existing].key == key {
+				fields[existing].value = value
+				continue next
+			}
+		}
+		fields = append(fields, field{key: key, value: value})
+	}
+	return fields
+}
+
+func writeJSON(entry *strings.Builder, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	entry.Write(data)
+}
+
+type contextKey struct{}
+
+// NewContext returns a context which carries the logger.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by the context, or the default logger.
+func FromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return logger
+	}
+	return Default()
+}
+
+// With returns a context whose logger adds the given key and value pairs to every entry, such as the
+// id of the order a request is about.
+func With(ctx context.Context, keyvals ...interface{}) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(keyvals...))
+}
+
+// Writer returns a writer which logs every line written to it at the given level, so that the
+// standard log package writes JSON logs too.
+func (logger *Logger) Writer(level Level) io.Writer {
+	return lineWriter{logger: logger, level: level}
+}
+
+type lineWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (w lineWriter) Write(data []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		w.logger.log(w.level, line, nil)
+	}
+	return len(data), nil
+}
@@ -0,0 +1,91 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"simple-order-service/pkg/logging"
+	"strings"
+	"testing"
+)
+
+func decodeEntries(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	entries := make([]map[string]interface{}, 0)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Got: %q, Want: a JSON object", line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogger_WritesJSONWithContextFields(t *testing.T) {
+	var out bytes.Buffer
+	logger := logging.New(&out, logging.LevelInfo)
+
+	ctx := logging.NewContext(context.Background(), logger.With("request_id", "abc"))
+	ctx = logging.With(ctx, "order_id", "1")
+	logging.FromContext(ctx).Warn("could not add product", "product_id", "123", "error", errors.New("out of stock"))
+
+	entries := decodeEntries(t, &out)
+	if len(entries) != 1 {
+		t.Fatalf("Got: %v entries, Want: %v", len(entries), 1)
+	}
+	want := map[string]interface{}{
+		"level":      "warn",
+		"msg":        "could not add product",
+		"request_id": "abc",
+		"order_id":   "1",
+		"product_id": "123",
+		"error":      "out of stock",
+	}
+	for key, value := range want {
+		if entries[0][key] != value {
+			t.Errorf("%s: Got: %v, Want: %v", key, entries[0][key], value)
+		}
+	}
+}
+
+func TestLogger_ReplacesRepeatedKeys(t *testing.T) {
+	var out bytes.Buffer
+	logger := logging.New(&out, logging.LevelInfo).With("order_id", "1")
+
+	logger.With("order_id", "2").Info("stored order", "order_id", "3")
+
+	if got := strings.Count(out.String(), `"order_id"`); got != 1 {
+		t.Fatalf("Got: %v order_id keys in %q, Want: %v", got, out.String(), 1)
+	}
+	if got := decodeEntries(t, &out)[0]["order_id"]; got != "3" {
+		t.Errorf("Got: %v, Want: %v", got, "3")
+	}
+}
+
+func TestLogger_SkipsEntriesBelowLevel(t *testing.T) {
+	var out bytes.Buffer
+	logger := logging.New(&out, logging.LevelWarn)
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error")
+
+	entries := decodeEntries(t, &out)
+	if len(entries) != 1 || entries[0]["msg"] != "error" {
+		t.Errorf("Got: %v, Want: only the error entry", entries)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if level, err := logging.ParseLevel("DEBUG"); err != nil || level != logging.LevelDebug {
+		t.Errorf("Got: %v, %v, Want: %v, %v", level, err, logging.LevelDebug, nil)
+	}
+	if _, err := logging.ParseLevel("verbose"); !errors.Is(err, logging.ErrInvalidLevel) {
+		t.Errorf("Got: %v, Want: %v", err, logging.ErrInvalidLevel)
+	}
+}
@@ -0,0 +1,273 @@
+// Package metrics keeps counters, gauges and histograms in memory and writes them in the Prometheus
+// text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of latency histograms.
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default is the registry written by the /metrics endpoint.
+var Default = NewRegistry()
+
+type collector interface {
+	familyName() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families, which are written in the order in which they were registered. A
+// family may only be registered once in a registry.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (registry *Registry) register(c collector) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, registered := range registry.collectors {
+		if registered.familyName() == c.familyName() {
+			panic(fmt.Sprintf("metric %s is already registered", c.familyName()))
+		}
+	}
+	registry.collectors = append(registry.collectors, c)
+}
+
+// Write writes every metric of the registry in the Prometheus text exposition format.
+func (registry *Registry) Write(w io.Writer) error {
+	registry.mu.Lock()
+	collectors := registry.collectors
+	registry.mu.Unlock()
+
+	buffered := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+// family is the name, help and labels shared by the series of a metric.
+type family struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+}
+
+func (f family) familyName() string {
+	return f.name
+}
+
+func (f family) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, strings.ReplaceAll(f.help, "\n", " "))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+}
+
+func (f family) labels(labelValues []string, extra ...string) string {
+	if len(f.labelNames) != len(labelValues) {
+		panic(fmt.Sprintf("metric %s has labels %v but was given values %v", f.name, f.labelNames, labelValues))
+	}
+	pairs := make([]string, 0, len(labelValues)+1)
+	for idx, name := range f.labelNames {
+		pairs = append(pairs, name+`="`+escapeLabelValue(labelValues[idx])+`"`)
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, extra[0]+`="`+escapeLabelValue(extra[1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// seriesKey joins label values with a byte which cannot appear in them unescaped.
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func sortedKeys[V any](series map[string]V) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func splitKey(key string, labelCount int) []string {
+	if labelCount == 0 {
+		return nil
+	}
+	return strings.Split(key, "\xff")
+}
+
+// CounterVec is a counter for every combination of label values.
+type CounterVec struct {
+	family
+	mu     sync.Mutex
+	series map[string]float64
+}
+
+func (registry *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	counter := &CounterVec{family: family{name: name, help: help, kind: "counter", labelNames: labelNames}, series: make(map[string]float64)}
+	registry.register(counter)
+	return counter
+}
+
+func (counter *CounterVec) Inc(labelValues ...string) {
+	counter.Add(1, labelValues...)
+}
+
+// Add increases the counter by value, which must not be negative.
+func (counter *CounterVec) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		return
+	}
+	counter.labels(labelValues)
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	counter.series[seriesKey(labelValues)] += value
+}
+
+func (counter *CounterVec) write(w *bufio.Writer) {
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	counter.writeHeader(w)
+	for _, key := range sortedKeys(counter.series) {
+		fmt.Fprintf(w, "%s%s %s\n", counter.name, counter.labels(splitKey(key, len(counter.labelNames))), formatValue(counter.series[key]))
+	}
+}
+
+// Sample is the value of one series of a gauge.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeFunc is a gauge whose samples are computed by collect every time the metrics are written.
+type GaugeFunc struct {
+	family
+	collect func() []Sample
+}
+
+func (registry *Registry) NewGaugeFunc(name, help string, labelNames []string, collect func() []Sample) *GaugeFunc {
+	gauge := &GaugeFunc{family: family{name: name, help: help, kind: "gauge", labelNames: labelNames}, collect: collect}
+	registry.register(gauge)
+	return gauge
+}
+
+func (gauge *GaugeFunc) write(w *bufio.Writer) {
+	samples := gauge.collect()
+	sort.Slice(samples, func(i, j int) bool {
+		return seriesKey(samples[i].LabelValues) < seriesKey(samples[j].LabelValues)
+	})
+	gauge.writeHeader(w)
+	for _, sample := range samples {
+		fmt.Fprintf(w, "%s%s %s\n", gauge.name, gauge.labels(sample.LabelValues), formatValue(sample.Value))
+	}
+}
+
+// Cached computes the samples of a gauge with collect at most once every ttl, and gives the samples
+// computed last in between, for gauges which are costly to compute.
+func Cached(ttl time.Duration, collect func() []Sample) func() []Sample {
+	var mu sync.Mutex
+	var samples []Sample
+	var collectedAt time.Time
+	return func() []Sample {
+		mu.Lock()
+		defer mu.Unlock()
+		if samples == nil || time.Since(collectedAt) >= ttl {
+			samples = collect()
+			collectedAt = time.Now()
+		}
+		return append([]Sample(nil), samples...)
+	}
+}
+
+// HistogramVec counts observations in buckets for every combination of label values.
+type HistogramVec struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec creates a histogram whose buckets have the given upper bounds, in increasing order.
+func (registry *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	hist := &HistogramVec{
+		family:  family{name: name, help: help, kind: "histogram", labelNames: labelNames},
+		buckets: buckets,
+		series:  make(map[string]*histogram),
+	}
+	registry.register(hist)
+	return hist
+}
+
+func (hist *HistogramVec) Observe(value float64, labelValues ...string) {
+	hist.labels(labelValues)
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+	key := seriesKey(labelValues)
+	series, ok := hist.series[key]
+	if !ok {
+		series = &histogram{counts: make([]uint64, len(hist.buckets))}
+		hist.series[key] = series
+	}
+	for idx, upperBound := range hist.buckets {
+		if value <= upperBound {
+			series.counts[idx] += 1
+		}
+	}
+	series.sum += value
+	series.count += 1
+}
+
+func (hist *HistogramVec) write(w *bufio.Writer) {
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+	hist.writeHeader(w)
+	for _, key := range sortedKeys(hist.series) {
+		labelValues := splitKey(key, len(hist.labelNames))
+		series := hist.series[key]
+		for idx, upperBound := range hist.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", hist.name, hist.labels(labelValues, "le", formatValue(upperBound)), series.counts[idx])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", hist.name, hist.labels(labelValues, "le", "+Inf"), series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", hist.name, hist.labels(labelValues), formatValue(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", hist.name, hist.labels(labelValues), series.count)
+	}
+}
@@ -0,0 +1,79 @@
+package metrics_test
+
+import (
+	"bytes"
+	"simple-order-service/pkg/metrics"
+	"testing"
+	"time"
+)
+
+func TestWrite_TextExpositionFormat(t *testing.T) {
+	registry := metrics.NewRegistry()
+	requests := registry.NewCounterVec("http_requests_total", "Requests handled.", "route", "code")
+	latency := registry.NewHistogramVec("http_request_duration_seconds", "Request latency.", []float64{0.1, 1}, "route")
+	registry.NewGaugeFunc("orders", "Orders by status.", []string{"status"}, func() []metrics.Sample {
+		return []metrics.Sample{{LabelValues: []string{"placed"}, Value: 2}, {LabelValues: []string{"open"}, Value: 1}}
+	})
+
+	requests.Inc("/orders/{id}", "200")
+	requests.Inc("/orders/{id}", "200")
+	requests.Inc(`/say"hi"`, "404")
+	latency.Observe(0.05, "/orders")
+	latency.Observe(0.5, "/orders")
+
+	var out bytes.Buffer
+	if err := registry.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP http_requests_total Requests handled.
+# TYPE http_requests_total counter
+http_requests_total{route="/orders/{id}",code="200"} 2
+http_requests_total{route="/say\"hi\"",code="404"} 1
+# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/orders",le="0.1"} 1
+http_request_duration_seconds_bucket{route="/orders",le="1"} 2
+http_request_duration_seconds_bucket{route="/orders",le="+Inf"} 2
+http_request_duration_seconds_sum{route="/orders"} 0.55
+http_request_duration_seconds_count{route="/orders"} 2
+# HELP orders Orders by status.
+# TYPE orders gauge
+orders{status="open"} 1
+orders{status="placed"} 2
+`
+	if out.String() != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", out.String(), want)
+	}
+}
+
+func TestCached_CollectsAtMostOnceEveryTTL(t *testing.T) {
+	collected := 0
+	samples := metrics.Cached(50*time.Millisecond, func() []metrics.Sample {
+		collected += 1
+		return []metrics.Sample{{Value: float64(collected)}}
+	})
+
+	if got := samples()[0].Value; got != 1 {
+		t.Errorf("Got: %v, Want: %v", got, 1)
+	}
+	if got := samples()[0].Value; got != 1 || collected != 1 {
+		t.Errorf("Got: %v after %d collections, Want: the cached samples", got, collected)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if got := samples()[0].Value; got != 2 {
+		t.Errorf("Got: %v, Want: %v", got, 2)
+	}
+}
+
+func TestRegistry_RejectsFamiliesRegisteredTwice(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.NewCounterVec("orders_total", "Orders created.")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Got: the family registered twice, Want: a panic")
+		}
+	}()
+	registry.NewGaugeFunc("orders_total", "Orders created.", nil, func() []metrics.Sample { return nil })
+}
@@ -0,0 +1,234 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError tells why the value of a field does not match its schema. Fields are named by their
+// path in the value, such as items[0].name; the value itself is named by the field given to the
+// validation.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (err FieldError) Error() string {
+	return err.Field + ": " + err.Reason
+}
+
+// DecodeJSON decodes a JSON value keeping its numbers as json.Number, which ValidateValue expects.
+func DecodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// ValidateValue returns every field of the decoded JSON value which does not match the schema.
+func (doc *Document) ValidateValue(schema *Schema, field string, value interface{}) []FieldError {
+	schema = doc.Schema(schema)
+	if schema == nil {
+		return nil
+	}
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return []FieldError{{Field: field, Reason: "must not be null"}}
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []FieldError{{Field: field, Reason: "must be an object"}}
+		}
+		return doc.validateObject(schema, field, object)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []FieldError{{Field: field, Reason: "must be an array"}}
+		}
+		errs := make([]FieldError, 0)
+		for idx, item := range array {
+			errs = append(errs, doc.ValidateValue(schema.Items, fmt.Sprintf("%s[%d]", field, idx), item)...)
+		}
+		return errs
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return []FieldError{{Field: field, Reason: "must be a string"}}
+		}
+		return validateString(schema, field, text)
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return []FieldError{{Field: field, Reason: "must be an integer"}}
+		}
+		integer, err := number.Int64()
+		if err != nil {
+			return []FieldError{{Field: field, Reason: "must be an integer"}}
+		}
+		return validateNumber(schema, field, float64(integer))
+	case "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			return []FieldError{{Field: field, Reason: "must be a number"}}
+		}
+		parsed, err := number.Float64()
+		if err != nil {
+			return []FieldError{{Field: field, Reason: "must be a number"}}
+		}
+		return validateNumber(schema, field, parsed)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []FieldError{{Field: field, Reason: "must be a boolean"}}
+		}
+	}
+	return nil
+}
+
+// ValidateParameter validates the raw value of a path or query parameter, after converting it to the
+// type of the schema of the parameter.
+func (doc *Document) ValidateParameter(parameter *Parameter, raw string) []FieldError {
+	schema := doc.Schema(parameter.Schema)
+	if schema == nil {
+		return nil
+	}
+	switch schema.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return []FieldError{{Field: parameter.Name, Reason: "must be an integer"}}
+		}
+		return doc.ValidateValue(schema, parameter.Name, json.Number(raw))
+	case "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return []FieldError{{Field: parameter.Name, Reason: "must be a number"}}
+		}
+		return doc.ValidateValue(schema, parameter.Name, json.Number(raw))
+	case "boolean":
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return []FieldError{{Field: parameter.Name, Reason: "must be true or false"}}
+		}
+		return doc.ValidateValue(schema, parameter.Name, parsed)
+	}
+	return doc.ValidateValue(schema, parameter.Name, raw)
+}
+
+func (doc *Document) validateObject(schema *Schema, field string, object map[string]interface{}) []FieldError {
+	errs := make([]FieldError, 0)
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			errs = append(errs, FieldError{Field: join(field, name), Reason: "is required"})
+		}
+	}
+
+	// Fields are checked in order, so that the errors are listed in the same order every time.
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				errs = append(errs, FieldError{Field: join(field, name), Reason: "is not a known field"})
+			}
+			continue
+		}
+		errs = append(errs, doc.ValidateValue(property, join(field, name), object[name])...)
+	}
+	return errs
+}
+
+func validateString(schema *Schema, field, text string) []FieldError {
+	if len(schema.Enum) > 0 {
+		for _, allowed := range schema.Enum {
+			if text == allowed {
+				return nil
+			}
+		}
+		return []FieldError{{Field: field, Reason: "must be one of " + strings.Join(quote(schema.Enum), ", ")}}
+	}
+	length := utf8.RuneCountInString(text)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		if *schema.MinLength == 1 {
+			return []FieldError{{Field: field, Reason: "must not be empty"}}
+		}
+		return []FieldError{{Field: field, Reason: fmt.Sprintf("must be at least %d characters long", *schema.MinLength)}}
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return []FieldError{{Field: field, Reason: fmt.Sprintf("must be at most %d characters long", *schema.MaxLength)}}
+	}
+
+	switch schema.Format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", text); err != nil {
+			return []FieldError{{Field: field, Reason: "must be a date such as 2006-01-02"}}
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			return []FieldError{{Field: field, Reason: "must be a time in RFC 3339 format, e.g. 2006-01-02T15:04:05Z"}}
+		}
+	case "email":
+		if address, err := mail.ParseAddress(text); err != nil || address.Address != text {
+			return []FieldError{{Field: field, Reason: "must be an email address"}}
+		}
+	case "uri":
+		if parsed, err := url.Parse(text); err != nil || !parsed.IsAbs() || parsed.Host == "" {
+			return []FieldError{{Field: field, Reason: "must be an absolute URL"}}
+		}
+	}
+	return nil
+}
+
+func validateNumber(schema *Schema, field string, number float64) []FieldError {
+	if schema.Minimum != nil {
+		if schema.ExclusiveMinimum && number <= *schema.Minimum {
+			return []FieldError{{Field: field, Reason: "must be greater than " + formatNumber(*schema.Minimum)}}
+		}
+		if number < *schema.Minimum {
+			return []FieldError{{Field: field, Reason: "must be at least " + formatNumber(*schema.Minimum)}}
+		}
+	}
+	if schema.Maximum != nil && number > *schema.Maximum {
+		return []FieldError{{Field: field, Reason: "must be at most " + formatNumber(*schema.Maximum)}}
+	}
+	return nil
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+func quote(values []string) []string {
+	quoted := make([]string, len(values))
+	for idx, value := range values {
+		quoted[idx] = "'" + value + "'"
+	}
+	return quoted
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
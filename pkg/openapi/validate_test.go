@@ -0,0 +1,69 @@
+package openapi_test
+
+import (
+	"reflect"
+	"simple-order-service/pkg/openapi"
+	"testing"
+)
+
+func TestValidateValue_ReportsEveryInvalidField(t *testing.T) {
+	closed := false
+	one, minLength := 1.0, 1
+	doc := &openapi.Document{Components: openapi.Components{Schemas: map[string]*openapi.Schema{
+		"Line": {
+			Type:     "object",
+			Required: []string{"product_id"},
+			Properties: map[string]*openapi.Schema{
+				"product_id": {Type: "string", MinLength: &minLength},
+				"quantity":   {Type: "integer", Minimum: &one},
+			},
+			AdditionalProperties: &closed,
+		},
+	}}}
+	schema := &openapi.Schema{
+		Type:     "object",
+		Required: []string{"email", "lines"},
+		Properties: map[string]*openapi.Schema{
+			"email":  {Type: "string", Format: "email"},
+			"status": {Type: "string", Enum: []string{"open", "placed"}},
+			"lines":  {Type: "array", Items: openapi.SchemaRef("Line")},
+		},
+	}
+
+	value, err := openapi.DecodeJSON([]byte(`{"status": "lost", "lines": [{"product_id": "", "quantity": 1.5, "note": "x"}, {"quantity": 2}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := doc.ValidateValue(schema, "", value)
+	want := []openapi.FieldError{
+		{Field: "email", Reason: "is required"},
+		{Field: "lines[0].note", Reason: "is not a known field"},
+		{Field: "lines[0].product_id", Reason: "must not be empty"},
+		{Field: "lines[0].quantity", Reason: "must be an integer"},
+		{Field: "lines[1].product_id", Reason: "is required"},
+		{Field: "status", Reason: "must be one of 'open', 'placed'"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got: %v, Want: %v", got, want)
+	}
+}
+
+func TestValidateParameter_ConvertsToTypeOfSchema(t *testing.T) {
+	one, hundred := 1.0, 100.0
+	doc := &openapi.Document{}
+	limit := &openapi.Parameter{Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: &one, Maximum: &hundred}}
+
+	tests := []struct {
+		raw  string
+		want []openapi.FieldError
+	}{
+		{"10", nil},
+		{"ten", []openapi.FieldError{{Field: "limit", Reason: "must be an integer"}}},
+		{"101", []openapi.FieldError{{Field: "limit", Reason: "must be at most 100"}}},
+	}
+	for _, test := range tests {
+		if got := doc.ValidateParameter(limit, test.raw); len(got) != len(test.want) || (len(got) > 0 && got[0] != test.want[0]) {
+			t.Errorf("%s: Got: %v, Want: %v", test.raw, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"container/list"
+	"math"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of a request to a limiter, along with the state of the bucket of its key.
+type Decision struct {
+	Allowed bool
+	// Limit is the number of requests the bucket holds when it is full.
+	Limit int
+	// Remaining is the number of requests left in the bucket.
+	Remaining int
+	// RetryAfter is how long to wait before the next request is allowed, which is zero when it is.
+	RetryAfter time.Duration
+	// Reset is how long it takes the bucket to fill up again.
+	Reset time.Duration
+}
+
+// Limiter keeps a token bucket for every key, such as a client. Buckets hold up to burst requests and
+// refill at rate requests per second. Only the buckets of the maxKeys keys used most recently are
+// kept, so that the memory of the limiter is bounded; the key of an evicted bucket starts over with a
+// full bucket.
+type Limiter struct {
+	rate    float64
+	burst   int
+	maxKeys int
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	recency *list.List
+}
+
+type bucket struct {
+	key     string
+	tokens  float64
+	updated time.Time
+}
+
+func NewLimiter(rate float64, burst, maxKeys int) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		burst:   burst,
+		maxKeys: maxKeys,
+		buckets: make(map[string]*list.Element),
+		recency: list.New(),
+	}
+}
+
+// PrincipalKey is the key of the bucket of an authenticated client, which is the same whichever API
+// the client calls.
+func PrincipalKey(role, subject string) string {
+	return "principal:" + role + ":" + subject
+}
+
+// Allow takes a request from the bucket of the key, if there is one left at the given time.
+func (limiter *Limiter) Allow(key string, at time.Time) Decision {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	b := limiter.bucket(key, at)
+	if elapsed := at.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limiter.burst), b.tokens+elapsed*limiter.rate)
+		b.updated = at
+	}
+
+	decision := Decision{Limit: limiter.burst}
+	if b.tokens >= 1 {
+		b.tokens -= 1
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = limiter.duration(1 - b.tokens)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = limiter.duration(float64(limiter.burst) - b.tokens)
+	return decision
+}
+
+// Len returns the number of buckets kept by the limiter.
+func (limiter *Limiter) Len() int {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	return len(limiter.buckets)
+}
+
+// bucket returns the bucket of the key, creating a full one for a new key and evicting the bucket used
+// least recently when there are too many.
+func (limiter *Limiter) bucket(key string, at time.Time) *bucket {
+	if element, ok := limiter.buckets[key]; ok {
+		limiter.recency.MoveToFront(element)
+		return element.Value.(*bucket)
+	}
+	if limiter.recency.Len() >= limiter.maxKeys {
+		oldest := limiter.recency.Back()
+		limiter.recency.Remove(oldest)
+		delete(limiter.buckets, oldest.Value.(*bucket).key)
+	}
+	b := &bucket{key: key, tokens: float64(limiter.burst), updated: at}
+	limiter.buckets[key] = limiter.recency.PushFront(b)
+	return b
+}
+
+// duration returns how long it takes to refill the given number of tokens.
+func (limiter *Limiter) duration(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens / limiter.rate * float64(time.Second)))
+}
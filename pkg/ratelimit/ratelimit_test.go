@@ -0,0 +1,48 @@
+package ratelimit_test
+
+import (
+	"simple-order-service/pkg/ratelimit"
+	"testing"
+	"time"
+)
+
+func TestLimiter_AllowsBurstThenRefills(t *testing.T) {
+	limiter := ratelimit.NewLimiter(2, 3, 10)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if decision := limiter.Allow("client", now); !decision.Allowed || decision.Remaining != 2-i {
+			t.Fatalf("request %d: Got: %+v, Want: allowed with %d remaining", i, decision, 2-i)
+		}
+	}
+	decision := limiter.Allow("client", now)
+	if decision.Allowed || decision.RetryAfter != 500*time.Millisecond || decision.Reset != 1500*time.Millisecond {
+		t.Errorf("Got: %+v, Want: rejected, retry after 500ms, reset after 1.5s", decision)
+	}
+	if decision := limiter.Allow("another client", now); !decision.Allowed {
+		t.Errorf("Got: %+v, Want: the bucket of another key to be full", decision)
+	}
+	if decision := limiter.Allow("client", now.Add(500*time.Millisecond)); !decision.Allowed || decision.Remaining != 0 {
+		t.Errorf("Got: %+v, Want: allowed once a request is refilled", decision)
+	}
+}
+
+func TestLimiter_KeepsBucketsOfRecentKeysOnly(t *testing.T) {
+	limiter := ratelimit.NewLimiter(1, 1, 2)
+	now := time.Now()
+
+	limiter.Allow("a", now)
+	limiter.Allow("b", now)
+	limiter.Allow("a", now)
+	limiter.Allow("c", now)
+
+	if limiter.Len() != 2 {
+		t.Errorf("Got: %v buckets, Want: 2", limiter.Len())
+	}
+	if decision := limiter.Allow("a", now); decision.Allowed {
+		t.Error("the bucket of a key used recently must be kept")
+	}
+	if decision := limiter.Allow("b", now); !decision.Allowed {
+		t.Error("the bucket of the key used least recently must be evicted")
+	}
+}
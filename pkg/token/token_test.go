@@ -0,0 +1,53 @@
+package token_test
+
+import (
+	"errors"
+	"simple-order-service/pkg/token"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigner_VerifiesSignedToken(t *testing.T) {
+	signer := token.NewSigner([]byte("a secret of at least thirty-two bytes"))
+	now := time.Now()
+	want := token.Claims{Subject: "c-1", Role: "customer", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+
+	signed, err := signer.Sign(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := signer.Verify(signed, now)
+	if err != nil || got != want {
+		t.Errorf("Got: %v, %v, Want: %v, nil", got, err, want)
+	}
+}
+
+func TestSigner_RejectsInvalidTokens(t *testing.T) {
+	signer := token.NewSigner([]byte("a secret of at least thirty-two bytes"))
+	now := time.Now()
+	signed, err := signer.Sign(token.Claims{Subject: "c-1", Role: "customer", ExpiresAt: now.Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, _ := strings.Cut(signed, ".")
+	forged, _ := token.NewSigner([]byte("another secret")).Sign(token.Claims{Subject: "c-1", Role: "admin", ExpiresAt: now.Add(time.Hour).Unix()})
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		at    time.Time
+		want  error
+	}{
+		{"no signature", payload, now, token.ErrMalformedToken},
+		{"signed by another secret", forged, now, token.ErrInvalidSignature},
+		{"claims changed", forgedPayload + "." + signature, now, token.ErrInvalidSignature},
+		{"expired", signed, now.Add(time.Hour), token.ErrExpiredToken},
+	}
+	for _, test := range tests {
+		if _, got := signer.Verify(test.token, test.at); !errors.Is(got, test.want) {
+			t.Errorf("%s: Got: %v, Want: %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ulid
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+var ErrOverflow = errors.New("too many ids generated in the same millisecond")
+
+// encoding is the base32 alphabet of Crockford, which leaves out the letters I, L, O and U.
+const encoding = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Generator generates ULIDs: 128 bit ids made of a millisecond timestamp followed by 80 random bits,
+// written as 26 characters of base32. The ids of a generator sort in the order they were generated
+// in, since the ids generated in the same millisecond increment the random bits of the previous one.
+type Generator struct {
+	mu      sync.Mutex
+	entropy io.Reader
+	lastMs  uint64
+	last    [16]byte
+}
+
+func NewGenerator(entropy io.Reader) *Generator {
+	return &Generator{entropy: entropy}
+}
+
+var defaultGenerator = NewGenerator(rand.Reader)
+
+// New generates an id with the default generator, which reads its random bits from crypto/rand.
+func New() (string, error) {
+	return defaultGenerator.New(time.Now())
+}
+
+// New generates the id of the given time. When the clock goes backwards, the ids keep the time of the
+// last id, so that they still sort in the order they were generated in.
+func (generator *Generator) New(at time.Time) (string, error) {
+	generator.mu.Lock()
+	defer generator.mu.Unlock()
+
+	ms := uint64(at.UnixMilli())
+	if ms <= generator.lastMs && generator.lastMs != 0 {
+		if !increment(generator.last[6:]) {
+			return "", ErrOverflow
+		}
+		return encode(generator.last), nil
+	}
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	if _, err := io.ReadFull(generator.entropy, id[6:]); err != nil {
+		return "", err
+	}
+	generator.lastMs = ms
+	generator.last = id
+	return encode(id), nil
+}
+
+// increment adds one to the big-endian number, and reports false when it overflows.
+func increment(number []byte) bool {
+	for i := len(number) - 1; i >= 0; i-- {
+		number[i]++
+		if number[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encode writes the 128 bits of the id as 26 characters of 5 bits each. The first character only
+// holds the 3 most significant bits.
+func encode(id [16]byte) string {
+	bit := func(k int) byte {
+		if k < 0 {
+			return 0
+		}
+		return (id[k/8] >> (7 - k%8)) & 1
+	}
+	out := make([]byte, 26)
+	for i := range out {
+		var value byte
+		for k := 5*i - 2; k < 5*i+3; k++ {
+			value = value<<1 | bit(k)
+		}
+		out[i] = encoding[value]
+	}
+	return string(out)
+}
@@ -0,0 +1,60 @@
+package ulid_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"simple-order-service/pkg/ulid"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestGenerator_EncodesTimeAndEntropy(t *testing.T) {
+	generator := ulid.NewGenerator(bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+	got, err := generator.New(time.UnixMilli(1469918176385))
+	want := "01ARYZ6S41ZZZZZZZZZZZZZZZZ"
+	if err != nil || got != want {
+		t.Errorf("Got: %v, %v, Want: %v, nil", got, err, want)
+	}
+}
+
+func TestGenerator_IdsSortInOrderOfGeneration(t *testing.T) {
+	generator := ulid.NewGenerator(rand.Reader)
+	start := time.Now()
+	ids := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		// Several ids share a millisecond, and the clock goes backwards once.
+		at := start.Add(time.Duration(i/10) * time.Millisecond)
+		if i == 50 {
+			at = start
+		}
+		id, err := generator.New(at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(id) != 26 {
+			t.Fatalf("Got: %v characters, Want: 26", len(id))
+		}
+		ids = append(ids, id)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("ids must sort in the order they were generated in: %v", ids)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] == ids[i-1] {
+			t.Fatalf("id %v was generated twice", ids[i])
+		}
+	}
+}
+
+func TestGenerator_ReportsOverflow(t *testing.T) {
+	generator := ulid.NewGenerator(bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+	at := time.Now()
+	if _, err := generator.New(at); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generator.New(at); !errors.Is(err, ulid.ErrOverflow) {
+		t.Errorf("Got: %v, Want: %v", err, ulid.ErrOverflow)
+	}
+}